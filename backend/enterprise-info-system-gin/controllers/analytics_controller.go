@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 解析统计参数 from、to（YYYY-MM-DD）和 deptNo
+func analyticsFilter(c *gin.Context, defaultMonths int) (*services.AnalyticsFilter, bool) {
+	filter, err := services.ParseAnalyticsFilter(c.Query("from"), c.Query("to"), c.Query("deptNo"), defaultMonths)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return filter, true
+}
+
+// 按月统计入职和离职人数，默认统计最近 12 个月
+func GetMonthlyHiresLeavers(c *gin.Context) {
+	filter, ok := analyticsFilter(c, 12)
+	if !ok {
+		return
+	}
+
+	result, err := services.GetMonthlyHiresLeavers(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// 统计全公司和各部门的离职率，默认统计最近 12 个月
+func GetTurnoverReport(c *gin.Context) {
+	filter, ok := analyticsFilter(c, 12)
+	if !ok {
+		return
+	}
+
+	report, err := services.GetTurnoverReport(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// 统计平均司龄和司龄分布，默认统计当前在职员工
+func GetTenureStats(c *gin.Context) {
+	filter, ok := analyticsFilter(c, 0)
+	if !ok {
+		return
+	}
+
+	stats, err := services.GetTenureStats(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// 统计年龄分布，默认统计当前在职员工
+func GetAgeStats(c *gin.Context) {
+	filter, ok := analyticsFilter(c, 0)
+	if !ok {
+		return
+	}
+
+	stats, err := services.GetAgeStats(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// 统计性别比例，默认统计当前在职员工
+func GetGenderStats(c *gin.Context) {
+	filter, ok := analyticsFilter(c, 0)
+	if !ok {
+		return
+	}
+
+	stats, err := services.GetGenderStats(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 获取员工附件列表
+func GetEmployeeAttachments(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	attachments, err := services.GetEmployeeAttachments(empNo, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// 上传员工附件，表单字段 file 为文件，category 为附件类别
+func UploadEmployeeAttachment(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传附件文件"})
+		return
+	}
+	if fileHeader.Size > services.MaxAttachmentSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "附件不能超过20MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取附件失败"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, services.MaxAttachmentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取附件失败"})
+		return
+	}
+
+	user := middleware.CurrentUser(c)
+	attachment, err := services.UploadEmployeeAttachment(empNo, c.PostForm("category"), fileHeader.Filename, data, &user.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachment)
+}
+
+// 下载附件，thumbnail=1 时返回缩略图
+func DownloadAttachment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的附件ID"})
+		return
+	}
+
+	attachment, err := services.GetAttachment(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	serveAttachment(c, attachment)
+}
+
+// 获取员工照片，thumbnail=1 时返回缩略图
+func GetEmployeePhoto(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	attachment, err := services.GetEmployeePhoto(empNo)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	serveAttachment(c, attachment)
+}
+
+func serveAttachment(c *gin.Context, attachment *models.EmployeeAttachment) {
+	if !services.CanViewAttachment(middleware.CurrentUser(c), attachment.EmpNo, attachment.Category) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "没有查看该附件的权限"})
+		return
+	}
+
+	thumbnail := c.Query("thumbnail") == "1" || c.Query("thumbnail") == "true"
+	r, contentType, err := services.OpenAttachment(attachment, thumbnail)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer r.Close()
+
+	disposition := "inline"
+	if attachment.Category != models.AttachmentPhoto {
+		disposition = "attachment"
+	}
+	size := attachment.Size
+	if thumbnail {
+		size = -1
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, r, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// 删除附件
+func DeleteAttachment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的附件ID"})
+		return
+	}
+
+	if err := services.DeleteAttachment(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 上班打卡
+func ClockIn(c *gin.Context) {
+	clock(c, services.ClockIn)
+}
+
+// 下班打卡
+func ClockOut(c *gin.Context) {
+	clock(c, services.ClockOut)
+}
+
+func clock(c *gin.Context, punch func(empNo int) (*models.AttendanceRecord, error)) {
+	var req models.ClockRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+			return
+		}
+	}
+
+	empNo, err := clockEmployee(middleware.CurrentUser(c), req.EmpNo)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	record, err := punch(empNo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// 确定打卡的员工：默认为当前账号关联的员工，管理员可以为其他员工补卡
+func clockEmployee(user *models.User, empNo *int) (int, error) {
+	if empNo != nil && (user.EmpNo == nil || *empNo != *user.EmpNo) {
+		if user.Role != "Admin" {
+			return 0, errors.New("只能为自己打卡")
+		}
+		return *empNo, nil
+	}
+	if user.EmpNo == nil {
+		return 0, errors.New("当前账号未关联员工")
+	}
+	return *user.EmpNo, nil
+}
+
+// 获取员工某月的考勤，month 格式为 YYYY-MM，默认为当月
+func GetEmployeeAttendance(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	days, err := services.GetEmployeeAttendance(empNo, c.DefaultQuery("month", time.Now().Format("2006-01")), middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, days)
+}
+
+// 部门月度考勤报表，支持 month 和 deptNo 参数
+func GetAttendanceReport(c *gin.Context) {
+	deptNo, _ := strconv.Atoi(c.Query("deptNo"))
+
+	reports, err := services.GetAttendanceReport(c.DefaultQuery("month", time.Now().Format("2006-01")), deptNo, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// 获取考勤规则
+func GetAttendanceSettings(c *gin.Context) {
+	settings, err := services.GetAttendanceSettings()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// 更新考勤规则
+func UpdateAttendanceSettings(c *gin.Context) {
+	var req models.AttendanceSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	settings, err := services.UpdateAttendanceSettings(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
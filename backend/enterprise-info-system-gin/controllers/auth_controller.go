@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"enterprise-info-system-gin/services"
+	"enterprise-info-system-gin/utils"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,9 +22,16 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	token, err := utils.IssueToken(user.UserID, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "登录成功",
 		"user":    user,
+		"token":   token,
 	})
 }
 
@@ -45,6 +54,60 @@ func Register(c *gin.Context) {
 			"user_id":  user.UserID,
 			"username": user.Username,
 			"role":     user.Role,
+			"emp_no":   user.EmpNo,
 		},
 	})
-} 
\ No newline at end of file
+} 
+// 将账号关联到员工（仅管理员），关联后该账号可以查看和修改员工本人的信息
+func LinkUserEmployee(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var req services.LinkUserEmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	user, err := services.LinkUserEmployee(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  user.UserID,
+		"username": user.Username,
+		"role":     user.Role,
+		"emp_no":   user.EmpNo,
+	})
+}
+
+// 修改账号角色（仅管理员）
+func SetUserRole(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	var req services.SetUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	user, err := services.SetUserRole(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  user.UserID,
+		"username": user.Username,
+		"role":     user.Role,
+		"emp_no":   user.EmpNo,
+	})
+}
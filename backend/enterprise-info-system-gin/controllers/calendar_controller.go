@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"bytes"
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"enterprise-info-system-gin/utils"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 获取日期范围内在职员工的生日和入职周年
+func GetCalendarEvents(c *gin.Context) {
+	events, err := services.GetCalendarEvents(c.Query("from"), c.Query("to"), c.Query("deptNo"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, events)
+}
+
+// 获取部门日历的订阅链接，可添加到 Outlook、Thunderbird 等日历客户端
+func GetDepartmentCalendarFeedURL(c *gin.Context) {
+	deptNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+		return
+	}
+	token, err := services.CalendarFeedToken(deptNo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	respondCalendarFeedURL(c, deptNo, token)
+}
+
+// 重新生成部门日历的订阅链接，之前分发的链接失效（仅管理员）
+func RotateDepartmentCalendarFeedURL(c *gin.Context) {
+	deptNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+		return
+	}
+	token, err := services.RotateCalendarFeedToken(deptNo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	respondCalendarFeedURL(c, deptNo, token)
+}
+
+func respondCalendarFeedURL(c *gin.Context, deptNo int, token string) {
+	feedURL := url.URL{
+		Scheme:   utils.RequestScheme(c.Request),
+		Host:     c.Request.Host,
+		Path:     "/api/calendar/departments/" + strconv.Itoa(deptNo) + "/events.ics",
+		RawQuery: url.Values{"token": {token}}.Encode(),
+	}
+	c.JSON(http.StatusOK, gin.H{"url": feedURL.String()})
+}
+
+// 部门日历订阅（iCalendar 格式），使用订阅链接中的令牌验证，无需登录
+func GetDepartmentCalendarFeed(c *gin.Context) {
+	deptNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+		return
+	}
+	if !services.VerifyCalendarFeedToken(deptNo, c.Query("token")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无效的订阅令牌"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteDepartmentCalendar(&buf, deptNo); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", "inline; filename=\"department-"+strconv.Itoa(deptNo)+".ics\"")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// 获取员工的日历隐私设置
+func GetCalendarPreference(c *gin.Context) {
+	empNo, _, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	pref, err := services.GetCalendarPreference(empNo, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
+
+// 更新员工的日历隐私设置，可隐藏生日或入职周年（管理员或员工本人）
+func UpdateCalendarPreference(c *gin.Context) {
+	empNo, _, ok := profileParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.CalendarPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	pref, err := services.UpdateCalendarPreference(empNo, &req, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pref)
+}
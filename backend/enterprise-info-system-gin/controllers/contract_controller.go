@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 获取员工的合同
+func GetEmployeeContracts(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	contracts, err := services.GetEmployeeContracts(empNo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, contracts)
+}
+
+// 添加合同
+func CreateContract(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	var req models.EmployeeContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	contract, err := services.CreateContract(empNo, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, contract)
+}
+
+// 更新合同
+func UpdateContract(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的合同ID"})
+		return
+	}
+
+	var req models.EmployeeContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	contract, err := services.UpdateContract(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, contract)
+}
+
+// 删除合同
+func DeleteContract(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的合同ID"})
+		return
+	}
+
+	if err := services.DeleteContract(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// 获取即将到期的合同和试用期，days 默认为 30 天
+func GetExpiringContracts(c *gin.Context) {
+	days := services.DefaultContractExpiryDays
+	if d := c.Query("days"); d != "" {
+		var err error
+		days, err = strconv.Atoi(d)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的天数"})
+			return
+		}
+	}
+
+	contracts, err := services.GetExpiringContracts(days)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, contracts)
+}
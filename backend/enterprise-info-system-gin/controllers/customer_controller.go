@@ -3,6 +3,7 @@ package controllers
 import (
 	"enterprise-info-system-gin/models"
 	"enterprise-info-system-gin/services"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -66,4 +67,65 @@ func DeleteCustomer(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
-} 
\ No newline at end of file
+}
+
+// 客户导入文件大小上限
+const maxCustomerImportSize = 10 << 20
+
+// 批量导入客户（支持 CSV、XLSX 和 vCard）
+func ImportCustomers(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传导入文件"})
+		return
+	}
+	if fileHeader.Size > maxCustomerImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "导入文件不能超过10MB"})
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format, err = services.DetectCustomerImportFormat(fileHeader.Filename)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取导入文件失败"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "读取导入文件失败"})
+		return
+	}
+
+	// 大文件可以放到后台任务中导入，通过 /api/jobs/:id 查询进度
+	if isAsyncRequest(c) {
+		userID, ok := jobSubmitter(c)
+		if !ok {
+			return
+		}
+		job, err := services.EnqueueCustomerImportJob(data, format, userID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	result, err := services.ImportCustomers(data, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 获取首页汇总数据
+func GetDashboard(c *gin.Context) {
+	summary, err := services.GetDashboardSummary()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
@@ -30,6 +30,7 @@ func CreateDepartment(c *gin.Context) {
 	department := &models.Department{
 		DeptName:        req.DeptName,
 		DeptPeopleCount: req.DeptPeopleCount,
+		ParentDeptNo:    req.ParentDeptNo,
 	}
 
 	newDepartment, err := services.CreateDepartment(department)
@@ -71,7 +72,8 @@ func DeleteDepartment(c *gin.Context) {
 		return
 	}
 
-	if err := services.DeleteDepartment(id); err != nil {
+	// children=reparent 时将子部门挂到被删除部门的上级，否则存在子部门时拒绝删除
+	if err := services.DeleteDepartment(id, c.Query("children")); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -132,10 +134,134 @@ func DeleteDepartmentEmployees(c *gin.Context) {
 		return
 	}
 
-	if err := services.DeleteDepartment(deptNo); err != nil {
+	if err := services.DeleteDepartment(deptNo, ""); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
-} 
\ No newline at end of file
+}
+
+// 创建部门人数核对任务
+func ReconcileDepartmentHeadcounts(c *gin.Context) {
+	userID, ok := jobSubmitter(c)
+	if !ok {
+		return
+	}
+	job, err := services.EnqueueHeadcountReconcileJob(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// 获取部门人数趋势，参数 from、to（YYYY-MM-DD）和 interval（day、week、month，默认 month）
+func GetDepartmentHeadcountTrend(c *gin.Context) {
+	deptNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+		return
+	}
+
+	trend, err := services.GetDepartmentHeadcountTrend(deptNo, c.Query("from"), c.Query("to"), c.Query("interval"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, trend)
+}
+
+// 创建历史人数快照补录任务，参数 from、to（YYYY-MM-DD）
+func BackfillHeadcountSnapshots(c *gin.Context) {
+	userID, ok := jobSubmitter(c)
+	if !ok {
+		return
+	}
+	job, err := services.EnqueueHeadcountBackfillJob(c.Query("from"), c.Query("to"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// 获取部门树，可通过 root 参数指定根部门
+func GetDepartmentTree(c *gin.Context) {
+	rootDeptNo := 0
+	if root := c.Query("root"); root != "" {
+		id, err := strconv.Atoi(root)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+			return
+		}
+		rootDeptNo = id
+	}
+
+	tree, err := services.GetDepartmentTree(rootDeptNo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tree)
+}
+
+// 移动部门（连同其所有下级部门）到新的上级部门
+func MoveDepartment(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+
+	var req models.DepartmentMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if err := services.MoveDepartment(id, req.ParentDeptNo); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "移动成功"})
+}
+
+// 获取部门经理任命记录
+func GetDepartmentManagers(c *gin.Context) {
+	deptNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+		return
+	}
+
+	managers, err := services.GetDepartmentManagers(deptNo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, managers)
+}
+
+// 任命部门经理
+func AssignDepartmentManager(c *gin.Context) {
+	deptNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+		return
+	}
+
+	var req models.DepartmentManagerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	manager, err := services.AssignDepartmentManager(deptNo, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, manager)
+}
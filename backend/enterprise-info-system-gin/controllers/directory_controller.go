@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"bytes"
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 通讯录文件的 MIME 类型
+const (
+	vcardContentType = "text/vcard; charset=utf-8"
+	ldifContentType  = "text/x-ldif; charset=utf-8"
+)
+
+// 解析通讯录过滤条件：department 为部门ID，employmentStatus 默认为在职（0 表示全部）
+func directoryFilter(c *gin.Context) (*services.DirectoryFilter, bool) {
+	filter, err := services.ParseDirectoryFilter(c.Query("department"), c.Query("employmentStatus"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return filter, true
+}
+
+func sendDirectoryFile(c *gin.Context, contentType, filename string, buf *bytes.Buffer) {
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// 导出通讯录为 vCard 文件，可导入手机和电话系统
+func ExportDirectoryVCards(c *gin.Context) {
+	filter, ok := directoryFilter(c)
+	if !ok {
+		return
+	}
+	var buf bytes.Buffer
+	if err := services.ExportDirectoryVCards(c.Request.Context(), filter, middleware.CurrentUser(c), &buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	sendDirectoryFile(c, vcardContentType, "directory_"+time.Now().Format("20060102150405")+".vcf", &buf)
+}
+
+// 导出通讯录为 LDIF 文件，可导入目录服务器，baseDn 为条目的基准 DN
+func ExportDirectoryLDIF(c *gin.Context) {
+	filter, ok := directoryFilter(c)
+	if !ok {
+		return
+	}
+	var buf bytes.Buffer
+	if err := services.ExportDirectoryLDIF(c.Request.Context(), filter, c.Query("baseDn"), middleware.CurrentUser(c), &buf); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sendDirectoryFile(c, ldifContentType, "directory_"+time.Now().Format("20060102150405")+".ldif", &buf)
+}
+
+// 导出单名员工的 vCard
+func ExportEmployeeVCard(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+	var buf bytes.Buffer
+	if err := services.ExportEmployeeVCard(c.Request.Context(), empNo, middleware.CurrentUser(c), &buf); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	sendDirectoryFile(c, vcardContentType, "employee_"+strconv.Itoa(empNo)+".vcf", &buf)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"enterprise-info-system-gin/middleware"
 	"enterprise-info-system-gin/models"
 	"enterprise-info-system-gin/services"
 	"net/http"
@@ -148,4 +149,107 @@ func GetEmployeeDetail(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, detail)
-} 
\ No newline at end of file
+}
+
+// 设置员工的直属上级
+func SetEmployeeManager(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	var req models.EmployeeManagerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	if err := services.SetEmployeeManager(empNo, req.ManagerEmpNo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "设置成功"})
+}
+
+// 获取员工的下属，transitive=true 时包含间接下属
+func GetEmployeeReports(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	transitive := c.Query("transitive") == "true" || c.Query("transitive") == "1"
+	reports, err := services.GetEmployeeReports(empNo, transitive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// 员工调岗
+func TransferEmployee(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	var req models.EmployeeTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	transfer, err := services.TransferEmployee(empNo, &req)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// 获取员工调岗记录
+func GetEmployeeTransfers(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	transfers, err := services.GetEmployeeTransfers(empNo, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// 员工离职
+func TerminateEmployee(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	var req models.EmployeeTerminationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	result, err := services.TerminateEmployee(empNo, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
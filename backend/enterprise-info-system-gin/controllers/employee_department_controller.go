@@ -28,7 +28,7 @@ func AddEmployeeDepartment(c *gin.Context) {
     }
 
     if err := services.AddEmployeeDepartment(&req); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondError(c, http.StatusInternalServerError, err)
         return
     }
 
@@ -50,7 +50,7 @@ func UpdateEmployeeDepartment(c *gin.Context) {
     }
 
     if err := services.UpdateEmployeeDepartment(edID, &req); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        respondError(c, http.StatusInternalServerError, err)
         return
     }
 
@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 解析路径中的员工ID和记录ID，记录ID不存在时返回 0
+func profileParams(c *gin.Context) (int, int, bool) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return 0, 0, false
+	}
+	itemID := 0
+	if param := c.Param("itemId"); param != "" {
+		itemID, err = strconv.Atoi(param)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的记录ID"})
+			return 0, 0, false
+		}
+	}
+	return empNo, itemID, true
+}
+
+// 没有权限时返回 403，其他错误返回 400
+func respondProfileError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrProfileForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	respondError(c, http.StatusBadRequest, err)
+}
+
+// 是否请求查看完整的证件号码或银行账号
+func wantsReveal(c *gin.Context) bool {
+	return c.Query("reveal") == "1" || c.Query("reveal") == "true"
+}
+
+// 获取员工的紧急联系人
+func GetEmergencyContacts(c *gin.Context) {
+	empNo, _, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	contacts, err := services.GetEmergencyContacts(empNo, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, contacts)
+}
+
+// 添加或更新紧急联系人
+func SaveEmergencyContact(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	var req models.EmergencyContactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+	contact, err := services.SaveEmergencyContact(empNo, itemID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, contact)
+}
+
+// 删除紧急联系人
+func DeleteEmergencyContact(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	if err := services.DeleteEmergencyContact(empNo, itemID, middleware.CurrentUser(c)); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// 获取员工的邮箱
+func GetEmployeeEmails(c *gin.Context) {
+	empNo, _, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	emails, err := services.GetEmployeeEmails(empNo, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, emails)
+}
+
+// 添加或更新邮箱
+func SaveEmployeeEmail(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	var req models.EmployeeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+	email, err := services.SaveEmployeeEmail(empNo, itemID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, email)
+}
+
+// 删除邮箱
+func DeleteEmployeeEmail(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	if err := services.DeleteEmployeeEmail(empNo, itemID, middleware.CurrentUser(c)); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// 获取员工的身份证件，reveal=1 时返回完整号码
+func GetIdentityDocuments(c *gin.Context) {
+	empNo, _, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	documents, err := services.GetIdentityDocuments(empNo, middleware.CurrentUser(c), wantsReveal(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, documents)
+}
+
+// 添加或更新身份证件
+func SaveIdentityDocument(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	var req models.IdentityDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+	document, err := services.SaveIdentityDocument(empNo, itemID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, document)
+}
+
+// 删除身份证件
+func DeleteIdentityDocument(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	if err := services.DeleteIdentityDocument(empNo, itemID, middleware.CurrentUser(c)); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// 获取员工的教育经历
+func GetEducationRecords(c *gin.Context) {
+	empNo, _, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	records, err := services.GetEducationRecords(empNo, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// 添加或更新教育经历
+func SaveEducationRecord(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	var req models.EducationRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+	record, err := services.SaveEducationRecord(empNo, itemID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// 删除教育经历
+func DeleteEducationRecord(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	if err := services.DeleteEducationRecord(empNo, itemID, middleware.CurrentUser(c)); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// 获取员工的银行账户，reveal=1 时返回完整账号
+func GetBankAccounts(c *gin.Context) {
+	empNo, _, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	accounts, err := services.GetBankAccounts(empNo, middleware.CurrentUser(c), wantsReveal(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, accounts)
+}
+
+// 添加或更新银行账户
+func SaveBankAccount(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	var req models.BankAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+	account, err := services.SaveBankAccount(empNo, itemID, &req, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, account)
+}
+
+// 删除银行账户
+func DeleteBankAccount(c *gin.Context) {
+	empNo, itemID, ok := profileParams(c)
+	if !ok {
+		return
+	}
+	if err := services.DeleteBankAccount(empNo, itemID, middleware.CurrentUser(c)); err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
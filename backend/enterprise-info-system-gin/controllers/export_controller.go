@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"encoding/json"
+	"enterprise-info-system-gin/services"
+	"enterprise-info-system-gin/utils"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 导出员工（支持与员工搜索相同的过滤条件），
+// skills 为与搜索请求相同格式的 JSON 数组，如 [{"skillId":1,"minLevel":3}]
+func ExportEmployees(c *gin.Context) {
+	params := map[string]interface{}{
+		"name":             c.Query("name"),
+		"department":       c.Query("department"),
+		"hireDateStart":    c.Query("hireDateStart"),
+		"hireDateEnd":      c.Query("hireDateEnd"),
+		"employmentStatus": c.Query("employmentStatus"),
+		"skillMatch":       c.Query("skillMatch"),
+	}
+	if skills := c.Query("skills"); skills != "" {
+		var criteria []interface{}
+		if err := json.Unmarshal([]byte(skills), &criteria); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的技能条件"})
+			return
+		}
+		params["skills"] = criteria
+	}
+	handleExport(c, services.ExportEntityEmployees, params)
+}
+
+// 导出客户
+func ExportCustomers(c *gin.Context) {
+	handleExport(c, services.ExportEntityCustomers, nil)
+}
+
+// 导出部门
+func ExportDepartments(c *gin.Context) {
+	handleExport(c, services.ExportEntityDepartments, nil)
+}
+
+// 导出员工部门关系
+func ExportEmployeeDepartments(c *gin.Context) {
+	handleExport(c, services.ExportEntityEmployeeDepartments, nil)
+}
+
+// format 参数可选 csv、xlsx、pdf，默认为 csv；
+// async=true 时创建后台任务并立即返回，否则以附件形式流式输出
+func handleExport(c *gin.Context, entity string, params map[string]interface{}) {
+	format := c.DefaultQuery("format", utils.ExportCSV)
+	if !utils.IsValidExportFormat(format) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的导出格式，仅支持 csv、xlsx 和 pdf"})
+		return
+	}
+
+	if isAsyncRequest(c) {
+		userID, ok := jobSubmitter(c)
+		if !ok {
+			return
+		}
+		job, err := services.EnqueueExportJob(entity, format, params, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	c.Header("Content-Type", utils.ExportContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="`+services.ExportFilename(entity, format)+`"`)
+	c.Status(http.StatusOK)
+
+	if err := services.Export(c.Request.Context(), entity, params, format, c.Writer); err != nil {
+		// 尚未输出任何内容时仍可返回错误信息，否则只能记录日志并中断传输
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("导出 %s 失败: %v\n", entity, err)
+		c.Abort()
+	}
+}
+
+// 请求是否要求以后台任务方式执行
+func isAsyncRequest(c *gin.Context) bool {
+	async := c.Query("async")
+	if async == "" {
+		async = c.PostForm("async")
+	}
+	return async == "1" || async == "true"
+}
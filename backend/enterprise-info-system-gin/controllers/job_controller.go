@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/services"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 提交后台任务的用户，只有提交者和管理员可以查看任务和下载结果，
+// 因此后台任务必须登录后提交；未登录时返回 401
+func jobSubmitter(c *gin.Context) (*int, bool) {
+	user := middleware.CurrentUser(c)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "请先登录后再提交后台任务"})
+		return nil, false
+	}
+	return &user.UserID, true
+}
+
+// 获取任务列表
+func GetJobs(c *gin.Context) {
+	jobs, err := services.GetJobs(c.Query("status"), c.Query("type"), middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// 获取任务状态和进度
+func GetJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务ID"})
+		return
+	}
+
+	job, err := services.GetJob(id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// 取消任务
+func CancelJob(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务ID"})
+		return
+	}
+
+	if err := services.CancelJob(id, middleware.CurrentUser(c)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "任务已取消"})
+}
+
+// 下载任务结果文件
+func DownloadJobResult(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务ID"})
+		return
+	}
+
+	path, err := services.GetJobResultFile(id, middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.FileAttachment(path, filepath.Base(path))
+}
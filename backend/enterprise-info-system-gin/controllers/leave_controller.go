@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 提交请假申请
+func ApplyLeave(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	var req models.LeaveApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	leave, err := services.ApplyLeave(empNo, &req, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, leave)
+}
+
+// 获取员工的假期余额和请假记录，year 默认为当前年度
+func GetEmployeeLeave(c *gin.Context) {
+	empNo, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的员工ID"})
+		return
+	}
+
+	year := time.Now().Year()
+	if y := c.Query("year"); y != "" {
+		year, err = strconv.Atoi(y)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的年度"})
+			return
+		}
+	}
+
+	summary, err := services.GetEmployeeLeave(empNo, year, middleware.CurrentUser(c))
+	if err != nil {
+		respondProfileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// 查询请假申请，支持 status 过滤
+func GetLeaveRequests(c *gin.Context) {
+	leaves, err := services.GetLeaveRequests(c.Query("status"), middleware.CurrentUser(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, leaves)
+}
+
+// 批准请假申请
+func ApproveLeave(c *gin.Context) {
+	reviewLeave(c, true)
+}
+
+// 驳回请假申请
+func RejectLeave(c *gin.Context) {
+	reviewLeave(c, false)
+}
+
+func reviewLeave(c *gin.Context, approve bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的申请ID"})
+		return
+	}
+
+	// 审批意见可以为空
+	var req models.LeaveReviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+			return
+		}
+	}
+
+	leave, err := services.ReviewLeave(id, middleware.CurrentUser(c), approve, req.Comment)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, leave)
+}
+
+// 撤销请假申请
+func CancelLeave(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的申请ID"})
+		return
+	}
+
+	if err := services.CancelLeave(id, middleware.CurrentUser(c)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已撤销"})
+}
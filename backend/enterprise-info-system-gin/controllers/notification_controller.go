@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/middleware"
+	"enterprise-info-system-gin/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 获取当前用户的通知，unread=1 时只返回未读通知
+func GetNotifications(c *gin.Context) {
+	unreadOnly := c.Query("unread") == "1" || c.Query("unread") == "true"
+
+	notifications, err := services.GetNotifications(middleware.CurrentUser(c), unreadOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notifications)
+}
+
+// 将通知标记为已读
+func MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的通知ID"})
+		return
+	}
+
+	if err := services.MarkNotificationsRead(middleware.CurrentUser(c), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已读"})
+}
+
+// 将当前用户的所有通知标记为已读
+func MarkAllNotificationsRead(c *gin.Context) {
+	if err := services.MarkNotificationsRead(middleware.CurrentUser(c), 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已读"})
+}
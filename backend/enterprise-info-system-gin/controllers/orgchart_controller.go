@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 获取组织架构图
+// format: json（默认）、dot、svg；root: 根部门编号；depth: 显示层级，0 表示不限；
+// employees=false 时只显示部门和经理
+func GetOrgChart(c *gin.Context) {
+	rootDeptNo, err := strconv.Atoi(c.DefaultQuery("root", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的部门ID"})
+		return
+	}
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if err != nil || depth < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的层级参数"})
+		return
+	}
+	includeEmployees := c.DefaultQuery("employees", "true") != "false"
+
+	format := c.DefaultQuery("format", services.OrgChartJSON)
+	if format != services.OrgChartJSON && format != services.OrgChartDOT && format != services.OrgChartSVG {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的格式，仅支持 json、dot 和 svg"})
+		return
+	}
+
+	charts, err := services.BuildOrgChart(rootDeptNo, depth, includeEmployees)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch format {
+	case services.OrgChartDOT:
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(services.RenderOrgChartDOT(charts)))
+	case services.OrgChartSVG:
+		c.Data(http.StatusOK, "image/svg+xml; charset=utf-8", services.RenderOrgChartSVG(charts))
+	default:
+		c.JSON(http.StatusOK, charts)
+	}
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 获取职位列表，deptNo 参数用于筛选某个部门可用的职位
+func GetPositions(c *gin.Context) {
+	deptNo, _ := strconv.Atoi(c.Query("deptNo"))
+
+	positions, err := services.GetPositions(deptNo)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, positions)
+}
+
+// 创建职位
+func CreatePosition(c *gin.Context) {
+	var req models.PositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	position, err := services.CreatePosition(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, position)
+}
+
+// 更新职位
+func UpdatePosition(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的职位ID"})
+		return
+	}
+
+	var req models.PositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	position, err := services.UpdatePosition(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, position)
+}
+
+// 删除职位
+func DeletePosition(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的职位ID"})
+		return
+	}
+
+	if err := services.DeletePosition(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// 按职位和职级统计在职人数
+func GetPositionHeadcount(c *gin.Context) {
+	report, err := services.GetPositionHeadcount()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 返回错误信息，字段校验错误统一返回 400 并附带各字段的错误详情
+func respondError(c *gin.Context, status int, err error) {
+	var verr *services.ValidationError
+	if errors.As(err, &verr) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": verr.Error(), "fields": verr.Fields})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SCIM 接口的响应类型（RFC 7644 3.1）
+const scimContentType = "application/scim+json; charset=utf-8"
+
+// 返回 SCIM 响应，c.JSON 不会覆盖已设置的 Content-Type
+func scimJSON(c *gin.Context, status int, obj interface{}) {
+	c.Header("Content-Type", scimContentType)
+	c.JSON(status, obj)
+}
+
+// 按 RFC 7644 3.12 返回 SCIM 错误
+func respondSCIMError(c *gin.Context, err error) {
+	status, scimType := http.StatusBadRequest, "invalidValue"
+	var scimErr *utils.SCIMError
+	if errors.As(err, &scimErr) {
+		status, scimType = scimErr.Status, scimErr.ScimType
+	}
+	body := gin.H{
+		"schemas": []string{models.SCIMSchemaError},
+		"status":  strconv.Itoa(status),
+		"detail":  err.Error(),
+	}
+	if scimType != "" {
+		body["scimType"] = scimType
+	}
+	scimJSON(c, status, body)
+}
+
+// SCIM 接口的根地址，用于生成资源的 location
+func scimBaseURL(c *gin.Context) string {
+	return utils.RequestScheme(c.Request) + "://" + c.Request.Host + "/scim/v2"
+}
+
+// 解析请求体，JSON 格式错误时返回 invalidSyntax
+func bindSCIMRequest(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		respondSCIMError(c, utils.NewSCIMError(http.StatusBadRequest, "invalidSyntax", "无效的请求内容"))
+		return false
+	}
+	return true
+}
+
+func scimListParams(c *gin.Context) (*services.SCIMListParams, bool) {
+	params, err := services.ParseSCIMListParams(c.Query("filter"), c.Query("startIndex"), c.Query("count"),
+		c.Query("attributes"), c.Query("excludedAttributes"))
+	if err != nil {
+		respondSCIMError(c, err)
+		return nil, false
+	}
+	return params, true
+}
+
+// 返回单个资源，支持 attributes 和 excludedAttributes 参数
+func respondSCIMResource(c *gin.Context, status int, resource interface{}) {
+	view, err := services.ProjectSCIMResource(resource, c.Query("attributes"), c.Query("excludedAttributes"))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	if meta, ok := view["meta"].(map[string]interface{}); ok && status == http.StatusCreated {
+		if location, ok := meta["location"].(string); ok {
+			c.Header("Location", location)
+		}
+	}
+	scimJSON(c, status, view)
+}
+
+// 解析 PATCH 请求，schemas 必须包含 PatchOp
+func bindSCIMPatch(c *gin.Context) ([]models.SCIMPatchOperation, bool) {
+	var req models.SCIMPatchRequest
+	if !bindSCIMRequest(c, &req) {
+		return nil, false
+	}
+	for _, schema := range req.Schemas {
+		if schema == models.SCIMSchemaPatchOp {
+			return req.Operations, true
+		}
+	}
+	respondSCIMError(c, utils.NewSCIMError(http.StatusBadRequest, "invalidSyntax", "schemas 必须包含 "+models.SCIMSchemaPatchOp))
+	return nil, false
+}
+
+// 获取用户列表，支持 filter、startIndex 和 count 参数
+func ListSCIMUsers(c *gin.Context) {
+	params, ok := scimListParams(c)
+	if !ok {
+		return
+	}
+	list, err := services.ListSCIMUsers(params, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	scimJSON(c, http.StatusOK, list)
+}
+
+// 获取单个用户
+func GetSCIMUser(c *gin.Context) {
+	user, err := services.GetSCIMUser(c.Param("id"), scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusOK, user)
+}
+
+// 创建用户（新员工入职）
+func CreateSCIMUser(c *gin.Context) {
+	var req models.SCIMUser
+	if !bindSCIMRequest(c, &req) {
+		return
+	}
+	user, err := services.CreateSCIMUser(&req, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusCreated, user)
+}
+
+// 替换用户
+func ReplaceSCIMUser(c *gin.Context) {
+	var req models.SCIMUser
+	if !bindSCIMRequest(c, &req) {
+		return
+	}
+	user, err := services.ReplaceSCIMUser(c.Param("id"), &req, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusOK, user)
+}
+
+// 修改用户的部分属性
+func PatchSCIMUser(c *gin.Context) {
+	ops, ok := bindSCIMPatch(c)
+	if !ok {
+		return
+	}
+	user, err := services.PatchSCIMUser(c.Param("id"), ops, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusOK, user)
+}
+
+// 删除用户
+func DeleteSCIMUser(c *gin.Context) {
+	if err := services.DeleteSCIMUser(c.Param("id")); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 获取组（部门）列表，支持 filter、startIndex 和 count 参数
+func ListSCIMGroups(c *gin.Context) {
+	params, ok := scimListParams(c)
+	if !ok {
+		return
+	}
+	list, err := services.ListSCIMGroups(params, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	scimJSON(c, http.StatusOK, list)
+}
+
+// 获取单个组
+func GetSCIMGroup(c *gin.Context) {
+	group, err := services.GetSCIMGroup(c.Param("id"), scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusOK, group)
+}
+
+// 创建组（新建部门）
+func CreateSCIMGroup(c *gin.Context) {
+	var req models.SCIMGroup
+	if !bindSCIMRequest(c, &req) {
+		return
+	}
+	group, err := services.CreateSCIMGroup(&req, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusCreated, group)
+}
+
+// 替换组
+func ReplaceSCIMGroup(c *gin.Context) {
+	var req models.SCIMGroup
+	if !bindSCIMRequest(c, &req) {
+		return
+	}
+	group, err := services.ReplaceSCIMGroup(c.Param("id"), &req, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusOK, group)
+}
+
+// 修改组的部分属性，常用于添加或移除成员
+func PatchSCIMGroup(c *gin.Context) {
+	ops, ok := bindSCIMPatch(c)
+	if !ok {
+		return
+	}
+	group, err := services.PatchSCIMGroup(c.Param("id"), ops, scimBaseURL(c))
+	if err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	respondSCIMResource(c, http.StatusOK, group)
+}
+
+// 删除组
+func DeleteSCIMGroup(c *gin.Context) {
+	if err := services.DeleteSCIMGroup(c.Param("id")); err != nil {
+		respondSCIMError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 获取 SCIM 服务能力说明
+func GetSCIMServiceProviderConfig(c *gin.Context) {
+	scimJSON(c, http.StatusOK, services.SCIMServiceProviderConfig(scimBaseURL(c)))
+}
+
+// 获取 SCIM 支持的资源类型
+func GetSCIMResourceTypes(c *gin.Context) {
+	resources := services.SCIMResourceTypes(scimBaseURL(c))
+	scimJSON(c, http.StatusOK, models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// 获取 SCIM 访问令牌列表
+func GetSCIMTokens(c *gin.Context) {
+	tokens, err := services.GetSCIMTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// 创建 SCIM 访问令牌，供身份提供方（如 Azure AD、Okta）调用 SCIM 接口
+func CreateSCIMToken(c *gin.Context) {
+	var req models.SCIMTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	token, err := services.CreateSCIMToken(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+// 删除（吊销）SCIM 访问令牌
+func DeleteSCIMToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的令牌ID"})
+		return
+	}
+
+	if err := services.DeleteSCIMToken(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"enterprise-info-system-gin/routes"
+	"enterprise-info-system-gin/services"
 	"enterprise-info-system-gin/utils"
 	"log"
 
@@ -12,9 +13,23 @@ func main() {
 	// 初始化数据库连接
 	utils.InitDB()
 
+	// 读取访问令牌的签名密钥
+	utils.InitTokenSecret()
+
+	// 还没有管理员时按 ADMIN_USERNAME 设置初始管理员
+	services.BootstrapAdmin()
+
+	// 启动后台任务工作池
+	services.StartJobWorkers(4)
+
 	// 创建 Gin 引擎
 	r := gin.Default()
 
+	// 只信任 TRUSTED_PROXIES 中的反向代理转发的客户端地址和协议
+	if err := r.SetTrustedProxies(utils.InitTrustedProxies()); err != nil {
+		log.Fatal("设置受信任代理失败:", err)
+	}
+
 	// 允许跨域
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/services"
+	"enterprise-info-system-gin/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 上下文中保存当前用户的键
+const currentUserKey = "currentUser"
+
+// 根据请求头中的访问令牌获取当前用户，失败时返回相应的状态码和错误信息
+func authenticate(c *gin.Context) (*models.User, int, string) {
+	header := c.GetHeader("Authorization")
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return nil, http.StatusUnauthorized, "请先登录"
+	}
+
+	claims, err := utils.ParseToken(token)
+	if err != nil {
+		return nil, http.StatusUnauthorized, err.Error()
+	}
+
+	// 以数据库中的用户为准，账号停用或角色变更后立即生效
+	user, err := services.GetUserByID(claims.UserID)
+	if err != nil || user.Disabled {
+		return nil, http.StatusUnauthorized, "账号不存在或已停用"
+	}
+	return user, http.StatusOK, ""
+}
+
+// 校验请求头中的访问令牌，并将当前用户保存到上下文中。
+// roles 不为空时只允许这些角色访问
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, status, message := authenticate(c)
+		if user == nil {
+			c.AbortWithStatusJSON(status, gin.H{"error": message})
+			return
+		}
+
+		if len(roles) > 0 {
+			allowed := false
+			for _, role := range roles {
+				if user.Role == role {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "没有访问权限"})
+				return
+			}
+		}
+
+		c.Set(currentUserKey, user)
+		c.Next()
+	}
+}
+
+// 不要求登录，但请求带有效的访问令牌时保存当前用户，
+// 用于公开接口中需要区分用户的功能（如后台任务）
+func OptionalLogin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user, _, _ := authenticate(c); user != nil {
+			c.Set(currentUserKey, user)
+		}
+		c.Next()
+	}
+}
+
+// 只要求登录，不限制角色
+func RequireLogin() gin.HandlerFunc {
+	return RequireRole()
+}
+
+// 获取当前登录的用户，未经过认证中间件时返回 nil
+func CurrentUser(c *gin.Context) *models.User {
+	if user, ok := c.Get(currentUserKey); ok {
+		return user.(*models.User)
+	}
+	return nil
+}
+
+// 校验 SCIM 接口的访问令牌（管理员创建的 Bearer Token），失败时按 RFC 7644 返回 SCIM 错误
+func RequireSCIMToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		detail := "缺少访问令牌"
+		if ok && token != "" {
+			err := services.AuthenticateSCIMToken(token)
+			if err == nil {
+				c.Next()
+				return
+			}
+			detail = err.Error()
+		}
+		c.Header("WWW-Authenticate", "Bearer")
+		c.Header("Content-Type", "application/scim+json; charset=utf-8")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"schemas": []string{models.SCIMSchemaError},
+			"status":  strconv.Itoa(http.StatusUnauthorized),
+			"detail":  detail,
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+// 每月入职和离职人数
+type MonthlyHiresLeavers struct {
+	Month   string `json:"month"` // 格式 YYYY-MM
+	Hires   int    `json:"hires"`
+	Leavers int    `json:"leavers"`
+}
+
+// 统计期间的人员流动情况，DeptNo 为 0 表示全公司
+type TurnoverStats struct {
+	DeptNo         int     `json:"deptNo"`
+	DeptName       string  `json:"deptName"`
+	StartHeadcount int     `json:"startHeadcount"`
+	EndHeadcount   int     `json:"endHeadcount"`
+	Hires          int     `json:"hires"`
+	Leavers        int     `json:"leavers"`
+	TurnoverRate   float64 `json:"turnoverRate"` // 离职人数 / 平均人数 * 100
+}
+
+// 人员流动报表
+type TurnoverReport struct {
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Company     TurnoverStats   `json:"company"`
+	Departments []TurnoverStats `json:"departments"`
+}
+
+// 分段人数
+type AnalyticsBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// 司龄统计，司龄按年计算
+type TenureStats struct {
+	Employees    int               `json:"employees"`
+	AverageYears float64           `json:"averageYears"`
+	Distribution []AnalyticsBucket `json:"distribution"`
+}
+
+// 年龄统计，未填写生日的员工计入 Unknown
+type AgeStats struct {
+	Employees    int               `json:"employees"`
+	AverageAge   float64           `json:"averageAge"`
+	Distribution []AnalyticsBucket `json:"distribution"`
+	Unknown      int               `json:"unknown"`
+}
+
+// 性别比例
+type GenderStats struct {
+	Employees   int     `json:"employees"`
+	Male        int     `json:"male"`
+	Female      int     `json:"female"`
+	MaleRatio   float64 `json:"maleRatio"`   // 百分比
+	FemaleRatio float64 `json:"femaleRatio"` // 百分比
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// 附件类别
+const (
+	AttachmentContract = "contract" // 合同
+	AttachmentIDScan   = "id"       // 身份证件扫描件
+	AttachmentPhoto    = "photo"    // 员工照片，每名员工只保留一张
+	AttachmentOther    = "other"
+)
+
+// 员工附件，文件内容保存在对象存储中
+type EmployeeAttachment struct {
+	AttachmentID int       `gorm:"column:AttachmentID;primaryKey;autoIncrement" json:"attachmentId"`
+	EmpNo        int       `gorm:"column:EmpNo;not null;index:idx_attachment_emp" json:"empNo"`
+	Category     string    `gorm:"column:Category;size:20;not null" json:"category"`
+	FileName     string    `gorm:"column:FileName;size:255;not null" json:"fileName"`
+	ContentType  string    `gorm:"column:ContentType;size:100;not null" json:"contentType"`
+	Size         int64     `gorm:"column:Size;not null" json:"size"`
+	StorageKey   string    `gorm:"column:StorageKey;size:255;not null" json:"-"`
+	ThumbnailKey string    `gorm:"column:ThumbnailKey;size:255" json:"-"`
+	UploadedBy   *int      `gorm:"column:UploadedBy" json:"uploadedBy"`
+	CreatedAt    time.Time `gorm:"column:CreatedAt" json:"createdAt"`
+	HasThumbnail bool      `gorm:"-" json:"hasThumbnail"`
+}
+
+// 指定表名
+func (EmployeeAttachment) TableName() string {
+	return "Employee_Attachments"
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// 每日考勤记录，每名员工每天一条
+type AttendanceRecord struct {
+	RecordID int        `gorm:"column:RecordID;primaryKey;autoIncrement" json:"recordId"`
+	EmpNo    int        `gorm:"column:EmpNo;not null;uniqueIndex:idx_attendance_emp_date" json:"empNo"`
+	WorkDate time.Time  `gorm:"column:WorkDate;type:date;not null;uniqueIndex:idx_attendance_emp_date" json:"workDate"`
+	ClockIn  *time.Time `gorm:"column:ClockIn" json:"clockIn"`
+	ClockOut *time.Time `gorm:"column:ClockOut" json:"clockOut"`
+}
+
+// 考勤规则，只有一条记录
+type AttendanceSettings struct {
+	SettingsID       int    `gorm:"column:SettingsID;primaryKey" json:"-"`
+	WorkStart        string `gorm:"column:WorkStart;size:5;not null" json:"workStart"` // 上班时间，格式 HH:MM
+	WorkEnd          string `gorm:"column:WorkEnd;size:5;not null" json:"workEnd"`     // 下班时间，格式 HH:MM
+	LateGraceMinutes int    `gorm:"column:LateGraceMinutes;not null;default:0" json:"lateGraceMinutes"`
+}
+
+// 用于接收打卡请求，EmpNo 为空时为当前登录用户关联的员工
+type ClockRequest struct {
+	EmpNo *int `json:"empNo"`
+}
+
+// 按考勤规则计算后的每日考勤
+type AttendanceDay struct {
+	AttendanceRecord
+	WorkedHours  float64 `json:"workedHours"`
+	Late         bool    `json:"late"`
+	LeftEarly    bool    `json:"leftEarly"`
+	MissingPunch bool    `json:"missingPunch"`
+}
+
+// 员工的月度考勤汇总
+type EmployeeAttendanceSummary struct {
+	EmpNo             int     `json:"empNo"`
+	EmployeeName      string  `json:"employeeName"`
+	Workdays          int     `json:"workdays"`
+	AttendedDays      int     `json:"attendedDays"`
+	LeaveDays         int     `json:"leaveDays"`
+	AbsentDays        int     `json:"absentDays"`
+	LateCount         int     `json:"lateCount"`
+	LeftEarlyCount    int     `json:"leftEarlyCount"`
+	MissingPunchCount int     `json:"missingPunchCount"`
+	WorkedHours       float64 `json:"workedHours"`
+}
+
+// 部门的月度考勤报表
+type DepartmentAttendanceReport struct {
+	DeptNo            int                         `json:"deptNo"`
+	DeptName          string                      `json:"deptName"`
+	Month             string                      `json:"month"`
+	LateCount         int                         `json:"lateCount"`
+	AbsentDays        int                         `json:"absentDays"`
+	MissingPunchCount int                         `json:"missingPunchCount"`
+	WorkedHours       float64                     `json:"workedHours"`
+	Employees         []EmployeeAttendanceSummary `json:"employees"`
+}
+
+// 指定表名
+func (AttendanceRecord) TableName() string {
+	return "Attendance_Records"
+}
+
+// 指定表名
+func (AttendanceSettings) TableName() string {
+	return "Attendance_Settings"
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// 部门日历订阅令牌的版本，重新生成订阅链接时递增，旧链接随即失效；
+// 没有记录时版本为 0
+type CalendarFeedKey struct {
+	DeptNo    int       `gorm:"column:DeptNo;primaryKey;autoIncrement:false" json:"deptNo"`
+	Version   int       `gorm:"column:Version;not null" json:"version"`
+	UpdatedAt time.Time `gorm:"column:UpdatedAt" json:"updatedAt"`
+}
+
+// 日历事件类型
+const (
+	CalendarEventBirthday    = "birthday"
+	CalendarEventAnniversary = "anniversary"
+)
+
+// 员工的日历隐私设置，没有记录时生日和入职周年都会显示
+type CalendarPreference struct {
+	EmpNo           int       `gorm:"column:EmpNo;primaryKey;autoIncrement:false" json:"empNo"`
+	HideBirthday    bool      `gorm:"column:HideBirthday;not null;default:false" json:"hideBirthday"`
+	HideAnniversary bool      `gorm:"column:HideAnniversary;not null;default:false" json:"hideAnniversary"`
+	UpdatedAt       time.Time `gorm:"column:UpdatedAt" json:"updatedAt"`
+}
+
+// 用于接收更新日历隐私设置的请求
+type CalendarPreferenceRequest struct {
+	HideBirthday    bool `json:"hideBirthday"`
+	HideAnniversary bool `json:"hideAnniversary"`
+}
+
+// 日历中的生日或入职周年，Years 为入职周年数
+type CalendarEvent struct {
+	Type         string `json:"type"`
+	Date         string `json:"date"`
+	EmpNo        int    `json:"empNo"`
+	EmployeeName string `json:"employeeName"`
+	Years        int    `json:"years,omitempty"`
+}
+
+// 指定表名
+func (CalendarPreference) TableName() string {
+	return "Calendar_Preferences"
+}
+
+// 指定表名
+func (CalendarFeedKey) TableName() string {
+	return "Calendar_Feed_Keys"
+}
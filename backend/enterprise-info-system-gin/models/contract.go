@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// 劳动合同类型
+const (
+	ContractFixedTerm  = "fixed-term" // 固定期限
+	ContractOpenEnded  = "open-ended" // 无固定期限
+	ContractInternship = "internship" // 实习
+	ContractPartTime   = "part-time"  // 非全日制
+)
+
+// 劳动合同，EndDate 为空表示无固定期限；AttachmentID 指向已签署的合同附件
+type EmployeeContract struct {
+	ContractID       int        `gorm:"column:ContractID;primaryKey;autoIncrement" json:"contractId"`
+	EmpNo            int        `gorm:"column:EmpNo;not null;index:idx_contract_emp" json:"empNo"`
+	ContractType     string     `gorm:"column:ContractType;size:20;not null" json:"contractType"`
+	StartDate        time.Time  `gorm:"column:StartDate;not null" json:"startDate"`
+	EndDate          *time.Time `gorm:"column:EndDate;index:idx_contract_end" json:"endDate"`
+	ProbationEndDate *time.Time `gorm:"column:ProbationEndDate" json:"probationEndDate"`
+	AttachmentID     *int       `gorm:"column:AttachmentID" json:"attachmentId"`
+	CreatedAt        time.Time  `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 用于接收添加/更新合同的请求，StartDate 为空时使用员工入职日期
+type EmployeeContractRequest struct {
+	ContractType     string `json:"contractType"`
+	StartDate        string `json:"startDate"`
+	EndDate          string `json:"endDate,omitempty"`
+	ProbationEndDate string `json:"probationEndDate,omitempty"`
+	AttachmentID     *int   `json:"attachmentId"`
+}
+
+// 即将到期的合同或试用期
+type ExpiringContract struct {
+	EmployeeContract
+	EmployeeName string    `json:"employeeName"`
+	Kind         string    `json:"kind"` // contract 合同到期，probation 试用期结束
+	ExpiryDate   time.Time `json:"expiryDate"`
+	DaysLeft     int       `json:"daysLeft"`
+}
+
+// 指定表名
+func (EmployeeContract) TableName() string {
+	return "Employee_Contracts"
+}
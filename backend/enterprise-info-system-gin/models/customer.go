@@ -13,4 +13,22 @@ type Customer struct {
 // 指定表名
 func (Customer) TableName() string {
 	return "Customers"
-}
\ No newline at end of file
+}
+
+// 客户批量导入结果
+type CustomerImportResult struct {
+	Total   int                       `json:"total"`
+	Created int                       `json:"created"`
+	Skipped int                       `json:"skipped"`
+	Failed  int                       `json:"failed"`
+	Rows    []CustomerImportRowResult `json:"rows"`
+}
+
+// 单行导入结果
+type CustomerImportRowResult struct {
+	Row          int    `json:"row"`
+	Status       string `json:"status"` // created / skipped / failed
+	CustomerName string `json:"customerName"`
+	CustomerID   int    `json:"customerID,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
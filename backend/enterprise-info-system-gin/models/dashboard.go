@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// 首页汇总数据
+type DashboardSummary struct {
+	Totals            DashboardTotals     `json:"totals"`
+	RecentHires       []DashboardEmployee `json:"recentHires"`
+	UpcomingBirthdays []DashboardEvent    `json:"upcomingBirthdays"`
+	WorkAnniversaries []DashboardEvent    `json:"workAnniversaries"`
+	TopDepartments    []DepartmentStats   `json:"topDepartments"`
+	GeneratedAt       time.Time           `json:"generatedAt"`
+}
+
+// 各类数据的总数，员工只统计在职员工
+type DashboardTotals struct {
+	Customers       int64 `json:"customers"`
+	Employees       int64 `json:"employees"`
+	Departments     int64 `json:"departments"`
+	ActiveRelations int64 `json:"activeRelations"`
+}
+
+// 最近入职的员工
+type DashboardEmployee struct {
+	EmpNo        int       `json:"empNo"`
+	EmployeeName string    `json:"employeeName"`
+	HireDate     time.Time `json:"hireDate"`
+}
+
+// 即将到来的生日或入职周年，Years 为周年数（生日不返回年龄）
+type DashboardEvent struct {
+	EmpNo        int    `json:"empNo"`
+	EmployeeName string `json:"employeeName"`
+	Date         string `json:"date"`
+	DaysUntil    int    `json:"daysUntil"`
+	Years        int    `json:"years,omitempty"`
+}
@@ -4,6 +4,7 @@ type Department struct {
 	DeptNo          int    `gorm:"column:DeptNo;primaryKey;autoIncrement" json:"deptNo"`
 	DeptName        string `gorm:"column:DeptName;size:30;not null;index:idx_department_name" json:"deptName"`
 	DeptPeopleCount int    `gorm:"column:DeptPeopleCount;default:0" json:"deptPeopleCount"`
+	ParentDeptNo    *int   `gorm:"column:ParentDeptNo;index:idx_department_parent" json:"parentDeptNo"`
 }
 
 // 用于接收请求的结构体
@@ -11,6 +12,22 @@ type DepartmentRequest struct {
 	DeptNo          int    `json:"deptNo"`
 	DeptName        string `json:"deptName"`
 	DeptPeopleCount int    `json:"deptPeopleCount"`
+	ParentDeptNo    *int   `json:"parentDeptNo"`
+}
+
+// 移动部门的请求，ParentDeptNo 为空表示移动到顶层
+type DepartmentMoveRequest struct {
+	ParentDeptNo *int `json:"parentDeptNo"`
+}
+
+// 部门树节点
+type DepartmentNode struct {
+	DeptNo           int               `json:"deptNo"`
+	DeptName         string            `json:"deptName"`
+	ParentDeptNo     *int              `json:"parentDeptNo"`
+	DeptPeopleCount  int               `json:"deptPeopleCount"`
+	TotalPeopleCount int               `json:"totalPeopleCount"` // 包含所有下级部门的在职人数（同一员工只计一次）
+	Children         []*DepartmentNode `json:"children"`
 }
 
 // 部门统计信息
@@ -20,7 +37,15 @@ type DepartmentStats struct {
 	EmployeeCount int    `json:"employeeCount"`
 }
 
+// 部门人数核对时修正的记录
+type HeadcountCorrection struct {
+	DeptNo   int    `json:"deptNo"`
+	DeptName string `json:"deptName"`
+	Before   int    `json:"before"`
+	After    int    `json:"after"`
+}
+
 // 指定表名
 func (Department) TableName() string {
 	return "Departments"
-}
\ No newline at end of file
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// 部门经理任命记录，EndDate 为空表示仍在任
+type DepartmentManager struct {
+	DmID      int        `gorm:"column:DmID;primaryKey;autoIncrement" json:"dmId"`
+	DeptNo    int        `gorm:"column:DeptNo;not null;index:idx_dept_manager" json:"deptNo"`
+	EmpNo     int        `gorm:"column:EmpNo;not null;index:idx_manager_emp" json:"empNo"`
+	StartDate time.Time  `gorm:"column:StartDate;not null" json:"startDate"`
+	EndDate   *time.Time `gorm:"column:EndDate" json:"endDate"`
+
+	// 关联关系
+	Employee Employee `gorm:"foreignKey:EmpNo;references:EmpNo" json:"employee,omitempty"`
+}
+
+// 用于接收任命部门经理的请求
+type DepartmentManagerRequest struct {
+	EmpNo     int    `json:"empNo"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate,omitempty"`
+}
+
+// 用于设置员工直属上级的请求，ManagerEmpNo 为空表示取消直属上级
+type EmployeeManagerRequest struct {
+	ManagerEmpNo *int `json:"managerEmpNo"`
+}
+
+// 下属信息，Level 为 1 表示直接下属
+type EmployeeReport struct {
+	EmpNo        int    `json:"empNo"`
+	EmployeeName string `json:"employeeName"`
+	ManagerEmpNo int    `json:"managerEmpNo"`
+	Level        int    `json:"level"`
+}
+
+// 指定表名
+func (DepartmentManager) TableName() string {
+	return "Department_Managers"
+}
+
+// 在指定日期是否在任
+func (m DepartmentManager) ActiveOn(day time.Time) bool {
+	if m.StartDate.After(day) {
+		return false
+	}
+	return m.EndDate == nil || !m.EndDate.Before(day)
+}
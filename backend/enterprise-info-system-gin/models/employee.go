@@ -5,14 +5,38 @@ import (
 )
 
 type Employee struct {
-    EmpNo     int       `gorm:"column:EmpNo;primaryKey;autoIncrement" json:"empNo"`
-    FirstName string    `gorm:"column:FirstName;size:30;not null" json:"firstName"`
-    LastName  string    `gorm:"column:LastName;size:30;not null" json:"lastName"`
-    Gender    int       `gorm:"column:Gender;check:Gender IN (0,1)" json:"gender"`
-    HireDate  time.Time `gorm:"column:HireDate;not null" json:"hireDate" time_format:"2006-01-02"`
-    Birthday  time.Time `gorm:"column:Birthday" json:"birthday" time_format:"2006-01-02"`
-    Address   string    `gorm:"column:Address;size:200" json:"address"`
-    Telephone string    `gorm:"column:Telephone;size:20" json:"telephone"`
+    EmpNo             int        `gorm:"column:EmpNo;primaryKey;autoIncrement" json:"empNo"`
+    FirstName         string     `gorm:"column:FirstName;size:30;not null" json:"firstName"`
+    LastName          string     `gorm:"column:LastName;size:30;not null" json:"lastName"`
+    Gender            int        `gorm:"column:Gender;check:Gender IN (0,1)" json:"gender"`
+    HireDate          time.Time  `gorm:"column:HireDate;not null" json:"hireDate" time_format:"2006-01-02"`
+    Birthday          time.Time  `gorm:"column:Birthday" json:"birthday" time_format:"2006-01-02"`
+    Address           string     `gorm:"column:Address;size:200" json:"address"`
+    Telephone         string     `gorm:"column:Telephone;size:20" json:"telephone"`
+    ManagerEmpNo      *int       `gorm:"column:ManagerEmpNo;index:idx_employee_manager" json:"managerEmpNo"`
+    EmploymentStatus  int        `gorm:"column:EmploymentStatus;not null;default:1;index:idx_employee_status" json:"employmentStatus"`
+    TerminationDate   *time.Time `gorm:"column:TerminationDate" json:"terminationDate"`
+    TerminationReason string     `gorm:"column:TerminationReason;size:200" json:"terminationReason"`
+}
+
+// 员工在职状态：离职员工的记录保留用于统计
+const (
+    EmploymentActive     = 1
+    EmploymentTerminated = 2
+)
+
+// 用于接收员工离职请求
+type EmployeeTerminationRequest struct {
+    TerminationDate string `json:"terminationDate"`
+    Reason          string `json:"reason"`
+}
+
+// 员工离职处理结果
+type EmployeeTerminationResult struct {
+    Employee            Employee `json:"employee"`
+    ClosedRelations     int      `json:"closedRelations"`
+    ReassignedReports   int      `json:"reassignedReports"`
+    RevokedUserAccounts int      `json:"revokedUserAccounts"`
 }
 
 // 自定义日期格式的 JSON 解析
@@ -34,10 +58,12 @@ func (Employee) TableName() string {
 
 // 员工搜索参数
 type EmployeeSearchParams struct {
-    Name          string `json:"name"`
-    Department    string `json:"department"`
-    HireDateStart string `json:"hireDateStart"`
-    HireDateEnd   string `json:"hireDateEnd"`
+    Name          string           `json:"name"`
+    Department    string           `json:"department"`
+    HireDateStart string           `json:"hireDateStart"`
+    HireDateEnd   string           `json:"hireDateEnd"`
+    Skills        []SkillCriterion `json:"skills"`     // 技能条件
+    SkillMatch    string           `json:"skillMatch"` // all 满足全部技能条件（默认），any 满足任一条件
 }
 
 // 员工详细信息（包括部门信息）
@@ -10,6 +10,7 @@ type EmployeeDepartment struct {
     EdEntryDate time.Time  `gorm:"column:EdEntryDate;not null" json:"edEntryDate"`
     EdLeaveDate *time.Time `gorm:"column:EdLeaveDate" json:"edLeaveDate"`
     EdStatus    int        `gorm:"column:EdStatus;check:EdStatus IN (1,2)" json:"edStatus"`
+    PositionID  *int       `gorm:"column:PositionID;index:idx_ed_position" json:"positionId"`
     
     // 关联关系
     Employee   Employee   `gorm:"foreignKey:EmpNo;references:EmpNo" json:"employee,omitempty"`
@@ -23,6 +24,7 @@ type EmployeeDepartmentRequest struct {
     EdEntryDate string `json:"edEntryDate"`
     EdLeaveDate string `json:"edLeaveDate,omitempty"`
     EdStatus    int    `json:"edStatus"`
+    PositionID  *int   `json:"positionId"` // 更新时不传表示保留原职位，传 0 表示清除
 }
 
 // 用于返回分组后的员工部门关系
@@ -41,6 +43,8 @@ type DepartmentRelation struct {
     EdEntryDate    time.Time  `json:"edEntryDate"`
     EdLeaveDate    *time.Time `json:"edLeaveDate"`
     EdStatus       int        `json:"edStatus"`
+    PositionID     *int       `json:"positionId"`
+    PositionTitle  string     `json:"positionTitle"`
 }
 
 // 指定表名
@@ -0,0 +1,133 @@
+package models
+
+import "time"
+
+// 邮箱类型
+const (
+	EmailWork     = "work"
+	EmailPersonal = "personal"
+)
+
+// 证件类型
+const (
+	DocumentIDCard   = "id-card"  // 居民身份证
+	DocumentPassport = "passport" // 护照
+	DocumentOther    = "other"
+)
+
+// 紧急联系人
+type EmergencyContact struct {
+	ContactID    int    `gorm:"column:ContactID;primaryKey;autoIncrement" json:"contactId"`
+	EmpNo        int    `gorm:"column:EmpNo;not null;index:idx_emergency_contact_emp" json:"empNo"`
+	Name         string `gorm:"column:Name;size:50;not null" json:"name"`
+	Relationship string `gorm:"column:Relationship;size:20;not null" json:"relationship"`
+	Telephone    string `gorm:"column:Telephone;size:20;not null" json:"telephone"`
+	Address      string `gorm:"column:Address;size:100" json:"address"`
+}
+
+// 用于接收添加/更新紧急联系人的请求
+type EmergencyContactRequest struct {
+	Name         string `json:"name"`
+	Relationship string `json:"relationship"`
+	Telephone    string `json:"telephone"`
+	Address      string `json:"address"`
+}
+
+// 员工邮箱，每名员工最多一个主邮箱
+type EmployeeEmail struct {
+	EmailID   int    `gorm:"column:EmailID;primaryKey;autoIncrement" json:"emailId"`
+	EmpNo     int    `gorm:"column:EmpNo;not null;uniqueIndex:idx_employee_email" json:"empNo"`
+	Email     string `gorm:"column:Email;size:100;not null;uniqueIndex:idx_employee_email" json:"email"`
+	EmailType string `gorm:"column:EmailType;size:20;not null" json:"emailType"`
+	IsPrimary bool   `gorm:"column:IsPrimary;not null;default:false" json:"isPrimary"`
+}
+
+// 用于接收添加/更新邮箱的请求
+type EmployeeEmailRequest struct {
+	Email     string `json:"email"`
+	EmailType string `json:"emailType"`
+	IsPrimary bool   `json:"isPrimary"`
+}
+
+// 身份证件，证件号码加密保存，默认只返回脱敏后的号码
+type IdentityDocument struct {
+	DocumentID      int        `gorm:"column:DocumentID;primaryKey;autoIncrement" json:"documentId"`
+	EmpNo           int        `gorm:"column:EmpNo;not null;index:idx_identity_document_emp" json:"empNo"`
+	DocumentType    string     `gorm:"column:DocumentType;size:20;not null" json:"documentType"`
+	NumberEncrypted string     `gorm:"column:NumberEncrypted;size:255;not null" json:"-"`
+	MaskedNumber    string     `gorm:"column:MaskedNumber;size:50;not null" json:"maskedNumber"`
+	ExpiryDate      *time.Time `gorm:"column:ExpiryDate;type:date" json:"expiryDate"`
+	Number          string     `gorm:"-" json:"number,omitempty"` // 仅在查看完整号码时返回
+}
+
+// 用于接收添加/更新证件的请求，更新时 Number 为空表示不修改号码
+type IdentityDocumentRequest struct {
+	DocumentType string `json:"documentType"`
+	Number       string `json:"number"`
+	ExpiryDate   string `json:"expiryDate,omitempty"`
+}
+
+// 教育经历
+type EducationRecord struct {
+	EducationID int        `gorm:"column:EducationID;primaryKey;autoIncrement" json:"educationId"`
+	EmpNo       int        `gorm:"column:EmpNo;not null;index:idx_education_emp" json:"empNo"`
+	School      string     `gorm:"column:School;size:100;not null" json:"school"`
+	Degree      string     `gorm:"column:Degree;size:50" json:"degree"`
+	Major       string     `gorm:"column:Major;size:100" json:"major"`
+	StartDate   *time.Time `gorm:"column:StartDate;type:date" json:"startDate"`
+	EndDate     *time.Time `gorm:"column:EndDate;type:date" json:"endDate"`
+}
+
+// 用于接收添加/更新教育经历的请求
+type EducationRecordRequest struct {
+	School    string `json:"school"`
+	Degree    string `json:"degree"`
+	Major     string `json:"major"`
+	StartDate string `json:"startDate,omitempty"`
+	EndDate   string `json:"endDate,omitempty"`
+}
+
+// 银行账户，账号加密保存，默认只返回脱敏后的账号
+type BankAccount struct {
+	AccountID       int    `gorm:"column:AccountID;primaryKey;autoIncrement" json:"accountId"`
+	EmpNo           int    `gorm:"column:EmpNo;not null;index:idx_bank_account_emp" json:"empNo"`
+	BankName        string `gorm:"column:BankName;size:100;not null" json:"bankName"`
+	AccountName     string `gorm:"column:AccountName;size:50;not null" json:"accountName"`
+	NumberEncrypted string `gorm:"column:NumberEncrypted;size:255;not null" json:"-"`
+	MaskedNumber    string `gorm:"column:MaskedNumber;size:50;not null" json:"maskedNumber"`
+	IsPrimary       bool   `gorm:"column:IsPrimary;not null;default:false" json:"isPrimary"`
+	AccountNumber   string `gorm:"-" json:"accountNumber,omitempty"` // 仅在查看完整账号时返回
+}
+
+// 用于接收添加/更新银行账户的请求，更新时 AccountNumber 为空表示不修改账号
+type BankAccountRequest struct {
+	BankName      string `json:"bankName"`
+	AccountName   string `json:"accountName"`
+	AccountNumber string `json:"accountNumber"`
+	IsPrimary     bool   `json:"isPrimary"`
+}
+
+// 指定表名
+func (EmergencyContact) TableName() string {
+	return "Emergency_Contacts"
+}
+
+// 指定表名
+func (EmployeeEmail) TableName() string {
+	return "Employee_Emails"
+}
+
+// 指定表名
+func (IdentityDocument) TableName() string {
+	return "Identity_Documents"
+}
+
+// 指定表名
+func (EducationRecord) TableName() string {
+	return "Education_Records"
+}
+
+// 指定表名
+func (BankAccount) TableName() string {
+	return "Bank_Accounts"
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// 员工调岗记录
+type EmployeeTransfer struct {
+	TransferID    int       `gorm:"column:TransferID;primaryKey;autoIncrement" json:"transferId"`
+	EmpNo         int       `gorm:"column:EmpNo;not null;index:idx_transfer_emp" json:"empNo"`
+	FromDeptNo    int       `gorm:"column:FromDeptNo;not null" json:"fromDeptNo"`
+	ToDeptNo      int       `gorm:"column:ToDeptNo;not null" json:"toDeptNo"`
+	FromEdID      int       `gorm:"column:FromEdID;not null" json:"fromEdId"`
+	ToEdID        int       `gorm:"column:ToEdID;not null" json:"toEdId"`
+	EffectiveDate time.Time `gorm:"column:EffectiveDate;not null" json:"effectiveDate"`
+	Reason        string    `gorm:"column:Reason;size:200" json:"reason"`
+	CreatedAt     time.Time `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 用于接收调岗请求，只有一个在职部门时 FromDeptNo 可省略
+type EmployeeTransferRequest struct {
+	FromDeptNo    int    `json:"fromDeptNo"`
+	ToDeptNo      int    `json:"toDeptNo"`
+	ToPositionID  *int   `json:"toPositionId"`
+	EffectiveDate string `json:"effectiveDate"`
+	Reason        string `json:"reason"`
+}
+
+// 调岗记录详情（包含部门名称）
+type EmployeeTransferDetail struct {
+	EmployeeTransfer
+	FromDeptName string `json:"fromDeptName"`
+	ToDeptName   string `json:"toDeptName"`
+}
+
+// 指定表名
+func (EmployeeTransfer) TableName() string {
+	return "Employee_Transfers"
+}
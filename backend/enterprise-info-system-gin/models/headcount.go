@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// 部门人数的每日快照，记录当天结束时的在职人数
+type HeadcountSnapshot struct {
+	SnapshotID   int       `gorm:"column:SnapshotID;primaryKey;autoIncrement" json:"snapshotId"`
+	DeptNo       int       `gorm:"column:DeptNo;not null;uniqueIndex:idx_headcount_dept_date" json:"deptNo"`
+	SnapshotDate time.Time `gorm:"column:SnapshotDate;type:date;not null;uniqueIndex:idx_headcount_dept_date" json:"snapshotDate"`
+	Headcount    int       `gorm:"column:Headcount;not null" json:"headcount"`
+	CreatedAt    time.Time `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 人数趋势中的一个时间点，Source 为 snapshot 表示取自快照，computed 表示根据部门关系推算
+type HeadcountPoint struct {
+	Date      string `json:"date"`
+	Headcount int    `json:"headcount"`
+	Source    string `json:"source"`
+}
+
+// 部门人数趋势
+type HeadcountTrend struct {
+	DeptNo   int              `json:"deptNo"`
+	DeptName string           `json:"deptName"`
+	Interval string           `json:"interval"`
+	Points   []HeadcountPoint `json:"points"`
+}
+
+// 指定表名
+func (HeadcountSnapshot) TableName() string {
+	return "Headcount_Snapshots"
+}
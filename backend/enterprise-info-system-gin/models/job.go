@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 任务状态
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+)
+
+// 后台任务，用于耗时较长的导入、导出和数据核对
+type Job struct {
+	JobID      int        `gorm:"column:JobID;primaryKey;autoIncrement" json:"jobId"`
+	JobType    string     `gorm:"column:JobType;size:50;not null;index:idx_job_type" json:"jobType"`
+	Status     string     `gorm:"column:Status;size:20;not null;default:pending;index:idx_job_status" json:"status"`
+	Progress   int        `gorm:"column:Progress;default:0" json:"progress"`
+	Payload    string     `gorm:"column:Payload;type:text" json:"-"`
+	Result     string     `gorm:"column:Result;type:text" json:"-"`
+	ResultFile string     `gorm:"column:ResultFile;size:255" json:"-"`
+	Error      string     `gorm:"column:Error;type:text" json:"error,omitempty"`
+	Attempts   int        `gorm:"column:Attempts;default:0" json:"attempts"`
+	UserID     *int       `gorm:"column:UserID;index:idx_job_user" json:"userId"` // 提交任务的用户，定时任务为空
+	CreatedAt  time.Time  `gorm:"column:CreatedAt" json:"createdAt"`
+	StartedAt  *time.Time `gorm:"column:StartedAt" json:"startedAt"`
+	FinishedAt *time.Time `gorm:"column:FinishedAt" json:"finishedAt"`
+
+	// 以下字段仅用于返回给前端
+	ResultData    json.RawMessage `gorm:"-" json:"result,omitempty"`
+	HasResultFile bool            `gorm:"-" json:"hasResultFile"`
+}
+
+// 指定表名
+func (Job) TableName() string {
+	return "Jobs"
+}
+
+// 任务是否已结束
+func (j Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCanceled
+}
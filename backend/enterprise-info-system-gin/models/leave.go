@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// 假期类型
+const (
+	LeaveTypeAnnual   = "annual"   // 年假
+	LeaveTypeSick     = "sick"     // 病假
+	LeaveTypePersonal = "personal" // 事假
+)
+
+// 请假申请状态
+const (
+	LeaveStatusPending  = "pending"
+	LeaveStatusApproved = "approved"
+	LeaveStatusRejected = "rejected"
+	LeaveStatusCanceled = "canceled"
+)
+
+// 请假申请，Days 为起止日期之间的工作日天数
+type LeaveRequest struct {
+	LeaveID        int        `gorm:"column:LeaveID;primaryKey;autoIncrement" json:"leaveId"`
+	EmpNo          int        `gorm:"column:EmpNo;not null;index:idx_leave_emp" json:"empNo"`
+	LeaveType      string     `gorm:"column:LeaveType;size:20;not null" json:"leaveType"`
+	StartDate      time.Time  `gorm:"column:StartDate;not null" json:"startDate"`
+	EndDate        time.Time  `gorm:"column:EndDate;not null" json:"endDate"`
+	Days           int        `gorm:"column:Days;not null" json:"days"`
+	Reason         string     `gorm:"column:Reason;size:200" json:"reason"`
+	Status         string     `gorm:"column:Status;size:20;not null;default:pending;index:idx_leave_status" json:"status"`
+	ReviewerUserID *int       `gorm:"column:ReviewerUserID" json:"reviewerUserId"`
+	ReviewComment  string     `gorm:"column:ReviewComment;size:200" json:"reviewComment"`
+	ReviewedAt     *time.Time `gorm:"column:ReviewedAt" json:"reviewedAt"`
+	CreatedAt      time.Time  `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 用于接收请假申请
+type LeaveApplyRequest struct {
+	LeaveType string `json:"leaveType"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	Reason    string `json:"reason"`
+}
+
+// 用于接收审批意见
+type LeaveReviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// 某类假期在一个年度内的额度
+type LeaveBalance struct {
+	LeaveType string `json:"leaveType"`
+	Entitled  int    `json:"entitled"`
+	Used      int    `json:"used"`
+	Pending   int    `json:"pending"`
+	Remaining int    `json:"remaining"`
+}
+
+// 员工的假期额度和请假记录
+type EmployeeLeaveSummary struct {
+	EmpNo    int            `json:"empNo"`
+	Year     int            `json:"year"`
+	Balances []LeaveBalance `json:"balances"`
+	History  []LeaveRequest `json:"history"`
+}
+
+// 请假申请详情（包含员工姓名）
+type LeaveRequestDetail struct {
+	LeaveRequest
+	EmployeeName string `json:"employeeName"`
+}
+
+// 指定表名
+func (LeaveRequest) TableName() string {
+	return "Leave_Requests"
+}
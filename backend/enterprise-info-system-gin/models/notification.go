@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// 系统通知，UserID 不为空时发给指定用户，否则发给 Role 对应角色的所有用户；
+// DedupKey 用于避免定时任务重复发送同一条通知。已读状态按用户记录在 NotificationRead 中，
+// ReadAt 只在查询时填充为当前用户的阅读时间
+type Notification struct {
+	NotificationID int        `gorm:"column:NotificationID;primaryKey;autoIncrement" json:"notificationId"`
+	UserID         *int       `gorm:"column:UserID;index:idx_notification_user" json:"userId"`
+	Role           string     `gorm:"column:Role;size:20;index:idx_notification_role" json:"role"`
+	EmpNo          *int       `gorm:"column:EmpNo;index:idx_notification_emp" json:"empNo"` // 通知涉及的员工
+	Category       string     `gorm:"column:Category;size:50;not null" json:"category"`
+	Title          string     `gorm:"column:Title;size:100;not null" json:"title"`
+	Content        string     `gorm:"column:Content;size:500" json:"content"`
+	DedupKey       *string    `gorm:"column:DedupKey;size:150;uniqueIndex:idx_notification_dedup" json:"-"`
+	ReadAt         *time.Time `gorm:"column:ReadAt;->;-:migration" json:"readAt"`
+	CreatedAt      time.Time  `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 指定表名
+func (Notification) TableName() string {
+	return "Notifications"
+}
+
+// 用户阅读通知的记录，发给角色的通知由每个用户各自标记已读
+type NotificationRead struct {
+	NotificationID int       `gorm:"column:NotificationID;primaryKey;autoIncrement:false" json:"notificationId"`
+	UserID         int       `gorm:"column:UserID;primaryKey;autoIncrement:false" json:"userId"`
+	ReadAt         time.Time `gorm:"column:ReadAt;not null" json:"readAt"`
+}
+
+// 指定表名
+func (NotificationRead) TableName() string {
+	return "NotificationReads"
+}
@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// 入职任务的负责人类型
+const (
+	OnboardingAssigneeFixed   = "fixed"   // 指定员工
+	OnboardingAssigneeManager = "manager" // 部门经理，没有部门时为直属上级
+	OnboardingAssigneeSelf    = "self"    // 新员工本人
+)
+
+// 入职任务状态
+const (
+	OnboardingTaskOpen      = "open"
+	OnboardingTaskCompleted = "completed"
+	OnboardingTaskCanceled  = "canceled"
+)
+
+// 入职任务模板，DeptNo 为空表示适用于所有新员工，
+// 否则在员工加入该部门时生成任务
+type OnboardingTemplate struct {
+	TemplateID    int    `gorm:"column:TemplateID;primaryKey;autoIncrement" json:"templateId"`
+	Title         string `gorm:"column:Title;size:100;not null" json:"title"`
+	Description   string `gorm:"column:Description;size:500" json:"description"`
+	DeptNo        *int   `gorm:"column:DeptNo;index:idx_onboarding_template_dept" json:"deptNo"`
+	AssigneeType  string `gorm:"column:AssigneeType;size:20;not null;default:manager" json:"assigneeType"`
+	AssigneeEmpNo *int   `gorm:"column:AssigneeEmpNo" json:"assigneeEmpNo"`
+	DueOffsetDays int    `gorm:"column:DueOffsetDays;not null;default:0" json:"dueOffsetDays"`
+	SortOrder     int    `gorm:"column:SortOrder;not null;default:0" json:"sortOrder"`
+	Active        bool   `gorm:"column:Active;not null" json:"active"` // 不设数据库默认值，否则创建时传入的 false 会被默认值替换
+}
+
+// 用于接收入职任务模板的请求，Active 为空时默认启用
+type OnboardingTemplateRequest struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	DeptNo        *int   `json:"deptNo"`
+	AssigneeType  string `json:"assigneeType"`
+	AssigneeEmpNo *int   `json:"assigneeEmpNo"`
+	DueOffsetDays int    `json:"dueOffsetDays"`
+	SortOrder     int    `json:"sortOrder"`
+	Active        *bool  `json:"active"`
+}
+
+// 员工的入职任务，由模板生成，到期日按入职日期加上模板的偏移天数计算
+type OnboardingTask struct {
+	TaskID        int        `gorm:"column:TaskID;primaryKey;autoIncrement" json:"taskId"`
+	EmpNo         int        `gorm:"column:EmpNo;not null;index:idx_onboarding_task_emp" json:"empNo"`
+	TemplateID    *int       `gorm:"column:TemplateID;index:idx_onboarding_task_template" json:"templateId"`
+	DeptNo        *int       `gorm:"column:DeptNo" json:"deptNo"`
+	Title         string     `gorm:"column:Title;size:100;not null" json:"title"`
+	Description   string     `gorm:"column:Description;size:500" json:"description"`
+	AssigneeEmpNo *int       `gorm:"column:AssigneeEmpNo;index:idx_onboarding_task_assignee" json:"assigneeEmpNo"`
+	DueDate       time.Time  `gorm:"column:DueDate;not null" json:"dueDate"`
+	Status        string     `gorm:"column:Status;size:20;not null;default:open;index:idx_onboarding_task_status" json:"status"`
+	CompletedAt   *time.Time `gorm:"column:CompletedAt" json:"completedAt"`
+	CreatedAt     time.Time  `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 用于更新入职任务的请求，字段为空表示不修改
+type OnboardingTaskUpdateRequest struct {
+	Status        string `json:"status"`
+	AssigneeEmpNo *int   `json:"assigneeEmpNo"`
+	DueDate       string `json:"dueDate"`
+}
+
+// 入职任务详情（包含员工和负责人姓名）
+type OnboardingTaskDetail struct {
+	OnboardingTask
+	EmployeeName string `json:"employeeName"`
+	AssigneeName string `json:"assigneeName"`
+	DeptName     string `json:"deptName"`
+	Overdue      bool   `json:"overdue"`
+}
+
+// 指定表名
+func (OnboardingTemplate) TableName() string {
+	return "Onboarding_Templates"
+}
+
+// 指定表名
+func (OnboardingTask) TableName() string {
+	return "Onboarding_Tasks"
+}
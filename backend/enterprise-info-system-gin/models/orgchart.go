@@ -0,0 +1,17 @@
+package models
+
+// 组织架构图中的部门节点
+type OrgChartNode struct {
+	DeptNo        int                `json:"deptNo"`
+	DeptName      string             `json:"deptName"`
+	Manager       *OrgChartEmployee  `json:"manager"`
+	Employees     []OrgChartEmployee `json:"employees"`
+	EmployeeCount int                `json:"employeeCount"`
+	Children      []*OrgChartNode    `json:"children"`
+}
+
+// 组织架构图中的员工
+type OrgChartEmployee struct {
+	EmpNo        int    `json:"empNo"`
+	EmployeeName string `json:"employeeName"`
+}
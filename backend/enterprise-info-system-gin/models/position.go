@@ -0,0 +1,46 @@
+package models
+
+// 职位，DeptNo 为空表示全公司通用的职位，否则只能用于该部门的员工
+type Position struct {
+	PositionID  int    `gorm:"column:PositionID;primaryKey;autoIncrement" json:"positionId"`
+	Title       string `gorm:"column:Title;size:50;not null" json:"title"`
+	Level       int    `gorm:"column:Level;not null;default:1;index:idx_position_level" json:"level"`
+	DeptNo      *int   `gorm:"column:DeptNo;index:idx_position_dept" json:"deptNo"`
+	Description string `gorm:"column:Description;size:200" json:"description"`
+}
+
+// 用于接收创建/更新职位的请求
+type PositionRequest struct {
+	Title       string `json:"title"`
+	Level       int    `json:"level"`
+	DeptNo      *int   `json:"deptNo"`
+	Description string `json:"description"`
+}
+
+// 按职位统计的在职人数
+type PositionHeadcount struct {
+	PositionID int    `json:"positionId"`
+	Title      string `json:"title"`
+	Level      int    `json:"level"`
+	DeptNo     *int   `json:"deptNo"`
+	DeptName   string `json:"deptName"`
+	Headcount  int    `json:"headcount"`
+}
+
+// 按职级统计的在职人数
+type LevelHeadcount struct {
+	Level     int `json:"level"`
+	Headcount int `json:"headcount"`
+}
+
+// 职位人数报表，未设置职位的在职关系单独统计
+type PositionHeadcountReport struct {
+	ByPosition   []PositionHeadcount `json:"byPosition"`
+	ByLevel      []LevelHeadcount    `json:"byLevel"`
+	Unpositioned int                 `json:"unpositioned"`
+}
+
+// 指定表名
+func (Position) TableName() string {
+	return "Positions"
+}
@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// 考核周期状态
+const (
+	ReviewCycleOpen   = "open"
+	ReviewCycleClosed = "closed"
+)
+
+// 绩效评估状态：草稿 -> 已提交 -> 员工已确认
+const (
+	ReviewDraft        = "draft"
+	ReviewSubmitted    = "submitted"
+	ReviewAcknowledged = "acknowledged"
+)
+
+// 评分范围
+const (
+	ReviewRatingMin = 1
+	ReviewRatingMax = 5
+)
+
+// 绩效考核周期，创建时根据在职的员工部门关系生成参与者
+type ReviewCycle struct {
+	CycleID     int       `gorm:"column:CycleID;primaryKey;autoIncrement" json:"cycleId"`
+	Name        string    `gorm:"column:Name;size:50;not null" json:"name"`
+	PeriodStart time.Time `gorm:"column:PeriodStart;type:date;not null" json:"periodStart"`
+	PeriodEnd   time.Time `gorm:"column:PeriodEnd;type:date;not null" json:"periodEnd"`
+	DeptNo      *int      `gorm:"column:DeptNo" json:"deptNo"` // 为空表示全公司
+	Status      string    `gorm:"column:Status;size:20;not null;default:open" json:"status"`
+	CreatedAt   time.Time `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 用于接收创建考核周期的请求，DeptNo 不为空时只包含该部门的员工
+type ReviewCycleRequest struct {
+	Name        string `json:"name"`
+	PeriodStart string `json:"periodStart"`
+	PeriodEnd   string `json:"periodEnd"`
+	DeptNo      *int   `json:"deptNo"`
+}
+
+// 员工在某个考核周期、某个部门的绩效评估，评估人默认为部门经理
+type PerformanceReview struct {
+	ReviewID        int        `gorm:"column:ReviewID;primaryKey;autoIncrement" json:"reviewId"`
+	CycleID         int        `gorm:"column:CycleID;not null;uniqueIndex:idx_review_cycle_emp_dept" json:"cycleId"`
+	EmpNo           int        `gorm:"column:EmpNo;not null;uniqueIndex:idx_review_cycle_emp_dept;index:idx_review_emp" json:"empNo"`
+	DeptNo          int        `gorm:"column:DeptNo;not null;uniqueIndex:idx_review_cycle_emp_dept" json:"deptNo"`
+	ReviewerEmpNo   *int       `gorm:"column:ReviewerEmpNo;index:idx_review_reviewer" json:"reviewerEmpNo"`
+	Rating          *int       `gorm:"column:Rating" json:"rating"`
+	Comment         string     `gorm:"column:Comment;type:text" json:"comment"`
+	EmployeeComment string     `gorm:"column:EmployeeComment;size:500" json:"employeeComment"`
+	Status          string     `gorm:"column:Status;size:20;not null;default:draft" json:"status"`
+	SubmittedAt     *time.Time `gorm:"column:SubmittedAt" json:"submittedAt"`
+	AcknowledgedAt  *time.Time `gorm:"column:AcknowledgedAt" json:"acknowledgedAt"`
+}
+
+// 用于接收填写评估的请求，ReviewerEmpNo 仅管理员可以修改
+type PerformanceReviewRequest struct {
+	Rating        *int   `json:"rating"`
+	Comment       string `json:"comment"`
+	ReviewerEmpNo *int   `json:"reviewerEmpNo"`
+}
+
+// 绩效评估（含员工、部门和评估人姓名）
+type PerformanceReviewDetail struct {
+	PerformanceReview
+	EmployeeName string `json:"employeeName"`
+	DeptName     string `json:"deptName"`
+	ReviewerName string `json:"reviewerName"`
+}
+
+// 部门的评分汇总，只统计已提交和已确认的评估
+type DepartmentReviewSummary struct {
+	DeptNo        int         `json:"deptNo"`
+	DeptName      string      `json:"deptName"`
+	Participants  int         `json:"participants"`
+	Completed     int         `json:"completed"`
+	AverageRating float64     `json:"averageRating"`
+	Distribution  map[int]int `json:"distribution"` // 各评分的人数
+}
+
+// 指定表名
+func (ReviewCycle) TableName() string {
+	return "Review_Cycles"
+}
+
+// 指定表名
+func (PerformanceReview) TableName() string {
+	return "Performance_Reviews"
+}
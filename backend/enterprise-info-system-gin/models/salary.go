@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// 薪资记录，EndDate 为空表示当前仍然有效；同一员工的薪资时间段互不重叠
+type Salary struct {
+	SalaryID      int        `gorm:"column:SalaryID;primaryKey;autoIncrement" json:"salaryId"`
+	EmpNo         int        `gorm:"column:EmpNo;not null;index:idx_salary_emp" json:"empNo"`
+	BaseSalary    float64    `gorm:"column:BaseSalary;type:decimal(12,2);not null" json:"baseSalary"`
+	Currency      string     `gorm:"column:Currency;size:3;not null;default:CNY" json:"currency"`
+	EffectiveDate time.Time  `gorm:"column:EffectiveDate;not null" json:"effectiveDate"`
+	EndDate       *time.Time `gorm:"column:EndDate" json:"endDate"`
+	Reason        string     `gorm:"column:Reason;size:200" json:"reason"`
+	CreatedAt     time.Time  `gorm:"column:CreatedAt" json:"createdAt"`
+}
+
+// 用于接收添加/更新薪资记录的请求，Currency 为空时默认为 CNY
+type SalaryRequest struct {
+	BaseSalary    float64 `json:"baseSalary"`
+	Currency      string  `json:"currency"`
+	EffectiveDate string  `json:"effectiveDate"`
+	EndDate       string  `json:"endDate,omitempty"`
+	Reason        string  `json:"reason"`
+}
+
+// 部门当前薪资成本，按币种分别统计
+type DepartmentPayroll struct {
+	DeptNo    int     `json:"deptNo"`
+	DeptName  string  `json:"deptName"`
+	Currency  string  `json:"currency"`
+	Headcount int     `json:"headcount"`
+	TotalCost float64 `json:"totalCost"`
+}
+
+// 指定表名
+func (Salary) TableName() string {
+	return "Salaries"
+}
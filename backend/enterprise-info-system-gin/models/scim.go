@@ -0,0 +1,152 @@
+package models
+
+import "time"
+
+// SCIM 2.0 使用的 schema 标识
+const (
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaEnterpriseUser        = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	SCIMSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMSchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// SCIM 资源类型
+const (
+	SCIMResourceUser  = "User"
+	SCIMResourceGroup = "Group"
+)
+
+// 身份提供方调用 SCIM 接口使用的访问令牌，只保存令牌的哈希值
+type SCIMToken struct {
+	TokenID    int        `gorm:"column:TokenID;primaryKey;autoIncrement" json:"tokenId"`
+	Name       string     `gorm:"column:Name;size:100;not null" json:"name"`
+	TokenHash  string     `gorm:"column:TokenHash;size:64;not null;uniqueIndex" json:"-"`
+	CreatedAt  time.Time  `gorm:"column:CreatedAt" json:"createdAt"`
+	LastUsedAt *time.Time `gorm:"column:LastUsedAt" json:"lastUsedAt"`
+}
+
+// 用于接收创建 SCIM 令牌的请求
+type SCIMTokenRequest struct {
+	Name string `json:"name"`
+}
+
+// 新建的 SCIM 令牌，明文令牌只在创建时返回一次
+type SCIMTokenCreated struct {
+	SCIMToken
+	Token string `json:"token"`
+}
+
+// 身份提供方为资源指定的 externalId
+type SCIMExternalID struct {
+	ResourceType string `gorm:"column:ResourceType;size:20;primaryKey" json:"resourceType"`
+	ResourceID   int    `gorm:"column:ResourceID;primaryKey;autoIncrement:false" json:"resourceId"`
+	ExternalID   string `gorm:"column:ExternalID;size:255;not null" json:"externalId"`
+}
+
+// SCIM 用户，对应员工及其关联的用户账号
+type SCIMUser struct {
+	Schemas      []string            `json:"schemas"`
+	ID           string              `json:"id,omitempty"`
+	ExternalID   string              `json:"externalId,omitempty"`
+	UserName     string              `json:"userName"`
+	Name         *SCIMName           `json:"name,omitempty"`
+	DisplayName  string              `json:"displayName,omitempty"`
+	Active       *bool               `json:"active,omitempty"`
+	Password     string              `json:"password,omitempty"` // 只写，不会返回
+	Emails       []SCIMMultiValue    `json:"emails,omitempty"`
+	PhoneNumbers []SCIMMultiValue    `json:"phoneNumbers,omitempty"`
+	Addresses    []SCIMAddress       `json:"addresses,omitempty"`
+	Groups       []SCIMMultiValue    `json:"groups,omitempty"` // 只读，通过 Group 的成员修改
+	Enterprise   *SCIMEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Meta         *SCIMMeta           `json:"meta,omitempty"`
+}
+
+// SCIM 用户姓名
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+// SCIM 多值属性的一项，例如邮箱、电话和组成员
+type SCIMMultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIM 地址
+type SCIMAddress struct {
+	Type          string `json:"type,omitempty"`
+	Formatted     string `json:"formatted,omitempty"`
+	StreetAddress string `json:"streetAddress,omitempty"`
+	Primary       bool   `json:"primary,omitempty"`
+}
+
+// SCIM 企业用户扩展，员工编号和部门只读
+type SCIMEnterpriseUser struct {
+	EmployeeNumber string       `json:"employeeNumber,omitempty"`
+	Department     string       `json:"department,omitempty"`
+	Manager        *SCIMManager `json:"manager,omitempty"`
+}
+
+// SCIM 用户的直属上级
+type SCIMManager struct {
+	Value       string `json:"value,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// SCIM 组，对应部门，成员为部门的在职员工
+type SCIMGroup struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	DisplayName string           `json:"displayName"`
+	Members     []SCIMMultiValue `json:"members,omitempty"`
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+// SCIM 资源的元数据
+type SCIMMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+// SCIM 列表查询结果
+type SCIMListResponse struct {
+	Schemas      []string                 `json:"schemas"`
+	TotalResults int                      `json:"totalResults"`
+	StartIndex   int                      `json:"startIndex"`
+	ItemsPerPage int                      `json:"itemsPerPage"`
+	Resources    []map[string]interface{} `json:"Resources"`
+}
+
+// SCIM PATCH 请求
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIM PATCH 中的一个操作，Op 为 add、replace 或 remove
+type SCIMPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// 指定表名
+func (SCIMToken) TableName() string {
+	return "SCIM_Tokens"
+}
+
+// 指定表名
+func (SCIMExternalID) TableName() string {
+	return "SCIM_External_IDs"
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// 技能熟练度范围：1 了解，2 熟悉，3 熟练，4 精通，5 专家
+const (
+	SkillLevelMin = 1
+	SkillLevelMax = 5
+)
+
+// 技能目录
+type Skill struct {
+	SkillID     int    `gorm:"column:SkillID;primaryKey;autoIncrement" json:"skillId"`
+	SkillName   string `gorm:"column:SkillName;size:50;not null;uniqueIndex:idx_skill_name" json:"skillName"`
+	Category    string `gorm:"column:Category;size:50" json:"category"`
+	Description string `gorm:"column:Description;size:200" json:"description"`
+}
+
+// 用于接收创建/更新技能的请求
+type SkillRequest struct {
+	SkillName   string `json:"skillName"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+}
+
+// 员工掌握的技能，每名员工每项技能一条
+type EmployeeSkill struct {
+	EmpNo     int       `gorm:"column:EmpNo;primaryKey;autoIncrement:false" json:"empNo"`
+	SkillID   int       `gorm:"column:SkillID;primaryKey;autoIncrement:false;index:idx_employee_skill_level" json:"skillId"`
+	Level     int       `gorm:"column:Level;not null;index:idx_employee_skill_level" json:"level"`
+	UpdatedAt time.Time `gorm:"column:UpdatedAt" json:"updatedAt"`
+}
+
+// 用于接收设置员工技能的请求
+type EmployeeSkillRequest struct {
+	SkillID int `json:"skillId"`
+	Level   int `json:"level"`
+}
+
+// 员工技能（含技能名称）
+type EmployeeSkillDetail struct {
+	EmployeeSkill
+	SkillName string `json:"skillName"`
+	Category  string `json:"category"`
+}
+
+// 按技能搜索员工的条件，MinLevel 为空时不限熟练度
+type SkillCriterion struct {
+	SkillID  int `json:"skillId"`
+	MinLevel int `json:"minLevel"`
+}
+
+// 指定表名
+func (Skill) TableName() string {
+	return "Skills"
+}
+
+// 指定表名
+func (EmployeeSkill) TableName() string {
+	return "Employee_Skills"
+}
@@ -5,6 +5,8 @@ type User struct {
 	Username     string `gorm:"column:Username;size:50;not null;unique" json:"username"`
 	PasswordHash string `gorm:"column:PasswordHash;size:255;not null" json:"password_hash"`
 	Role         string `gorm:"column:Role;size:20;default:User" json:"role"`
+	EmpNo        *int   `gorm:"column:EmpNo;index:idx_user_emp" json:"emp_no"`
+	Disabled     bool   `gorm:"column:Disabled;not null;default:false" json:"disabled"`
 }
 
 // 指定表名
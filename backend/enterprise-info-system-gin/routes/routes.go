@@ -2,6 +2,7 @@ package routes
 
 import (
 	"enterprise-info-system-gin/controllers"
+	"enterprise-info-system-gin/middleware"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,14 +17,21 @@ func SetupRoutes(r *gin.Engine) {
 	r.POST("/api/customers", controllers.CreateCustomer)
 	r.PUT("/api/customers", controllers.UpdateCustomer)
 	r.DELETE("/api/customers/:id", controllers.DeleteCustomer)
+	r.POST("/api/customers/import", middleware.OptionalLogin(), controllers.ImportCustomers)
+	r.GET("/api/customers/export", middleware.OptionalLogin(), controllers.ExportCustomers)
 
 	// 员工相关路由
 	r.GET("/api/employees", controllers.GetEmployees)
 	r.POST("/api/employees", controllers.CreateEmployee)
 	r.PUT("/api/employees", controllers.UpdateEmployee)
-	r.DELETE("/api/employees/:id", controllers.DeleteEmployee)
 	r.POST("/api/employees/search", controllers.SearchEmployees)
 	r.GET("/api/employees/:id/detail", controllers.GetEmployeeDetail)
+	r.GET("/api/employees/export", middleware.OptionalLogin(), controllers.ExportEmployees)
+	r.GET("/api/employees/:id/reports", controllers.GetEmployeeReports)
+	r.GET("/api/employees/:id/onboarding", controllers.GetEmployeeOnboardingTasks)
+
+	// 部门日历订阅，使用链接中的令牌验证，日历客户端无法登录
+	r.GET("/api/calendar/departments/:id/events.ics", controllers.GetDepartmentCalendarFeed)
 
 	// API 路由组
 	api := r.Group("/api")
@@ -35,14 +43,243 @@ func SetupRoutes(r *gin.Engine) {
 		api.DELETE("/departments/:id", controllers.DeleteDepartment)
 		api.GET("/departments/stats", controllers.GetDepartmentStats)
 		api.GET("/departments/:id/employees", controllers.GetDepartmentEmployees)
+		api.GET("/departments/export", middleware.OptionalLogin(), controllers.ExportDepartments)
+		api.GET("/departments/tree", controllers.GetDepartmentTree)
+		api.GET("/departments/:id/managers", controllers.GetDepartmentManagers)
+		api.GET("/departments/:id/headcount", controllers.GetDepartmentHeadcountTrend)
 
 		// 员工部门关系管理
 		api.GET("/employee-departments", controllers.GetEmployeeDepartments)
 		api.POST("/employee-departments", controllers.AddEmployeeDepartment)
 		api.PUT("/employee-departments/:id", controllers.UpdateEmployeeDepartment)
 		api.DELETE("/employee-departments/:id", controllers.DeleteEmployeeDepartment)
+		api.GET("/employee-departments/export", middleware.OptionalLogin(), controllers.ExportEmployeeDepartments)
 		
 		// 添加删除员工相关的所有部门关系的路由
 		api.DELETE("/employee-departments/employee/:empNo", controllers.DeleteEmployeeAllDepartments)
+
+		// 组织架构图
+		api.GET("/orgchart", controllers.GetOrgChart)
+
+		// 入职任务
+		api.GET("/onboarding", controllers.GetOnboardingTasks)
+		api.GET("/onboarding/templates", controllers.GetOnboardingTemplates)
+
+		// 职位管理
+		api.GET("/positions", controllers.GetPositions)
+		api.GET("/positions/headcount", controllers.GetPositionHeadcount)
+
+		// 技能目录
+		api.GET("/skills", controllers.GetSkills)
+
+		// 绩效考核周期
+		api.GET("/review-cycles", controllers.GetReviewCycles)
+
+		// 考勤
+		api.GET("/attendance/settings", controllers.GetAttendanceSettings)
+	}
+
+	// 需要登录的路由
+	auth := r.Group("/api", middleware.RequireLogin())
+	{
+		// 后台任务（仅提交者本人或管理员可以访问）
+		auth.GET("/jobs", controllers.GetJobs)
+		auth.GET("/jobs/:id", controllers.GetJob)
+		auth.POST("/jobs/:id/cancel", controllers.CancelJob)
+		auth.GET("/jobs/:id/result", controllers.DownloadJobResult)
+		auth.POST("/departments/reconcile-headcount", controllers.ReconcileDepartmentHeadcounts)
+
+		// 员工调岗记录（员工本人、上级或管理员）
+		auth.GET("/employees/:id/transfers", controllers.GetEmployeeTransfers)
+
+		// 员工请假（员工本人、上级或管理员）
+		auth.GET("/employees/:id/leave", controllers.GetEmployeeLeave)
+		auth.POST("/employees/:id/leave", controllers.ApplyLeave)
+
+		// 请假申请列表（管理员可以看到全部，其他用户只能看到本人和下属员工的申请）
+		auth.GET("/leave", controllers.GetLeaveRequests)
+
+		// 请假审批（管理员或部门经理）
+		auth.POST("/leave/:id/approve", controllers.ApproveLeave)
+		auth.POST("/leave/:id/reject", controllers.RejectLeave)
+		auth.POST("/leave/:id/cancel", controllers.CancelLeave)
+
+		// 打卡
+		auth.POST("/attendance/clock-in", controllers.ClockIn)
+		auth.POST("/attendance/clock-out", controllers.ClockOut)
+		auth.GET("/employees/:id/attendance", controllers.GetEmployeeAttendance)
+
+		// 部门考勤报表（管理员可以查看所有部门，部门经理只能查看自己的部门）
+		auth.GET("/attendance/report", controllers.GetAttendanceReport)
+
+		// 员工附件（合同、证件等仅管理员和员工本人可见）
+		auth.GET("/employees/:id/attachments", controllers.GetEmployeeAttachments)
+		auth.GET("/employees/:id/photo", controllers.GetEmployeePhoto)
+		auth.GET("/attachments/:id", controllers.DownloadAttachment)
+
+		// 员工个人信息（查看和修改权限见 services/employee_profile_service.go）
+		auth.GET("/employees/:id/emergency-contacts", controllers.GetEmergencyContacts)
+		auth.POST("/employees/:id/emergency-contacts", controllers.SaveEmergencyContact)
+		auth.PUT("/employees/:id/emergency-contacts/:itemId", controllers.SaveEmergencyContact)
+		auth.DELETE("/employees/:id/emergency-contacts/:itemId", controllers.DeleteEmergencyContact)
+		auth.GET("/employees/:id/emails", controllers.GetEmployeeEmails)
+		auth.POST("/employees/:id/emails", controllers.SaveEmployeeEmail)
+		auth.PUT("/employees/:id/emails/:itemId", controllers.SaveEmployeeEmail)
+		auth.DELETE("/employees/:id/emails/:itemId", controllers.DeleteEmployeeEmail)
+		auth.GET("/employees/:id/id-documents", controllers.GetIdentityDocuments)
+		auth.POST("/employees/:id/id-documents", controllers.SaveIdentityDocument)
+		auth.PUT("/employees/:id/id-documents/:itemId", controllers.SaveIdentityDocument)
+		auth.DELETE("/employees/:id/id-documents/:itemId", controllers.DeleteIdentityDocument)
+		auth.GET("/employees/:id/education", controllers.GetEducationRecords)
+		auth.POST("/employees/:id/education", controllers.SaveEducationRecord)
+		auth.PUT("/employees/:id/education/:itemId", controllers.SaveEducationRecord)
+		auth.DELETE("/employees/:id/education/:itemId", controllers.DeleteEducationRecord)
+		auth.GET("/employees/:id/bank-accounts", controllers.GetBankAccounts)
+		auth.POST("/employees/:id/bank-accounts", controllers.SaveBankAccount)
+		auth.PUT("/employees/:id/bank-accounts/:itemId", controllers.SaveBankAccount)
+		auth.DELETE("/employees/:id/bank-accounts/:itemId", controllers.DeleteBankAccount)
+
+		// 员工技能（所有登录用户可以查看，管理员或员工本人可以修改）
+		auth.GET("/employees/:id/skills", controllers.GetEmployeeSkills)
+		auth.PUT("/employees/:id/skills", controllers.SetEmployeeSkill)
+		auth.DELETE("/employees/:id/skills/:skillId", controllers.RemoveEmployeeSkill)
+
+		// 绩效评估（评估人填写和提交，员工本人确认）
+		auth.GET("/review-cycles/:id/reviews", controllers.GetCycleReviews)
+		auth.GET("/employees/:id/reviews", controllers.GetEmployeeReviews)
+		auth.PUT("/reviews/:id", controllers.UpdateReview)
+		auth.POST("/reviews/:id/submit", controllers.SubmitReview)
+		auth.POST("/reviews/:id/acknowledge", controllers.AcknowledgeReview)
+
+		// 更新入职任务（管理员、员工本人或上级）
+		auth.PUT("/onboarding/:id", controllers.UpdateOnboardingTask)
+
+		// 首页汇总
+		auth.GET("/dashboard", controllers.GetDashboard)
+
+		// 通讯录导出（vCard、LDIF）
+		auth.GET("/directory/vcard", controllers.ExportDirectoryVCards)
+		auth.GET("/directory/ldif", controllers.ExportDirectoryLDIF)
+		auth.GET("/employees/:id/vcard", controllers.ExportEmployeeVCard)
+
+		// 生日和入职周年日历（员工可设置隐藏）
+		auth.GET("/calendar/events", controllers.GetCalendarEvents)
+		auth.GET("/calendar/departments/:id/feed-url", controllers.GetDepartmentCalendarFeedURL)
+		auth.GET("/employees/:id/calendar-preferences", controllers.GetCalendarPreference)
+		auth.PUT("/employees/:id/calendar-preferences", controllers.UpdateCalendarPreference)
+
+		// 人力资源统计分析
+		auth.GET("/analytics/hires-leavers", controllers.GetMonthlyHiresLeavers)
+		auth.GET("/analytics/turnover", controllers.GetTurnoverReport)
+		auth.GET("/analytics/tenure", controllers.GetTenureStats)
+		auth.GET("/analytics/age", controllers.GetAgeStats)
+		auth.GET("/analytics/gender", controllers.GetGenderStats)
+
+		// 通知
+		auth.GET("/notifications", controllers.GetNotifications)
+		auth.POST("/notifications/read-all", controllers.MarkAllNotificationsRead)
+		auth.POST("/notifications/:id/read", controllers.MarkNotificationRead)
+	}
+
+	// 仅管理员可访问的路由
+	admin := r.Group("/api", middleware.RequireRole("Admin"))
+	{
+		// 薪资管理
+		admin.GET("/employees/:id/salaries", controllers.GetEmployeeSalaries)
+		admin.POST("/employees/:id/salaries", controllers.CreateSalary)
+		admin.PUT("/salaries/:id", controllers.UpdateSalary)
+		admin.DELETE("/salaries/:id", controllers.DeleteSalary)
+		admin.GET("/salaries/payroll", controllers.GetDepartmentPayroll)
+
+		// 考勤规则
+		admin.PUT("/attendance/settings", controllers.UpdateAttendanceSettings)
+
+		// 员工附件上传和删除
+		admin.POST("/employees/:id/attachments", controllers.UploadEmployeeAttachment)
+		admin.DELETE("/attachments/:id", controllers.DeleteAttachment)
+
+		// 劳动合同
+		admin.GET("/employees/:id/contracts", controllers.GetEmployeeContracts)
+		admin.POST("/employees/:id/contracts", controllers.CreateContract)
+		admin.PUT("/contracts/:id", controllers.UpdateContract)
+		admin.DELETE("/contracts/:id", controllers.DeleteContract)
+		admin.GET("/contracts/expiring", controllers.GetExpiringContracts)
+
+		// 绩效考核周期管理
+		admin.POST("/review-cycles", controllers.CreateReviewCycle)
+		admin.POST("/review-cycles/:id/close", controllers.CloseReviewCycle)
+		admin.DELETE("/review-cycles/:id", controllers.DeleteReviewCycle)
+		admin.GET("/review-cycles/:id/summary", controllers.GetReviewSummary)
+
+		// 历史人数快照补录
+		admin.POST("/departments/headcount/backfill", controllers.BackfillHeadcountSnapshots)
+
+		// 入职任务模板维护
+		admin.POST("/onboarding/templates", controllers.CreateOnboardingTemplate)
+		admin.PUT("/onboarding/templates/:id", controllers.UpdateOnboardingTemplate)
+		admin.DELETE("/onboarding/templates/:id", controllers.DeleteOnboardingTemplate)
+
+		// 职位维护
+		admin.POST("/positions", controllers.CreatePosition)
+		admin.PUT("/positions/:id", controllers.UpdatePosition)
+		admin.DELETE("/positions/:id", controllers.DeletePosition)
+
+		// 技能目录维护
+		admin.POST("/skills", controllers.CreateSkill)
+		admin.PUT("/skills/:id", controllers.UpdateSkill)
+		admin.DELETE("/skills/:id", controllers.DeleteSkill)
+
+		// 设置直属上级
+		admin.PUT("/employees/:id/manager", controllers.SetEmployeeManager)
+
+		// 调整部门层级
+		admin.PUT("/departments/:id/move", controllers.MoveDepartment)
+
+		// 任命部门经理
+		admin.POST("/departments/:id/managers", controllers.AssignDepartmentManager)
+
+		// 员工调岗
+		admin.POST("/employees/:id/transfer", controllers.TransferEmployee)
+
+		// 员工离职
+		admin.POST("/employees/:id/terminate", controllers.TerminateEmployee)
+
+		// 删除员工（同时删除薪资、合同、附件和个人信息）
+		admin.DELETE("/employees/:id", controllers.DeleteEmployee)
+
+		// 账号关联员工
+		admin.PUT("/users/:id/employee", controllers.LinkUserEmployee)
+
+		// 修改账号角色
+		admin.PUT("/users/:id/role", controllers.SetUserRole)
+
+		// 重新生成部门日历订阅链接
+		admin.POST("/calendar/departments/:id/feed-url/rotate", controllers.RotateDepartmentCalendarFeedURL)
+
+		// SCIM 访问令牌管理
+		admin.GET("/scim-tokens", controllers.GetSCIMTokens)
+		admin.POST("/scim-tokens", controllers.CreateSCIMToken)
+		admin.DELETE("/scim-tokens/:id", controllers.DeleteSCIMToken)
+	}
+
+	// SCIM 2.0 用户和组同步接口（RFC 7644），供身份提供方使用 SCIM 访问令牌调用
+	scim := r.Group("/scim/v2", middleware.RequireSCIMToken())
+	{
+		scim.GET("/ServiceProviderConfig", controllers.GetSCIMServiceProviderConfig)
+		scim.GET("/ResourceTypes", controllers.GetSCIMResourceTypes)
+
+		scim.GET("/Users", controllers.ListSCIMUsers)
+		scim.POST("/Users", controllers.CreateSCIMUser)
+		scim.GET("/Users/:id", controllers.GetSCIMUser)
+		scim.PUT("/Users/:id", controllers.ReplaceSCIMUser)
+		scim.PATCH("/Users/:id", controllers.PatchSCIMUser)
+		scim.DELETE("/Users/:id", controllers.DeleteSCIMUser)
+
+		scim.GET("/Groups", controllers.ListSCIMGroups)
+		scim.POST("/Groups", controllers.CreateSCIMGroup)
+		scim.GET("/Groups/:id", controllers.GetSCIMGroup)
+		scim.PUT("/Groups/:id", controllers.ReplaceSCIMGroup)
+		scim.PATCH("/Groups/:id", controllers.PatchSCIMGroup)
+		scim.DELETE("/Groups/:id", controllers.DeleteSCIMGroup)
 	}
 } 
\ No newline at end of file
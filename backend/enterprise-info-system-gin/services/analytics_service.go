@@ -0,0 +1,351 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+// 统计的时间范围和部门，From、To 均包含在内，DeptNo 为 0 表示全公司
+type AnalyticsFilter struct {
+	From   time.Time
+	To     time.Time
+	DeptNo int
+}
+
+// 统计范围最多十年
+const maxAnalyticsMonths = 120
+
+// 员工的离职日期：优先使用离职登记的日期，旧数据使用最后一条部门关系的离开日期
+const employeeLeaveDateSQL = "COALESCE(e.TerminationDate, (SELECT MAX(x.EdLeaveDate) FROM Employee_Department x WHERE x.EmpNo = e.EmpNo))"
+
+// 部门关系在某一时刻是否有效
+const relationActiveAtSQL = "ed.EdEntryDate < ? AND ((ed.EdLeaveDate IS NULL AND ed.EdStatus = 1) OR ed.EdLeaveDate >= ?)"
+
+// 解析统计参数，日期格式为 YYYY-MM-DD：to 默认为今天；
+// from 默认为 defaultMonths 个月前的月初，defaultMonths 为 0 时默认与 to 相同
+func ParseAnalyticsFilter(from, to, deptNo string, defaultMonths int) (*AnalyticsFilter, error) {
+	filter := &AnalyticsFilter{To: truncateToDay(time.Now())}
+	if to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return nil, errors.New("无效的结束日期格式")
+		}
+		filter.To = parsed
+	}
+
+	filter.From = filter.To
+	if defaultMonths > 0 {
+		filter.From = time.Date(filter.To.Year(), filter.To.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, 1-defaultMonths, 0)
+	}
+	if from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return nil, errors.New("无效的开始日期格式")
+		}
+		filter.From = parsed
+	}
+	if filter.To.Before(filter.From) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+	if filter.From.AddDate(0, maxAnalyticsMonths, 0).Before(filter.To) {
+		return nil, errors.New("统计范围不能超过" + strconv.Itoa(maxAnalyticsMonths/12) + "年")
+	}
+
+	if deptNo != "" && deptNo != "0" {
+		id, err := strconv.Atoi(deptNo)
+		if err != nil {
+			return nil, errors.New("无效的部门ID")
+		}
+		var dept models.Department
+		if err := utils.DB.First(&dept, id).Error; err != nil {
+			return nil, errors.New("部门不存在")
+		}
+		filter.DeptNo = id
+	}
+	return filter, nil
+}
+
+// 统计范围的结束时刻（To 的次日零点）
+func (f *AnalyticsFilter) until() time.Time {
+	return f.To.AddDate(0, 0, 1)
+}
+
+// 按月统计入职和离职人数：全公司按员工的入职日期和离职日期统计，
+// 指定部门时按该部门关系的加入日期和离开日期统计（含调动）
+func GetMonthlyHiresLeavers(filter *AnalyticsFilter) ([]models.MonthlyHiresLeavers, error) {
+	type monthCount struct {
+		Month string
+		Count int
+	}
+	var hires, leavers []monthCount
+
+	if filter.DeptNo == 0 {
+		if err := utils.DB.Raw(`
+			SELECT DATE_FORMAT(HireDate, '%Y-%m') as Month, COUNT(*) as Count
+			FROM Employees
+			WHERE HireDate >= ? AND HireDate < ?
+			GROUP BY Month
+		`, filter.From, filter.until()).Scan(&hires).Error; err != nil {
+			return nil, errors.New("统计入职人数失败")
+		}
+		if err := utils.DB.Raw(`
+			SELECT DATE_FORMAT(l.LeaveDate, '%Y-%m') as Month, COUNT(*) as Count
+			FROM (
+				SELECT `+employeeLeaveDateSQL+` as LeaveDate
+				FROM Employees e
+				WHERE e.EmploymentStatus = ?
+			) l
+			WHERE l.LeaveDate >= ? AND l.LeaveDate < ?
+			GROUP BY Month
+		`, models.EmploymentTerminated, filter.From, filter.until()).Scan(&leavers).Error; err != nil {
+			return nil, errors.New("统计离职人数失败")
+		}
+	} else {
+		if err := utils.DB.Raw(`
+			SELECT DATE_FORMAT(EdEntryDate, '%Y-%m') as Month, COUNT(DISTINCT EmpNo) as Count
+			FROM Employee_Department
+			WHERE DeptNo = ? AND EdEntryDate >= ? AND EdEntryDate < ?
+			GROUP BY Month
+		`, filter.DeptNo, filter.From, filter.until()).Scan(&hires).Error; err != nil {
+			return nil, errors.New("统计入职人数失败")
+		}
+		if err := utils.DB.Raw(`
+			SELECT DATE_FORMAT(EdLeaveDate, '%Y-%m') as Month, COUNT(DISTINCT EmpNo) as Count
+			FROM Employee_Department
+			WHERE DeptNo = ? AND EdLeaveDate >= ? AND EdLeaveDate < ?
+			GROUP BY Month
+		`, filter.DeptNo, filter.From, filter.until()).Scan(&leavers).Error; err != nil {
+			return nil, errors.New("统计离职人数失败")
+		}
+	}
+
+	// 没有数据的月份补 0
+	result := []models.MonthlyHiresLeavers{}
+	index := make(map[string]int)
+	last := time.Date(filter.To.Year(), filter.To.Month(), 1, 0, 0, 0, 0, time.Local)
+	for m := time.Date(filter.From.Year(), filter.From.Month(), 1, 0, 0, 0, 0, time.Local); !m.After(last); m = m.AddDate(0, 1, 0) {
+		index[m.Format("2006-01")] = len(result)
+		result = append(result, models.MonthlyHiresLeavers{Month: m.Format("2006-01")})
+	}
+	for _, h := range hires {
+		if i, ok := index[h.Month]; ok {
+			result[i].Hires = h.Count
+		}
+	}
+	for _, l := range leavers {
+		if i, ok := index[l.Month]; ok {
+			result[i].Leavers = l.Count
+		}
+	}
+	return result, nil
+}
+
+// 计算离职率（百分比）：离职人数除以期初和期末的平均人数
+func turnoverRate(stats *models.TurnoverStats) float64 {
+	average := float64(stats.StartHeadcount+stats.EndHeadcount) / 2
+	if average == 0 {
+		return 0
+	}
+	return math.Round(float64(stats.Leavers)/average*10000) / 100
+}
+
+// 统计期间全公司和各部门的人员流动和离职率，
+// 部门的入职和离职包含部门间的调动
+func GetTurnoverReport(filter *AnalyticsFilter) (*models.TurnoverReport, error) {
+	from, until := filter.From, filter.until()
+	report := &models.TurnoverReport{
+		From:        dateOnly(filter.From),
+		To:          dateOnly(filter.To),
+		Departments: []models.TurnoverStats{},
+	}
+
+	company := &report.Company
+	if err := utils.DB.Raw(`
+		SELECT
+			COALESCE(SUM(CASE WHEN l.HireDate < ? AND (l.EmploymentStatus = ? OR l.LeaveDate >= ?) THEN 1 ELSE 0 END), 0) as StartHeadcount,
+			COALESCE(SUM(CASE WHEN l.HireDate < ? AND (l.EmploymentStatus = ? OR l.LeaveDate >= ?) THEN 1 ELSE 0 END), 0) as EndHeadcount,
+			COALESCE(SUM(CASE WHEN l.HireDate >= ? AND l.HireDate < ? THEN 1 ELSE 0 END), 0) as Hires,
+			COALESCE(SUM(CASE WHEN l.EmploymentStatus = ? AND l.LeaveDate >= ? AND l.LeaveDate < ? THEN 1 ELSE 0 END), 0) as Leavers
+		FROM (
+			SELECT e.HireDate, e.EmploymentStatus, `+employeeLeaveDateSQL+` as LeaveDate
+			FROM Employees e
+		) l
+	`,
+		from, models.EmploymentActive, from,
+		until, models.EmploymentActive, until,
+		from, until,
+		models.EmploymentTerminated, from, until,
+	).Scan(company).Error; err != nil {
+		return nil, errors.New("统计全公司人员流动失败")
+	}
+	company.DeptName = "全公司"
+	company.TurnoverRate = turnoverRate(company)
+
+	query := utils.DB.Table("Departments d").
+		Select(`d.DeptNo, d.DeptName,
+			COUNT(DISTINCT CASE WHEN `+relationActiveAtSQL+` THEN ed.EmpNo END) as StartHeadcount,
+			COUNT(DISTINCT CASE WHEN `+relationActiveAtSQL+` THEN ed.EmpNo END) as EndHeadcount,
+			COUNT(DISTINCT CASE WHEN ed.EdEntryDate >= ? AND ed.EdEntryDate < ? THEN ed.EmpNo END) as Hires,
+			COUNT(DISTINCT CASE WHEN ed.EdLeaveDate >= ? AND ed.EdLeaveDate < ? THEN ed.EmpNo END) as Leavers`,
+			from, from, until, until, from, until, from, until).
+		Joins("LEFT JOIN Employee_Department ed ON ed.DeptNo = d.DeptNo").
+		Group("d.DeptNo, d.DeptName").
+		Order("d.DeptNo")
+	if filter.DeptNo != 0 {
+		query = query.Where("d.DeptNo = ?", filter.DeptNo)
+	}
+	if err := query.Scan(&report.Departments).Error; err != nil {
+		return nil, errors.New("统计部门人员流动失败")
+	}
+	for i := range report.Departments {
+		report.Departments[i].TurnoverRate = turnoverRate(&report.Departments[i])
+	}
+	return report, nil
+}
+
+// 统计范围内的员工
+type analyticsEmployee struct {
+	EmpNo     int
+	Gender    int
+	HireDate  time.Time
+	Birthday  *time.Time
+	LeaveDate *time.Time
+}
+
+// 获取统计期间内曾经在职的员工，指定部门时只包含期间内属于该部门的员工
+func loadAnalyticsEmployees(filter *AnalyticsFilter) ([]analyticsEmployee, error) {
+	from, until := filter.From, filter.until()
+	query := utils.DB.Table("Employees e").
+		Select("e.EmpNo, e.Gender, e.HireDate, e.Birthday, "+employeeLeaveDateSQL+" as LeaveDate").
+		Where("e.HireDate < ?", until).
+		Where("e.EmploymentStatus = ? OR "+employeeLeaveDateSQL+" >= ?", models.EmploymentActive, from)
+	if filter.DeptNo != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM Employee_Department ed WHERE ed.EmpNo = e.EmpNo AND ed.DeptNo = ? AND "+relationActiveAtSQL+")",
+			filter.DeptNo, until, from)
+	}
+
+	var employees []analyticsEmployee
+	if err := query.Scan(&employees).Error; err != nil {
+		return nil, errors.New("获取员工信息失败")
+	}
+	return employees, nil
+}
+
+// 统计员工的平均司龄和司龄分布，司龄计算到统计结束日期或离职日期
+func GetTenureStats(filter *AnalyticsFilter) (*models.TenureStats, error) {
+	employees, err := loadAnalyticsEmployees(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{"1年以下", "1-3年", "3-5年", "5-10年", "10年以上"}
+	limits := []float64{1, 3, 5, 10}
+	stats := &models.TenureStats{Distribution: make([]models.AnalyticsBucket, len(labels))}
+	for i, label := range labels {
+		stats.Distribution[i].Label = label
+	}
+
+	total := 0.0
+	for _, e := range employees {
+		end := filter.To
+		if e.LeaveDate != nil && e.LeaveDate.Before(end) {
+			end = *e.LeaveDate
+		}
+		years := end.Sub(truncateToDay(e.HireDate)).Hours() / 24 / 365.25
+		if years < 0 {
+			years = 0
+		}
+		total += years
+
+		bucket := len(limits)
+		for i, limit := range limits {
+			if years < limit {
+				bucket = i
+				break
+			}
+		}
+		stats.Distribution[bucket].Count++
+	}
+
+	stats.Employees = len(employees)
+	if stats.Employees > 0 {
+		stats.AverageYears = math.Round(total/float64(stats.Employees)*100) / 100
+	}
+	return stats, nil
+}
+
+// 计算在某一天的周岁年龄
+func ageOn(birthday, day time.Time) int {
+	age := day.Year() - birthday.Year()
+	if day.Month() < birthday.Month() || (day.Month() == birthday.Month() && day.Day() < birthday.Day()) {
+		age--
+	}
+	return age
+}
+
+// 统计员工在统计结束日期的年龄分布
+func GetAgeStats(filter *AnalyticsFilter) (*models.AgeStats, error) {
+	employees, err := loadAnalyticsEmployees(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []string{"25岁以下", "25-34岁", "35-44岁", "45-54岁", "55岁及以上"}
+	limits := []int{25, 35, 45, 55}
+	stats := &models.AgeStats{Distribution: make([]models.AnalyticsBucket, len(labels))}
+	for i, label := range labels {
+		stats.Distribution[i].Label = label
+	}
+
+	total, known := 0, 0
+	for _, e := range employees {
+		if e.Birthday == nil || e.Birthday.Year() < 1900 || e.Birthday.After(filter.To) {
+			stats.Unknown++
+			continue
+		}
+		age := ageOn(*e.Birthday, filter.To)
+		total += age
+		known++
+
+		bucket := len(limits)
+		for i, limit := range limits {
+			if age < limit {
+				bucket = i
+				break
+			}
+		}
+		stats.Distribution[bucket].Count++
+	}
+
+	stats.Employees = len(employees)
+	if known > 0 {
+		stats.AverageAge = math.Round(float64(total)/float64(known)*10) / 10
+	}
+	return stats, nil
+}
+
+// 统计员工的性别比例
+func GetGenderStats(filter *AnalyticsFilter) (*models.GenderStats, error) {
+	employees, err := loadAnalyticsEmployees(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.GenderStats{Employees: len(employees)}
+	for _, e := range employees {
+		if e.Gender == 1 {
+			stats.Male++
+		} else {
+			stats.Female++
+		}
+	}
+	if stats.Employees > 0 {
+		stats.MaleRatio = math.Round(float64(stats.Male)/float64(stats.Employees)*10000) / 100
+		stats.FemaleRatio = math.Round(float64(stats.Female)/float64(stats.Employees)*10000) / 100
+	}
+	return stats, nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"bytes"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 附件文件的存储位置，可以替换为 S3 兼容的实现
+var AttachmentStorage utils.ObjectStorage = utils.NewLocalObjectStorage("data/attachments")
+
+// 附件大小上限和缩略图尺寸
+const (
+	MaxAttachmentSize = 20 << 20
+	MaxPhotoSize      = 5 << 20
+	thumbnailSize     = 200
+)
+
+// 各类附件允许的文件类型（按文件内容识别）
+var attachmentContentTypes = map[string][]string{
+	models.AttachmentContract: {"application/pdf", "image/jpeg", "image/png"},
+	models.AttachmentIDScan:   {"application/pdf", "image/jpeg", "image/png"},
+	models.AttachmentPhoto:    {"image/jpeg", "image/png", "image/gif"},
+	models.AttachmentOther:    {"application/pdf", "image/jpeg", "image/png", "image/gif", "text/plain; charset=utf-8"},
+}
+
+// 用户是否可以查看该类附件：照片所有登录用户可见，
+// 合同和证件等只有管理员和员工本人可见
+func CanViewAttachment(user *models.User, empNo int, category string) bool {
+	if category == models.AttachmentPhoto || user.Role == "Admin" {
+		return true
+	}
+	return user.EmpNo != nil && *user.EmpNo == empNo
+}
+
+// 获取员工的附件列表，只返回当前用户可以查看的附件
+func GetEmployeeAttachments(empNo int, user *models.User) ([]models.EmployeeAttachment, error) {
+	var emp models.Employee
+	if err := utils.DB.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+
+	var attachments []models.EmployeeAttachment
+	if err := utils.DB.Where("EmpNo = ?", empNo).
+		Order("CreatedAt DESC").
+		Find(&attachments).Error; err != nil {
+		return nil, errors.New("获取附件列表失败")
+	}
+
+	visible := []models.EmployeeAttachment{}
+	for _, a := range attachments {
+		if CanViewAttachment(user, empNo, a.Category) {
+			a.HasThumbnail = a.ThumbnailKey != ""
+			visible = append(visible, a)
+		}
+	}
+	return visible, nil
+}
+
+// 上传员工附件：校验类别、大小和文件内容类型，照片生成缩略图并替换原有照片
+func UploadEmployeeAttachment(empNo int, category, fileName string, data []byte, uploadedBy *int) (*models.EmployeeAttachment, error) {
+	allowed, ok := attachmentContentTypes[category]
+	if !ok {
+		return nil, errors.New("无效的附件类别")
+	}
+	maxSize := MaxAttachmentSize
+	if category == models.AttachmentPhoto {
+		maxSize = MaxPhotoSize
+	}
+	if len(data) == 0 {
+		return nil, errors.New("上传的文件为空")
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("文件大小不能超过%dMB", maxSize>>20)
+	}
+
+	contentType := http.DetectContentType(data)
+	valid := false
+	for _, t := range allowed {
+		if contentType == t {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, errors.New("不支持的文件类型：" + contentType)
+	}
+
+	var emp models.Employee
+	if err := utils.DB.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+
+	fileName = filepath.Base(strings.ReplaceAll(fileName, "\\", "/"))
+	if fileName == "." || fileName == "/" {
+		fileName = category
+	}
+
+	// 键名带上时间戳，同名文件不会互相覆盖
+	prefix := fmt.Sprintf("employees/%d/%s/%d", empNo, category, time.Now().UnixNano())
+	attachment := &models.EmployeeAttachment{
+		EmpNo:       empNo,
+		Category:    category,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  prefix + filepath.Ext(fileName),
+		UploadedBy:  uploadedBy,
+	}
+
+	if category == models.AttachmentPhoto {
+		thumbnail, err := utils.MakeThumbnail(bytes.NewReader(data), thumbnailSize)
+		if errors.Is(err, utils.ErrImageTooLarge) {
+			return nil, errors.New("图片尺寸过大，像素数不能超过4000万")
+		}
+		if err != nil {
+			return nil, errors.New("无法识别的图片文件")
+		}
+		attachment.ThumbnailKey = prefix + "-thumb.jpg"
+		if err := AttachmentStorage.PutObject(attachment.ThumbnailKey, bytes.NewReader(thumbnail), "image/jpeg"); err != nil {
+			return nil, errors.New("保存缩略图失败")
+		}
+	}
+
+	if err := AttachmentStorage.PutObject(attachment.StorageKey, bytes.NewReader(data), contentType); err != nil {
+		deleteAttachmentObjects(attachment)
+		return nil, errors.New("保存附件失败")
+	}
+
+	// 照片只保留最新一张
+	var previous []models.EmployeeAttachment
+	if category == models.AttachmentPhoto {
+		if err := utils.DB.Where("EmpNo = ? AND Category = ?", empNo, models.AttachmentPhoto).
+			Find(&previous).Error; err != nil {
+			deleteAttachmentObjects(attachment)
+			return nil, errors.New("获取原有照片失败")
+		}
+	}
+
+	if err := utils.DB.Create(attachment).Error; err != nil {
+		deleteAttachmentObjects(attachment)
+		return nil, errors.New("保存附件信息失败")
+	}
+
+	for i := range previous {
+		if err := utils.DB.Delete(&previous[i]).Error; err == nil {
+			deleteAttachmentObjects(&previous[i])
+		}
+	}
+
+	attachment.HasThumbnail = attachment.ThumbnailKey != ""
+	return attachment, nil
+}
+
+// 获取附件信息
+func GetAttachment(id int) (*models.EmployeeAttachment, error) {
+	var attachment models.EmployeeAttachment
+	if err := utils.DB.First(&attachment, id).Error; err != nil {
+		return nil, errors.New("附件不存在")
+	}
+	attachment.HasThumbnail = attachment.ThumbnailKey != ""
+	return &attachment, nil
+}
+
+// 获取员工当前的照片
+func GetEmployeePhoto(empNo int) (*models.EmployeeAttachment, error) {
+	var attachment models.EmployeeAttachment
+	if err := utils.DB.Where("EmpNo = ? AND Category = ?", empNo, models.AttachmentPhoto).
+		Order("CreatedAt DESC").
+		First(&attachment).Error; err != nil {
+		return nil, errors.New("该员工没有照片")
+	}
+	attachment.HasThumbnail = attachment.ThumbnailKey != ""
+	return &attachment, nil
+}
+
+// 读取附件内容，thumbnail 为 true 时读取缩略图
+func OpenAttachment(attachment *models.EmployeeAttachment, thumbnail bool) (io.ReadCloser, string, error) {
+	key, contentType := attachment.StorageKey, attachment.ContentType
+	if thumbnail {
+		if attachment.ThumbnailKey == "" {
+			return nil, "", errors.New("该附件没有缩略图")
+		}
+		key, contentType = attachment.ThumbnailKey, "image/jpeg"
+	}
+
+	r, err := AttachmentStorage.GetObject(key)
+	if err != nil {
+		return nil, "", errors.New("附件文件不存在")
+	}
+	return r, contentType, nil
+}
+
+// 删除附件
+func DeleteAttachment(id int) error {
+	attachment, err := GetAttachment(id)
+	if err != nil {
+		return err
+	}
+	if err := utils.DB.Delete(attachment).Error; err != nil {
+		return errors.New("删除附件失败")
+	}
+	deleteAttachmentObjects(attachment)
+	return nil
+}
+
+// 删除员工的所有附件文件，附件记录由调用方删除
+func deleteEmployeeAttachmentObjects(attachments []models.EmployeeAttachment) {
+	for i := range attachments {
+		deleteAttachmentObjects(&attachments[i])
+	}
+}
+
+// 删除附件对应的文件，失败时只记录日志，不影响数据库操作
+func deleteAttachmentObjects(attachment *models.EmployeeAttachment) {
+	for _, key := range []string{attachment.StorageKey, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := AttachmentStorage.DeleteObject(key); err != nil {
+			log.Printf("删除附件文件 %s 失败: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 未配置考勤规则时使用的默认规则
+var defaultAttendanceSettings = models.AttendanceSettings{
+	SettingsID: 1,
+	WorkStart:  "09:00",
+	WorkEnd:    "18:00",
+}
+
+// 获取考勤规则
+func GetAttendanceSettings() (*models.AttendanceSettings, error) {
+	var settings models.AttendanceSettings
+	err := utils.DB.First(&settings, 1).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		settings = defaultAttendanceSettings
+		return &settings, nil
+	}
+	if err != nil {
+		return nil, errors.New("获取考勤规则失败")
+	}
+	return &settings, nil
+}
+
+// 更新考勤规则
+func UpdateAttendanceSettings(req *models.AttendanceSettings) (*models.AttendanceSettings, error) {
+	start, err := time.Parse("15:04", req.WorkStart)
+	if err != nil {
+		return nil, errors.New("无效的上班时间格式")
+	}
+	end, err := time.Parse("15:04", req.WorkEnd)
+	if err != nil {
+		return nil, errors.New("无效的下班时间格式")
+	}
+	if !end.After(start) {
+		return nil, errors.New("下班时间必须晚于上班时间")
+	}
+	if req.LateGraceMinutes < 0 || req.LateGraceMinutes > 120 {
+		return nil, errors.New("迟到宽限时间必须在0到120分钟之间")
+	}
+
+	settings := &models.AttendanceSettings{
+		SettingsID:       1,
+		WorkStart:        start.Format("15:04"),
+		WorkEnd:          end.Format("15:04"),
+		LateGraceMinutes: req.LateGraceMinutes,
+	}
+	if err := utils.DB.Save(settings).Error; err != nil {
+		return nil, errors.New("更新考勤规则失败")
+	}
+	return settings, nil
+}
+
+// 获取员工当天的打卡记录，不存在时返回 gorm.ErrRecordNotFound
+func findAttendanceRecord(tx *gorm.DB, empNo int, workDate time.Time, record *models.AttendanceRecord) error {
+	err := tx.Where("EmpNo = ? AND WorkDate = ?", empNo, workDate).First(record).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("获取考勤记录失败")
+	}
+	return err
+}
+
+// 上班打卡，每天只能打一次
+func ClockIn(empNo int) (*models.AttendanceRecord, error) {
+	now := time.Now()
+	var record models.AttendanceRecord
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkAttendanceEmployee(tx, empNo); err != nil {
+			return err
+		}
+
+		err := findAttendanceRecord(tx, empNo, truncateToDay(now), &record)
+		if err == nil {
+			if record.ClockIn != nil {
+				return errors.New("今天已经打过上班卡")
+			}
+			if err := tx.Model(&record).Update("ClockIn", now).Error; err != nil {
+				return errors.New("打卡失败")
+			}
+			record.ClockIn = &now
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		record = models.AttendanceRecord{EmpNo: empNo, WorkDate: truncateToDay(now), ClockIn: &now}
+		if err := tx.Create(&record).Error; err != nil {
+			// 同时提交的两次打卡，另一次已经创建了当天的记录
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errors.New("今天已经打过上班卡")
+			}
+			return errors.New("打卡失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// 下班打卡，重复打卡时以最后一次为准；没有上班卡时同样记录，按缺卡处理
+func ClockOut(empNo int) (*models.AttendanceRecord, error) {
+	now := time.Now()
+	var record models.AttendanceRecord
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkAttendanceEmployee(tx, empNo); err != nil {
+			return err
+		}
+
+		err := findAttendanceRecord(tx, empNo, truncateToDay(now), &record)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			record = models.AttendanceRecord{EmpNo: empNo, WorkDate: truncateToDay(now), ClockOut: &now}
+			err = tx.Create(&record).Error
+			if err == nil {
+				return nil
+			}
+			// 同时提交的两次打卡，另一次已经创建了当天的记录，改为更新该记录
+			if !errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errors.New("打卡失败")
+			}
+			err = findAttendanceRecord(tx, empNo, truncateToDay(now), &record)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&record).Update("ClockOut", now).Error; err != nil {
+			return errors.New("打卡失败")
+		}
+		record.ClockOut = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// 只有在职员工可以打卡
+func checkAttendanceEmployee(tx *gorm.DB, empNo int) error {
+	var emp models.Employee
+	if err := tx.First(&emp, empNo).Error; err != nil {
+		return errors.New("员工不存在")
+	}
+	if emp.EmploymentStatus == models.EmploymentTerminated {
+		return errors.New("该员工已离职")
+	}
+	return nil
+}
+
+// 按考勤规则计算一天的工时、迟到、早退和缺卡，today 当天尚未结束，不判定早退和缺卡
+func evaluateAttendance(record models.AttendanceRecord, settings *models.AttendanceSettings, today time.Time) models.AttendanceDay {
+	day := models.AttendanceDay{AttendanceRecord: record}
+	workDate := truncateToDay(record.WorkDate)
+	finished := workDate.Before(today)
+
+	start, _ := time.Parse("15:04", settings.WorkStart)
+	end, _ := time.Parse("15:04", settings.WorkEnd)
+	workStart := workDate.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+	workEnd := workDate.Add(time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute)
+
+	if record.ClockIn != nil {
+		day.Late = record.ClockIn.After(workStart.Add(time.Duration(settings.LateGraceMinutes) * time.Minute))
+	}
+	if record.ClockOut != nil && finished {
+		day.LeftEarly = record.ClockOut.Before(workEnd)
+	}
+	if record.ClockIn != nil && record.ClockOut != nil && record.ClockOut.After(*record.ClockIn) {
+		day.WorkedHours = math.Round(record.ClockOut.Sub(*record.ClockIn).Hours()*100) / 100
+	}
+	day.MissingPunch = finished && (record.ClockIn == nil || record.ClockOut == nil)
+	return day
+}
+
+// 解析 YYYY-MM 格式的月份，返回该月第一天和最后一天
+func parseMonth(month string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("无效的月份格式")
+	}
+	return start, start.AddDate(0, 1, -1), nil
+}
+
+// 获取员工某月的每日考勤：员工本人、上级或管理员可以查看
+func GetEmployeeAttendance(empNo int, month string, user *models.User) ([]models.AttendanceDay, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "attendance", false); err != nil {
+		return nil, err
+	}
+	start, end, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := GetAttendanceSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.AttendanceRecord
+	if err := utils.DB.Where("EmpNo = ? AND WorkDate BETWEEN ? AND ?", empNo, start, end).
+		Order("WorkDate").
+		Find(&records).Error; err != nil {
+		return nil, errors.New("获取考勤记录失败")
+	}
+
+	today := truncateToDay(time.Now())
+	days := make([]models.AttendanceDay, 0, len(records))
+	for _, r := range records {
+		days = append(days, evaluateAttendance(r, settings, today))
+	}
+	return days, nil
+}
+
+// 按部门生成月度考勤报表，只统计截至昨天的工作日（周一至周五）；
+// 没有打卡记录且没有已批准请假的工作日计为缺勤。deptNo 为 0 时统计所有部门；
+// 管理员可以查看所有部门，其他用户只能查看自己在任经理的部门
+func GetAttendanceReport(month string, deptNo int, user *models.User) ([]models.DepartmentAttendanceReport, error) {
+	start, end, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := GetAttendanceSettings()
+	if err != nil {
+		return nil, err
+	}
+	today := truncateToDay(time.Now())
+	if yesterday := today.AddDate(0, 0, -1); end.After(yesterday) {
+		end = yesterday
+	}
+
+	// 部门的在职员工
+	var members []struct {
+		DeptNo       int
+		DeptName     string
+		EmpNo        int
+		EmployeeName string
+		HireDate     time.Time
+	}
+	query := utils.DB.Table("Employee_Department ed").
+		Select("DISTINCT ed.DeptNo, d.DeptName, e.EmpNo, CONCAT(e.LastName, e.FirstName) as EmployeeName, e.HireDate").
+		Joins("INNER JOIN Departments d ON ed.DeptNo = d.DeptNo").
+		Joins("INNER JOIN Employees e ON ed.EmpNo = e.EmpNo").
+		Where("ed.EdStatus = 1")
+	if deptNo != 0 {
+		query = query.Where("ed.DeptNo = ?", deptNo)
+	}
+	if user.Role != "Admin" {
+		if user.EmpNo == nil {
+			return []models.DepartmentAttendanceReport{}, nil
+		}
+		managed := utils.DB.Model(&models.DepartmentManager{}).
+			Select("DeptNo").
+			Where("EmpNo = ? AND StartDate <= ? AND (EndDate IS NULL OR EndDate >= ?)", *user.EmpNo, today, today)
+		query = query.Where("ed.DeptNo IN (?)", managed)
+	}
+	if err := query.Order("ed.DeptNo, e.EmpNo").Scan(&members).Error; err != nil {
+		return nil, errors.New("获取部门员工失败")
+	}
+
+	empNos := make([]int, 0, len(members))
+	for _, m := range members {
+		empNos = append(empNos, m.EmpNo)
+	}
+
+	// 打卡记录和已批准的请假，按员工和日期索引
+	records := make(map[int]map[string]models.AttendanceRecord)
+	leaveDays := make(map[int]map[string]bool)
+	if len(empNos) > 0 && !end.Before(start) {
+		var list []models.AttendanceRecord
+		if err := utils.DB.Where("EmpNo IN ? AND WorkDate BETWEEN ? AND ?", empNos, start, end).
+			Find(&list).Error; err != nil {
+			return nil, errors.New("获取考勤记录失败")
+		}
+		for _, r := range list {
+			if records[r.EmpNo] == nil {
+				records[r.EmpNo] = make(map[string]models.AttendanceRecord)
+			}
+			records[r.EmpNo][dateOnly(r.WorkDate)] = r
+		}
+
+		var leaves []models.LeaveRequest
+		if err := utils.DB.Where("EmpNo IN ? AND Status = ? AND StartDate <= ? AND EndDate >= ?",
+			empNos, models.LeaveStatusApproved, end, start).
+			Find(&leaves).Error; err != nil {
+			return nil, errors.New("获取请假记录失败")
+		}
+		for _, l := range leaves {
+			if leaveDays[l.EmpNo] == nil {
+				leaveDays[l.EmpNo] = make(map[string]bool)
+			}
+			for d := l.StartDate; !d.After(l.EndDate); d = d.AddDate(0, 0, 1) {
+				leaveDays[l.EmpNo][dateOnly(d)] = true
+			}
+		}
+	}
+
+	reports := []models.DepartmentAttendanceReport{}
+	index := make(map[int]int)
+	for _, m := range members {
+		i, ok := index[m.DeptNo]
+		if !ok {
+			i = len(reports)
+			index[m.DeptNo] = i
+			reports = append(reports, models.DepartmentAttendanceReport{
+				DeptNo:    m.DeptNo,
+				DeptName:  m.DeptName,
+				Month:     start.Format("2006-01"),
+				Employees: []models.EmployeeAttendanceSummary{},
+			})
+		}
+
+		summary := models.EmployeeAttendanceSummary{EmpNo: m.EmpNo, EmployeeName: m.EmployeeName}
+		hireDate := truncateToDay(m.HireDate)
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || d.Before(hireDate) {
+				continue
+			}
+			summary.Workdays++
+
+			key := dateOnly(d)
+			if r, ok := records[m.EmpNo][key]; ok {
+				day := evaluateAttendance(r, settings, today)
+				summary.AttendedDays++
+				summary.WorkedHours += day.WorkedHours
+				if day.Late {
+					summary.LateCount++
+				}
+				if day.LeftEarly {
+					summary.LeftEarlyCount++
+				}
+				if day.MissingPunch {
+					summary.MissingPunchCount++
+				}
+			} else if leaveDays[m.EmpNo][key] {
+				summary.LeaveDays++
+			} else {
+				summary.AbsentDays++
+			}
+		}
+		summary.WorkedHours = math.Round(summary.WorkedHours*100) / 100
+
+		report := &reports[i]
+		report.Employees = append(report.Employees, summary)
+		report.LateCount += summary.LateCount
+		report.AbsentDays += summary.AbsentDays
+		report.MissingPunchCount += summary.MissingPunchCount
+		report.WorkedHours = math.Round((report.WorkedHours+summary.WorkedHours)*100) / 100
+	}
+
+	return reports, nil
+}
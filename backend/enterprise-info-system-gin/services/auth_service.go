@@ -4,8 +4,17 @@ import (
 	"enterprise-info-system-gin/models"
 	"enterprise-info-system-gin/utils"
 	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// 账号角色
+var userRoles = []string{"Admin", "User"}
+
 type LoginRequest struct {
     Username     string `json:"username"`
     PasswordHash string `json:"password_hash"`
@@ -22,15 +31,20 @@ func Login(req LoginRequest) (*models.User, error) {
         return nil, errors.New("密码错误")
     }
 
+    if user.Disabled {
+        return nil, errors.New("账号已停用")
+    }
+
     return &user, nil
 }
 
 type RegisterRequest struct {
     Username     string `json:"username"`
     PasswordHash string `json:"password_hash"`
-    Role         string `json:"role"`
 }
 
+// 公开注册只能创建普通用户，管理员角色和关联员工需由管理员设置；
+// 系统中还没有管理员时，用户名与 ADMIN_USERNAME 相同的账号注册为管理员
 func Register(req RegisterRequest) (*models.User, error) {
     // 检查用户名是否已存在
     var existingUser models.User
@@ -39,21 +53,22 @@ func Register(req RegisterRequest) (*models.User, error) {
         return nil, errors.New("用户名已存在")
     }
 
-    // 设置默认角色
-    if req.Role == "" {
-        req.Role = "User"
-    }
-
-    // 验证角色是否有效
-    if req.Role != "Admin" && req.Role != "User" {
-        return nil, errors.New("无效的用户角色")
+    role := "User"
+    if username := adminUsername(); username != "" && req.Username == username {
+        hasAdmin, err := hasActiveAdmin(utils.DB)
+        if err != nil {
+            return nil, err
+        }
+        if !hasAdmin {
+            role = "Admin"
+        }
     }
 
     // 创建新用户，直接使用前端传来的加密密码
     user := &models.User{
         Username:     req.Username,
         PasswordHash: req.PasswordHash,
-        Role:        req.Role,
+        Role:         role,
     }
 
     if err := utils.DB.Create(user).Error; err != nil {
@@ -61,4 +76,141 @@ func Register(req RegisterRequest) (*models.User, error) {
     }
 
     return user, nil
-} 
\ No newline at end of file
+}
+
+// 初始管理员的用户名，从 ADMIN_USERNAME 环境变量读取
+func adminUsername() string {
+    return strings.TrimSpace(os.Getenv("ADMIN_USERNAME"))
+}
+
+// 是否存在未停用的管理员账号
+func hasActiveAdmin(tx *gorm.DB) (bool, error) {
+    var count int64
+    if err := tx.Model(&models.User{}).
+        Where("Role = ? AND Disabled = ?", "Admin", false).
+        Count(&count).Error; err != nil {
+        return false, errors.New("检查管理员账号失败")
+    }
+    return count > 0, nil
+}
+
+// 系统中还没有管理员时，将用户名与 ADMIN_USERNAME 相同的已有账号设为管理员；
+// 已有管理员后不再生效，之后由管理员通过 PUT /api/users/:id/role 修改角色
+func BootstrapAdmin() {
+    username := adminUsername()
+    if username == "" {
+        return
+    }
+    hasAdmin, err := hasActiveAdmin(utils.DB)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if hasAdmin {
+        return
+    }
+
+    result := utils.DB.Model(&models.User{}).
+        Where("Username = ? AND Disabled = ?", username, false).
+        Update("Role", "Admin")
+    if result.Error != nil {
+        log.Fatal("设置初始管理员失败: ", result.Error)
+    }
+    if result.RowsAffected > 0 {
+        log.Printf("已将账号 %s 设为管理员", username)
+    }
+}
+
+// 修改账号角色的请求
+type SetUserRoleRequest struct {
+    Role string `json:"role"`
+}
+
+// 修改账号角色（仅管理员），不能取消最后一个管理员的角色
+func SetUserRole(userID int, req *SetUserRoleRequest) (*models.User, error) {
+    valid := false
+    for _, role := range userRoles {
+        if req.Role == role {
+            valid = true
+            break
+        }
+    }
+    if !valid {
+        return nil, errors.New("无效的角色")
+    }
+
+    var user models.User
+    err := utils.DB.Transaction(func(tx *gorm.DB) error {
+        // 锁定所有管理员账号，避免并发修改后没有管理员
+        var admins []models.User
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+            Where("Role = ?", "Admin").
+            Find(&admins).Error; err != nil {
+            return errors.New("检查管理员账号失败")
+        }
+        if err := tx.First(&user, userID).Error; err != nil {
+            return errors.New("用户不存在")
+        }
+        if user.Role == req.Role {
+            return nil
+        }
+
+        if user.Role == "Admin" {
+            others := 0
+            for _, admin := range admins {
+                if admin.UserID != user.UserID && !admin.Disabled {
+                    others++
+                }
+            }
+            if others == 0 {
+                return errors.New("至少需要保留一个管理员")
+            }
+        }
+
+        if err := tx.Model(&user).Update("Role", req.Role).Error; err != nil {
+            return errors.New("修改角色失败")
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &user, nil
+}
+
+// 关联账号的请求，EmpNo 为空表示取消关联
+type LinkUserEmployeeRequest struct {
+    EmpNo *int `json:"emp_no"`
+}
+
+// 将账号关联到员工（仅管理员），关联的员工必须存在且在职
+func LinkUserEmployee(userID int, req *LinkUserEmployeeRequest) (*models.User, error) {
+    var user models.User
+    if err := utils.DB.First(&user, userID).Error; err != nil {
+        return nil, errors.New("用户不存在")
+    }
+
+    if req.EmpNo != nil {
+        var emp models.Employee
+        if err := utils.DB.First(&emp, *req.EmpNo).Error; err != nil {
+            return nil, errors.New("关联的员工不存在")
+        }
+        if emp.EmploymentStatus == models.EmploymentTerminated {
+            return nil, errors.New("关联的员工已离职")
+        }
+    }
+
+    if err := utils.DB.Model(&user).Update("EmpNo", req.EmpNo).Error; err != nil {
+        return nil, errors.New("关联员工失败")
+    }
+    user.EmpNo = req.EmpNo
+    return &user, nil
+}
+
+// 根据用户ID获取用户
+func GetUserByID(userID int) (*models.User, error) {
+    var user models.User
+    if err := utils.DB.First(&user, userID).Error; err != nil {
+        return nil, errors.New("用户不存在")
+    }
+    return &user, nil
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 日历查询的默认天数和最大天数
+const (
+	calendarDefaultDays = 30
+	calendarMaxDays     = 366
+)
+
+// 日历中使用的员工生日、入职日期和隐私设置
+type calendarEmployee struct {
+	EmpNo           int
+	EmployeeName    string
+	HireDate        time.Time
+	Birthday        *time.Time
+	HideBirthday    bool
+	HideAnniversary bool
+}
+
+// 是否显示该员工的生日，未填写或明显无效的生日不显示
+func (e *calendarEmployee) showBirthday() bool {
+	return !e.HideBirthday && e.Birthday != nil && e.Birthday.Year() >= 1900
+}
+
+// 获取在职员工的生日、入职日期和隐私设置，deptNo 不为 0 时只获取该部门的在职成员
+func loadCalendarEmployees(deptNo int) ([]calendarEmployee, error) {
+	query := utils.DB.Table("Employees e").
+		Select("e.EmpNo, CONCAT(e.LastName, e.FirstName) as EmployeeName, e.HireDate, e.Birthday, "+
+			"COALESCE(p.HideBirthday, false) as HideBirthday, COALESCE(p.HideAnniversary, false) as HideAnniversary").
+		Joins("LEFT JOIN Calendar_Preferences p ON p.EmpNo = e.EmpNo").
+		Where("e.EmploymentStatus = ?", models.EmploymentActive)
+	if deptNo != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM Employee_Department ed WHERE ed.EmpNo = e.EmpNo AND ed.DeptNo = ? AND ed.EdStatus = 1)", deptNo)
+	}
+
+	var employees []calendarEmployee
+	if err := query.Order("e.EmpNo").Scan(&employees).Error; err != nil {
+		return nil, errors.New("获取员工生日和入职日期失败")
+	}
+	return employees, nil
+}
+
+// 获取员工的日历隐私设置，没有设置时返回默认值
+func GetCalendarPreference(empNo int, user *models.User) (*models.CalendarPreference, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "calendar", false); err != nil {
+		return nil, err
+	}
+	pref := &models.CalendarPreference{EmpNo: empNo}
+	if err := utils.DB.Where("EmpNo = ?", empNo).Limit(1).Find(pref).Error; err != nil {
+		return nil, errors.New("获取日历设置失败")
+	}
+	return pref, nil
+}
+
+// 更新员工的日历隐私设置（管理员或员工本人）
+func UpdateCalendarPreference(empNo int, req *models.CalendarPreferenceRequest, user *models.User) (*models.CalendarPreference, error) {
+	pref := &models.CalendarPreference{
+		EmpNo:           empNo,
+		HideBirthday:    req.HideBirthday,
+		HideAnniversary: req.HideAnniversary,
+	}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "calendar", true); err != nil {
+			return err
+		}
+		if err := tx.Save(pref).Error; err != nil {
+			return errors.New("保存日历设置失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pref, nil
+}
+
+// 获取日期范围内在职员工的生日和入职周年，默认从今天起 30 天，范围不能超过一年
+func GetCalendarEvents(from, to, deptNo string) ([]models.CalendarEvent, error) {
+	start := truncateToDay(time.Now())
+	if from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return nil, errors.New("无效的开始日期格式")
+		}
+		start = parsed
+	}
+	end := start.AddDate(0, 0, calendarDefaultDays-1)
+	if to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			return nil, errors.New("无效的结束日期格式")
+		}
+		end = parsed
+	}
+	if end.Before(start) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+	if start.AddDate(0, 0, calendarMaxDays).Before(end) {
+		return nil, errors.New("查询范围不能超过" + strconv.Itoa(calendarMaxDays) + "天")
+	}
+
+	dept := 0
+	if deptNo != "" && deptNo != "0" {
+		id, err := strconv.Atoi(deptNo)
+		if err != nil {
+			return nil, errors.New("无效的部门ID")
+		}
+		var d models.Department
+		if err := utils.DB.First(&d, id).Error; err != nil {
+			return nil, errors.New("部门不存在")
+		}
+		dept = id
+	}
+
+	employees, err := loadCalendarEmployees(dept)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []models.CalendarEvent{}
+	for _, e := range employees {
+		for year := start.Year(); year <= end.Year(); year++ {
+			if e.showBirthday() {
+				if day := anniversaryInYear(*e.Birthday, year, time.Local); !day.Before(start) && !day.After(end) {
+					events = append(events, models.CalendarEvent{
+						Type:         models.CalendarEventBirthday,
+						Date:         dateOnly(day),
+						EmpNo:        e.EmpNo,
+						EmployeeName: e.EmployeeName,
+					})
+				}
+			}
+			if years := year - e.HireDate.Year(); !e.HideAnniversary && years > 0 {
+				if day := anniversaryInYear(e.HireDate, year, time.Local); !day.Before(start) && !day.After(end) {
+					events = append(events, models.CalendarEvent{
+						Type:         models.CalendarEventAnniversary,
+						Date:         dateOnly(day),
+						EmpNo:        e.EmpNo,
+						EmployeeName: e.EmployeeName,
+						Years:        years,
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Date != events[j].Date {
+			return events[i].Date < events[j].Date
+		}
+		if events[i].Type != events[j].Type {
+			return events[i].Type == models.CalendarEventBirthday
+		}
+		return events[i].EmpNo < events[j].EmpNo
+	})
+	return events, nil
+}
+
+// 部门日历订阅令牌的当前版本
+func calendarFeedVersion(deptNo int) (int, error) {
+	var key models.CalendarFeedKey
+	err := utils.DB.Where("DeptNo = ?", deptNo).Limit(1).Find(&key).Error
+	return key.Version, err
+}
+
+func calendarFeedValue(deptNo, version int) string {
+	return "calendar-feed:" + strconv.Itoa(deptNo) + ":" + strconv.Itoa(version)
+}
+
+// 部门日历订阅链接中的令牌，订阅链接无法携带登录令牌，因此按部门和令牌版本签名
+func CalendarFeedToken(deptNo int) (string, error) {
+	var dept models.Department
+	if err := utils.DB.Select("DeptNo").First(&dept, deptNo).Error; err != nil {
+		return "", errors.New("部门不存在")
+	}
+	version, err := calendarFeedVersion(deptNo)
+	if err != nil {
+		return "", errors.New("获取订阅令牌失败")
+	}
+	return utils.SignValue(calendarFeedValue(deptNo, version)), nil
+}
+
+// 校验部门日历订阅链接中的令牌，重新生成后旧令牌不再有效
+func VerifyCalendarFeedToken(deptNo int, token string) bool {
+	if token == "" {
+		return false
+	}
+	version, err := calendarFeedVersion(deptNo)
+	return err == nil && utils.VerifySignedValue(calendarFeedValue(deptNo, version), token)
+}
+
+// 重新生成部门日历的订阅令牌，之前分发的订阅链接全部失效
+func RotateCalendarFeedToken(deptNo int) (string, error) {
+	var dept models.Department
+	if err := utils.DB.Select("DeptNo").First(&dept, deptNo).Error; err != nil {
+		return "", errors.New("部门不存在")
+	}
+	if err := utils.DB.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"Version":   gorm.Expr("Version + 1"),
+			"UpdatedAt": time.Now(),
+		}),
+	}).Create(&models.CalendarFeedKey{DeptNo: deptNo, Version: 1}).Error; err != nil {
+		return "", errors.New("重新生成订阅令牌失败")
+	}
+	return CalendarFeedToken(deptNo)
+}
+
+// 生成部门日历（iCalendar 格式），包含部门在职成员每年重复的生日和入职周年
+func WriteDepartmentCalendar(w io.Writer, deptNo int) error {
+	var dept models.Department
+	if err := utils.DB.First(&dept, deptNo).Error; err != nil {
+		return errors.New("部门不存在")
+	}
+	employees, err := loadCalendarEmployees(deptNo)
+	if err != nil {
+		return err
+	}
+
+	events := make([]utils.ICalEvent, 0, len(employees)*2)
+	for _, e := range employees {
+		if e.showBirthday() {
+			events = append(events, utils.ICalEvent{
+				UID:     "birthday-" + strconv.Itoa(e.EmpNo) + "@enterprise-info-system",
+				Summary: e.EmployeeName + " 生日",
+				Date:    *e.Birthday,
+				Yearly:  true,
+			})
+		}
+		if !e.HideAnniversary {
+			// 周年从入职满一年开始，入职当天不算
+			events = append(events, utils.ICalEvent{
+				UID:         "anniversary-" + strconv.Itoa(e.EmpNo) + "@enterprise-info-system",
+				Summary:     e.EmployeeName + " 入职周年",
+				Description: "入职日期：" + dateOnly(e.HireDate),
+				Date:        anniversaryInYear(e.HireDate, e.HireDate.Year()+1, time.Local),
+				Yearly:      true,
+			})
+		}
+	}
+	return utils.WriteICalendar(w, dept.DeptName+" 生日和入职周年", events)
+}
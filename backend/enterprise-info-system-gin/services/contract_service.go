@@ -0,0 +1,307 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 合同到期检查默认提前的天数
+const DefaultContractExpiryDays = 30
+
+var contractTypes = []string{
+	models.ContractFixedTerm,
+	models.ContractOpenEnded,
+	models.ContractInternship,
+	models.ContractPartTime,
+}
+
+// 获取员工的合同（按开始日期倒序）
+func GetEmployeeContracts(empNo int) ([]models.EmployeeContract, error) {
+	var emp models.Employee
+	if err := utils.DB.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+
+	contracts := []models.EmployeeContract{}
+	if err := utils.DB.Where("EmpNo = ?", empNo).
+		Order("StartDate DESC").
+		Find(&contracts).Error; err != nil {
+		return nil, errors.New("获取合同失败")
+	}
+	return contracts, nil
+}
+
+// 添加合同
+func CreateContract(empNo int, req *models.EmployeeContractRequest) (*models.EmployeeContract, error) {
+	contract := &models.EmployeeContract{EmpNo: empNo}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyContractRequest(tx, contract, req); err != nil {
+			return err
+		}
+		if err := tx.Create(contract).Error; err != nil {
+			return errors.New("添加合同失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contract, nil
+}
+
+// 更新合同
+func UpdateContract(id int, req *models.EmployeeContractRequest) (*models.EmployeeContract, error) {
+	var contract models.EmployeeContract
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&contract, id).Error; err != nil {
+			return errors.New("合同不存在")
+		}
+		if err := applyContractRequest(tx, &contract, req); err != nil {
+			return err
+		}
+		if err := tx.Save(&contract).Error; err != nil {
+			return errors.New("更新合同失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &contract, nil
+}
+
+// 删除合同
+func DeleteContract(id int) error {
+	result := utils.DB.Delete(&models.EmployeeContract{}, id)
+	if result.Error != nil {
+		return errors.New("删除合同失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("合同不存在")
+	}
+	return nil
+}
+
+// 校验合同请求并写入合同：
+//   - 固定期限、实习和非全日制合同必须有结束日期，无固定期限合同不能有结束日期
+//   - 开始日期不早于入职日期，试用期结束日期在合同期内
+//   - 同一员工的合同期限互不重叠
+//   - 合同附件必须是该员工的合同类附件
+func applyContractRequest(tx *gorm.DB, contract *models.EmployeeContract, req *models.EmployeeContractRequest) error {
+	validType := false
+	for _, t := range contractTypes {
+		if req.ContractType == t {
+			validType = true
+			break
+		}
+	}
+	if !validType {
+		return errors.New("无效的合同类型")
+	}
+
+	var emp models.Employee
+	if err := tx.First(&emp, contract.EmpNo).Error; err != nil {
+		return errors.New("员工不存在")
+	}
+
+	startDate := truncateToDay(emp.HireDate)
+	if req.StartDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return errors.New("无效的开始日期格式")
+		}
+		startDate = parsed
+	}
+	if dateOnly(startDate) < dateOnly(emp.HireDate) {
+		return errors.New("合同开始日期不能早于员工入职日期")
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" && req.EndDate != "null" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return errors.New("无效的结束日期格式")
+		}
+		if dateOnly(parsed) < dateOnly(startDate) {
+			return errors.New("合同结束日期不能早于开始日期")
+		}
+		endDate = &parsed
+	}
+	if req.ContractType == models.ContractOpenEnded && endDate != nil {
+		return errors.New("无固定期限合同不能设置结束日期")
+	}
+	if req.ContractType != models.ContractOpenEnded && endDate == nil {
+		return errors.New("请设置合同结束日期")
+	}
+
+	var probationEnd *time.Time
+	if req.ProbationEndDate != "" && req.ProbationEndDate != "null" {
+		parsed, err := time.Parse("2006-01-02", req.ProbationEndDate)
+		if err != nil {
+			return errors.New("无效的试用期结束日期格式")
+		}
+		if dateOnly(parsed) < dateOnly(startDate) || (endDate != nil && dateOnly(parsed) > dateOnly(*endDate)) {
+			return errors.New("试用期结束日期必须在合同期内")
+		}
+		probationEnd = &parsed
+	}
+
+	if req.AttachmentID != nil {
+		var attachment models.EmployeeAttachment
+		if err := tx.First(&attachment, *req.AttachmentID).Error; err != nil {
+			return errors.New("合同附件不存在")
+		}
+		if attachment.EmpNo != contract.EmpNo || attachment.Category != models.AttachmentContract {
+			return errors.New("合同附件必须是该员工的合同类附件")
+		}
+	}
+
+	var others []models.EmployeeContract
+	if err := tx.Where("EmpNo = ? AND ContractID != ?", contract.EmpNo, contract.ContractID).Find(&others).Error; err != nil {
+		return errors.New("获取员工合同失败")
+	}
+	start, end := periodBounds(startDate, endDate)
+	for _, other := range others {
+		otherStart, otherEnd := periodBounds(other.StartDate, other.EndDate)
+		if start <= otherEnd && otherStart <= end {
+			return errors.New("与已有合同（" + otherStart + " 开始）的期限重叠")
+		}
+	}
+
+	contract.ContractType = req.ContractType
+	contract.StartDate = startDate
+	contract.EndDate = endDate
+	contract.ProbationEndDate = probationEnd
+	contract.AttachmentID = req.AttachmentID
+	return nil
+}
+
+// 获取 days 天内到期的合同和结束的试用期，只包含在职员工；
+// 已有后续合同（续签）的合同不再提醒
+func GetExpiringContracts(days int) ([]models.ExpiringContract, error) {
+	if days < 0 {
+		return nil, errors.New("天数不能为负数")
+	}
+	today := truncateToDay(time.Now())
+	until := today.AddDate(0, 0, days)
+
+	var rows []struct {
+		models.EmployeeContract
+		EmployeeName string
+	}
+	if err := utils.DB.Table("Employee_Contracts c").
+		Select("c.*, CONCAT(e.LastName, e.FirstName) as EmployeeName").
+		Joins("INNER JOIN Employees e ON c.EmpNo = e.EmpNo").
+		Where("e.EmploymentStatus = ?", models.EmploymentActive).
+		Where("(c.EndDate BETWEEN ? AND ?) OR (c.ProbationEndDate BETWEEN ? AND ?)", today, until, today, until).
+		Where("NOT EXISTS (SELECT 1 FROM Employee_Contracts n WHERE n.EmpNo = c.EmpNo AND n.StartDate > c.StartDate)").
+		Scan(&rows).Error; err != nil {
+		return nil, errors.New("获取即将到期的合同失败")
+	}
+
+	result := []models.ExpiringContract{}
+	add := func(contract models.EmployeeContract, name, kind string, date *time.Time) {
+		if date == nil {
+			return
+		}
+		day := truncateToDay(*date)
+		if day.Before(today) || day.After(until) {
+			return
+		}
+		result = append(result, models.ExpiringContract{
+			EmployeeContract: contract,
+			EmployeeName:     name,
+			Kind:             kind,
+			ExpiryDate:       day,
+			DaysLeft:         daysBetween(today, day),
+		})
+	}
+	for _, r := range rows {
+		add(r.EmployeeContract, r.EmployeeName, "probation", r.ProbationEndDate)
+		add(r.EmployeeContract, r.EmployeeName, "contract", r.EndDate)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].ExpiryDate.Before(result[j].ExpiryDate)
+	})
+	return result, nil
+}
+
+func init() {
+	RegisterJobHandler("contract-expiry-check", func(job *JobContext) error {
+		var payload struct {
+			Days int `json:"days"`
+		}
+		if err := job.Bind(&payload); err != nil {
+			return errors.New("无效的任务参数")
+		}
+		if payload.Days <= 0 {
+			payload.Days = DefaultContractExpiryDays
+		}
+		created, err := NotifyExpiringContracts(payload.Days)
+		job.SetResult(map[string]interface{}{"notifications": created})
+		return err
+	})
+
+	// 每天早上 8 点检查即将到期的合同
+	RegisterDailyJob("contract-expiry-check", 8, func() interface{} {
+		return map[string]int{"days": DefaultContractExpiryDays}
+	})
+}
+
+// 为即将到期的合同和试用期发送通知：管理员和员工的直属上级各收到一条，
+// 同一合同的同一到期事项只通知一次，返回新建的通知数量
+func NotifyExpiringContracts(days int) (int, error) {
+	expiring, err := GetExpiringContracts(days)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, c := range expiring {
+		title := fmt.Sprintf("%s的劳动合同将于 %s 到期", c.EmployeeName, dateOnly(c.ExpiryDate))
+		if c.Kind == "probation" {
+			title = fmt.Sprintf("%s的试用期将于 %s 结束", c.EmployeeName, dateOnly(c.ExpiryDate))
+		}
+		content := fmt.Sprintf("员工编号 %d，合同编号 %d，剩余 %d 天", c.EmpNo, c.ContractID, c.DaysLeft)
+		empNo := c.EmpNo
+		keyPrefix := fmt.Sprintf("contract-%s:%d:%s", c.Kind, c.ContractID, dateOnly(c.ExpiryDate))
+
+		recipients := []models.Notification{{Role: "Admin"}}
+		var emp models.Employee
+		if err := utils.DB.Select("EmpNo", "ManagerEmpNo").First(&emp, c.EmpNo).Error; err == nil && emp.ManagerEmpNo != nil {
+			var users []models.User
+			utils.DB.Where("EmpNo = ? AND Disabled = ?", *emp.ManagerEmpNo, false).Find(&users)
+			for _, u := range users {
+				userID := u.UserID
+				recipients = append(recipients, models.Notification{UserID: &userID})
+			}
+		}
+
+		for _, n := range recipients {
+			key := keyPrefix + ":role:" + n.Role
+			if n.UserID != nil {
+				key = fmt.Sprintf("%s:user:%d", keyPrefix, *n.UserID)
+			}
+			n.EmpNo = &empNo
+			n.Category = "contract-expiry"
+			n.Title = title
+			n.Content = content
+			n.DedupKey = &key
+			ok, err := createNotification(&n)
+			if err != nil {
+				return created, errors.New("创建通知失败")
+			}
+			if ok {
+				created++
+			}
+		}
+	}
+	return created, nil
+}
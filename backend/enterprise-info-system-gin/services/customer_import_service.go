@@ -0,0 +1,493 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// 支持的导入格式
+const (
+	CustomerImportCSV   = "csv"
+	CustomerImportXLSX  = "xlsx"
+	CustomerImportVCard = "vcard"
+)
+
+// 导入文件中可识别的表头（统一转为小写并去掉空格后比较）
+var customerImportHeaders = map[string][]string{
+	"name":      {"客户名称", "客户姓名", "姓名", "名称", "客户", "name", "customername", "fullname", "联系人"},
+	"company":   {"公司", "公司名称", "单位", "company", "organization", "org"},
+	"telephone": {"电话", "联系电话", "手机", "手机号", "电话号码", "telephone", "phone", "tel", "mobile"},
+	"address":   {"地址", "联系地址", "address"},
+	"sex":       {"性别", "sex", "gender"},
+	"age":       {"年龄", "age"},
+}
+
+// 根据文件名推断导入格式
+func DetectCustomerImportFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return CustomerImportCSV, nil
+	case ".xlsx":
+		return CustomerImportXLSX, nil
+	case ".vcf", ".vcard":
+		return CustomerImportVCard, nil
+	}
+	return "", errors.New("不支持的文件格式，仅支持 csv、xlsx 和 vcf")
+}
+
+// 待导入的一行客户数据
+type customerImportRow struct {
+	row      int
+	customer models.Customer
+	err      error
+}
+
+// 后台导入任务的参数，File 为已保存的上传文件路径
+type customerImportJobPayload struct {
+	File   string `json:"file"`
+	Format string `json:"format"`
+}
+
+func init() {
+	RegisterJobHandler("customer-import", func(job *JobContext) error {
+		var payload customerImportJobPayload
+		if err := job.Bind(&payload); err != nil {
+			return errors.New("无效的任务参数")
+		}
+		data, err := os.ReadFile(payload.File)
+		if err != nil {
+			return errors.New("导入文件不存在")
+		}
+
+		result, err := importCustomers(job, data, payload.Format, job.SetProgressCount)
+		if result != nil {
+			job.SetResult(result)
+		}
+		return err
+	})
+
+	// 任务结束（包括执行前被取消）后删除上传文件
+	RegisterJobCleanup("customer-import", func(job *models.Job) {
+		var payload customerImportJobPayload
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err == nil && payload.File != "" {
+			os.Remove(payload.File)
+		}
+	})
+}
+
+// 创建后台导入任务，适用于较大的导入文件
+func EnqueueCustomerImportJob(data []byte, format string, userID *int) (*models.Job, error) {
+	if format != CustomerImportCSV && format != CustomerImportXLSX && format != CustomerImportVCard {
+		return nil, errors.New("不支持的导入格式")
+	}
+	path, err := SaveJobUpload(data, "."+format)
+	if err != nil {
+		return nil, err
+	}
+	job, err := EnqueueJob("customer-import", customerImportJobPayload{File: path, Format: format}, userID)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return job, nil
+}
+
+// 批量导入客户
+func ImportCustomers(data []byte, format string) (*models.CustomerImportResult, error) {
+	return importCustomers(context.Background(), data, format, nil)
+}
+
+// 导入客户，progress 用于汇报已处理的行数，取消 ctx 会停止处理剩余的行
+func importCustomers(ctx context.Context, data []byte, format string,
+	progress func(done, total int)) (*models.CustomerImportResult, error) {
+	var rows []customerImportRow
+	var err error
+
+	switch format {
+	case CustomerImportCSV:
+		rows, err = parseCustomerCSV(data)
+	case CustomerImportXLSX:
+		rows, err = parseCustomerXLSX(data)
+	case CustomerImportVCard:
+		rows, err = parseCustomerVCard(data)
+	default:
+		return nil, errors.New("不支持的导入格式")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("导入文件中没有客户数据")
+	}
+
+	// 载入已有客户用于重复检测
+	var existing []models.Customer
+	if err := utils.DB.Select("CustomerID", "CustomerName", "Company", "Telephone").
+		Find(&existing).Error; err != nil {
+		return nil, errors.New("获取客户列表失败")
+	}
+	byPhone := make(map[string]int)
+	byNameCompany := make(map[string]int)
+	for _, c := range existing {
+		if phone := normalizeTelephone(c.Telephone); phone != "" {
+			byPhone[phone] = c.CustomerID
+		}
+		byNameCompany[customerNameCompanyKey(c.CustomerName, c.Company)] = c.CustomerID
+	}
+
+	result := &models.CustomerImportResult{Total: len(rows)}
+	for i, r := range rows {
+		if ctx.Err() != nil {
+			return result, errors.New("导入已取消")
+		}
+		if progress != nil {
+			progress(i, len(rows))
+		}
+
+		rowResult := models.CustomerImportRowResult{
+			Row:          r.row,
+			CustomerName: r.customer.CustomerName,
+		}
+
+		if r.err == nil {
+			r.err = validateImportedCustomer(&r.customer)
+		}
+		if r.err != nil {
+			rowResult.Status = "failed"
+			rowResult.Reason = r.err.Error()
+			result.Failed++
+			result.Rows = append(result.Rows, rowResult)
+			continue
+		}
+
+		phone := r.customer.Telephone
+		key := customerNameCompanyKey(r.customer.CustomerName, r.customer.Company)
+		if id, ok := byPhone[phone]; ok && phone != "" {
+			rowResult.Status = "skipped"
+			rowResult.CustomerID = id
+			rowResult.Reason = "电话号码已存在"
+			result.Skipped++
+			result.Rows = append(result.Rows, rowResult)
+			continue
+		}
+		if id, ok := byNameCompany[key]; ok {
+			rowResult.Status = "skipped"
+			rowResult.CustomerID = id
+			rowResult.Reason = "同名同公司的客户已存在"
+			result.Skipped++
+			result.Rows = append(result.Rows, rowResult)
+			continue
+		}
+
+		customer := r.customer
+		if err := utils.DB.Create(&customer).Error; err != nil {
+			rowResult.Status = "failed"
+			rowResult.Reason = "创建客户失败"
+			result.Failed++
+			result.Rows = append(result.Rows, rowResult)
+			continue
+		}
+
+		// 同一文件中后续的重复行也需要被跳过
+		if phone != "" {
+			byPhone[phone] = customer.CustomerID
+		}
+		byNameCompany[key] = customer.CustomerID
+
+		rowResult.Status = "created"
+		rowResult.CustomerID = customer.CustomerID
+		result.Created++
+		result.Rows = append(result.Rows, rowResult)
+	}
+
+	return result, nil
+}
+
+// 校验并规范化导入的客户字段，长度限制与 models.Customer 的列定义一致
+func validateImportedCustomer(c *models.Customer) error {
+	c.CustomerName = strings.TrimSpace(c.CustomerName)
+	c.Company = strings.TrimSpace(c.Company)
+	c.Address = strings.TrimSpace(c.Address)
+	c.Sex = strings.TrimSpace(c.Sex)
+	c.Telephone = normalizeTelephone(c.Telephone)
+
+	if c.CustomerName == "" {
+		return errors.New("客户名称不能为空")
+	}
+	if utf8.RuneCountInString(c.CustomerName) > 20 {
+		return errors.New("客户名称不能超过20个字符")
+	}
+	if utf8.RuneCountInString(c.Company) > 50 {
+		return errors.New("公司名称不能超过50个字符")
+	}
+	if utf8.RuneCountInString(c.Telephone) > 20 {
+		return errors.New("电话号码不能超过20个字符")
+	}
+	if utf8.RuneCountInString(c.Address) > 200 {
+		return errors.New("地址不能超过200个字符")
+	}
+	if c.Sex != "" {
+		switch strings.ToLower(c.Sex) {
+		case "男", "m", "male":
+			c.Sex = "男"
+		case "女", "f", "female":
+			c.Sex = "女"
+		default:
+			return errors.New("无效的性别: " + c.Sex)
+		}
+	}
+	if c.Age < 0 || c.Age > 150 {
+		return errors.New("无效的年龄")
+	}
+	return nil
+}
+
+// 去掉电话号码中的空格、横线和括号，保留开头的 "+"
+func normalizeTelephone(phone string) string {
+	var sb strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		switch {
+		case r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r == '+' && i == 0:
+			sb.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			// 含有分机号等其他字符时保留原样
+			return strings.TrimSpace(phone)
+		}
+	}
+	return sb.String()
+}
+
+func customerNameCompanyKey(name, company string) string {
+	return strings.ToLower(strings.TrimSpace(name)) + "\x00" + strings.ToLower(strings.TrimSpace(company))
+}
+
+// 解析 CSV，兼容 Excel 导出的 UTF-8 BOM 和 GBK 编码
+func parseCustomerCSV(data []byte) ([]customerImportRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		decoded, err := simplifiedchinese.GB18030.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, errors.New("无法识别 CSV 文件编码")
+		}
+		data = decoded
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	if firstLine, _, _ := bytes.Cut(data, []byte("\n")); bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("解析 CSV 文件失败: " + err.Error())
+		}
+		// csv.Reader 会跳过空行，这里补齐以保证行号与文件一致
+		line, _ := reader.FieldPos(0)
+		for len(records) < line-1 {
+			records = append(records, nil)
+		}
+		records = append(records, record)
+	}
+	return customerRowsFromTable(records)
+}
+
+func parseCustomerXLSX(data []byte) ([]customerImportRow, error) {
+	records, err := utils.ReadXLSXRows(data)
+	if err != nil {
+		return nil, err
+	}
+	return customerRowsFromTable(records)
+}
+
+// 将表格数据映射为客户，第一个非空行作为表头
+func customerRowsFromTable(records [][]string) ([]customerImportRow, error) {
+	headerIndex := -1
+	for i, record := range records {
+		if !isBlankRecord(record) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, h := range records[headerIndex] {
+		h = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(h), " ", ""))
+		for field, aliases := range customerImportHeaders {
+			if _, ok := columns[field]; ok {
+				continue
+			}
+			for _, alias := range aliases {
+				if h == strings.ToLower(alias) {
+					columns[field] = i
+					break
+				}
+			}
+		}
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("导入文件缺少客户名称列")
+	}
+
+	cell := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []customerImportRow
+	for i := headerIndex + 1; i < len(records); i++ {
+		record := records[i]
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := customerImportRow{
+			row: i + 1,
+			customer: models.Customer{
+				CustomerName: cell(record, "name"),
+				Company:      cell(record, "company"),
+				Telephone:    cell(record, "telephone"),
+				Address:      cell(record, "address"),
+				Sex:          cell(record, "sex"),
+			},
+		}
+		if age := cell(record, "age"); age != "" {
+			n, err := strconv.Atoi(age)
+			if err != nil {
+				row.err = errors.New("无效的年龄: " + age)
+			}
+			row.customer.Age = n
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// 解析 vCard 3.0/4.0 联系人，行号为联系人在文件中的序号
+func parseCustomerVCard(data []byte) ([]customerImportRow, error) {
+	cards, err := utils.ParseVCards(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]customerImportRow, 0, len(cards))
+	for i, card := range cards {
+		customer := models.Customer{
+			CustomerName: vcardDisplayName(card),
+			Telephone:    vcardPreferredTelephone(card),
+		}
+		if org, ok := card.Get("ORG"); ok {
+			customer.Company = org.Components()[0]
+		}
+		if adr, ok := card.Get("ADR"); ok {
+			customer.Address = vcardAddress(adr.Components())
+		}
+		if gender, ok := card.Get("GENDER"); ok {
+			// vCard 4.0: GENDER:M / GENDER:F
+			customer.Sex = gender.Components()[0]
+		}
+		rows = append(rows, customerImportRow{row: i + 1, customer: customer})
+	}
+	return rows, nil
+}
+
+// 优先使用 FN，缺失时由 N（姓;名;...）拼出中文习惯的姓名
+func vcardDisplayName(card utils.VCard) string {
+	if fn := strings.TrimSpace(card.Text("FN")); fn != "" {
+		return fn
+	}
+	n, ok := card.Get("N")
+	if !ok {
+		return ""
+	}
+	parts := n.Components()
+	name := parts[0]
+	if len(parts) > 1 {
+		name += parts[1]
+	}
+	return strings.TrimSpace(name)
+}
+
+// 优先使用标记为首选或手机的号码
+func vcardPreferredTelephone(card utils.VCard) string {
+	tels := card.GetAll("TEL")
+	if len(tels) == 0 {
+		return ""
+	}
+	for _, t := range tels {
+		if t.HasType("pref") {
+			return vcardTelValue(t)
+		}
+	}
+	for _, t := range tels {
+		if t.HasType("cell") {
+			return vcardTelValue(t)
+		}
+	}
+	return vcardTelValue(tels[0])
+}
+
+// vCard 4.0 中电话可能写成 tel:+86-138... 的 URI 形式
+func vcardTelValue(p utils.VCardProperty) string {
+	v := strings.TrimSpace(p.Value)
+	if strings.HasPrefix(strings.ToLower(v), "tel:") {
+		v = v[4:]
+	}
+	if semi := strings.Index(v, ";"); semi >= 0 {
+		v = v[:semi]
+	}
+	return v
+}
+
+// ADR 的组成部分依次为：邮政信箱;扩展地址;街道;城市;省份;邮编;国家
+// 按照国家、省份、城市、街道、扩展地址的顺序拼接
+func vcardAddress(parts []string) string {
+	for len(parts) < 7 {
+		parts = append(parts, "")
+	}
+	var segments []string
+	for _, i := range []int{6, 4, 3, 2, 1, 0} {
+		if s := strings.TrimSpace(parts[i]); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return strings.Join(segments, " ")
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 首页汇总的参数
+const (
+	dashboardCacheTTL    = 30 * time.Second
+	dashboardRecentDays  = 30 // 最近入职的天数
+	dashboardUpcoming    = 30 // 即将到来的生日和周年的天数
+	dashboardListLimit   = 10
+	dashboardTopDeptSize = 5
+)
+
+// 首页汇总的缓存，过期前直接返回上次的结果
+var dashboardCache struct {
+	sync.Mutex
+	summary *models.DashboardSummary
+	expires time.Time
+}
+
+// 获取首页汇总数据，各项数据并发查询，结果缓存 30 秒
+func GetDashboardSummary() (*models.DashboardSummary, error) {
+	dashboardCache.Lock()
+	defer dashboardCache.Unlock()
+	if dashboardCache.summary != nil && time.Now().Before(dashboardCache.expires) {
+		return dashboardCache.summary, nil
+	}
+
+	summary := &models.DashboardSummary{GeneratedAt: time.Now()}
+	tasks := []func() error{
+		func() error {
+			return loadDashboardTotals(&summary.Totals)
+		},
+		func() (err error) {
+			summary.RecentHires, err = getRecentHires()
+			return err
+		},
+		func() (err error) {
+			summary.UpcomingBirthdays, summary.WorkAnniversaries, err = getUpcomingEmployeeEvents()
+			return err
+		},
+		func() (err error) {
+			summary.TopDepartments, err = getTopDepartments()
+			return err
+		},
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task func() error) {
+			defer wg.Done()
+			errs[i] = task()
+		}(i, task)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dashboardCache.summary = summary
+	dashboardCache.expires = time.Now().Add(dashboardCacheTTL)
+	return summary, nil
+}
+
+// 统计各类数据的总数
+func loadDashboardTotals(totals *models.DashboardTotals) error {
+	if err := utils.DB.Model(&models.Customer{}).Count(&totals.Customers).Error; err != nil {
+		return errors.New("统计客户数量失败")
+	}
+	if err := utils.DB.Model(&models.Employee{}).
+		Where("EmploymentStatus = ?", models.EmploymentActive).
+		Count(&totals.Employees).Error; err != nil {
+		return errors.New("统计员工数量失败")
+	}
+	if err := utils.DB.Model(&models.Department{}).Count(&totals.Departments).Error; err != nil {
+		return errors.New("统计部门数量失败")
+	}
+	if err := utils.DB.Model(&models.EmployeeDepartment{}).
+		Where("EdStatus = 1").
+		Count(&totals.ActiveRelations).Error; err != nil {
+		return errors.New("统计员工部门关系失败")
+	}
+	return nil
+}
+
+// 获取最近入职的在职员工
+func getRecentHires() ([]models.DashboardEmployee, error) {
+	since := truncateToDay(time.Now()).AddDate(0, 0, -dashboardRecentDays)
+	hires := []models.DashboardEmployee{}
+	if err := utils.DB.Model(&models.Employee{}).
+		Select("EmpNo, CONCAT(LastName, FirstName) as EmployeeName, HireDate").
+		Where("HireDate >= ? AND EmploymentStatus = ?", since, models.EmploymentActive).
+		Order("HireDate DESC, EmpNo DESC").
+		Limit(dashboardListLimit).
+		Scan(&hires).Error; err != nil {
+		return nil, errors.New("获取最近入职员工失败")
+	}
+	return hires, nil
+}
+
+// 计算 date 在 from 当天或之后的下一个周年日，2 月 29 日在平年按 2 月 28 日计算
+func nextAnniversary(date, from time.Time) time.Time {
+	next := anniversaryInYear(date, from.Year(), from.Location())
+	if next.Before(from) {
+		next = anniversaryInYear(date, from.Year()+1, from.Location())
+	}
+	return next
+}
+
+// 计算 date 在指定年份的周年日，2 月 29 日在平年按 2 月 28 日计算
+func anniversaryInYear(date time.Time, year int, loc *time.Location) time.Time {
+	day := date.Day()
+	if date.Month() == time.February && day == 29 && !isLeapYear(year) {
+		day = 28
+	}
+	return time.Date(year, date.Month(), day, 0, 0, 0, 0, loc)
+}
+
+// from 到 to 相差的天数，按日历日期计算，不受夏令时切换的影响
+func daysBetween(from, to time.Time) int {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+// 获取在职员工即将到来的生日和入职周年（不含入职当天），不显示员工设置为隐藏的日期
+func getUpcomingEmployeeEvents() ([]models.DashboardEvent, []models.DashboardEvent, error) {
+	employees, err := loadCalendarEmployees(0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	today := truncateToDay(time.Now())
+	until := today.AddDate(0, 0, dashboardUpcoming)
+	birthdays := []models.DashboardEvent{}
+	anniversaries := []models.DashboardEvent{}
+	for _, e := range employees {
+		if e.showBirthday() {
+			if next := nextAnniversary(*e.Birthday, today); next.Before(until) {
+				birthdays = append(birthdays, models.DashboardEvent{
+					EmpNo:        e.EmpNo,
+					EmployeeName: e.EmployeeName,
+					Date:         dateOnly(next),
+					DaysUntil:    daysBetween(today, next),
+				})
+			}
+		}
+
+		if e.HideAnniversary {
+			continue
+		}
+		next := nextAnniversary(e.HireDate, today)
+		if years := next.Year() - e.HireDate.Year(); years > 0 && next.Before(until) {
+			anniversaries = append(anniversaries, models.DashboardEvent{
+				EmpNo:        e.EmpNo,
+				EmployeeName: e.EmployeeName,
+				Date:         dateOnly(next),
+				DaysUntil:    daysBetween(today, next),
+				Years:        years,
+			})
+		}
+	}
+
+	for _, events := range [][]models.DashboardEvent{birthdays, anniversaries} {
+		sort.SliceStable(events, func(i, j int) bool {
+			if events[i].DaysUntil != events[j].DaysUntil {
+				return events[i].DaysUntil < events[j].DaysUntil
+			}
+			return events[i].EmpNo < events[j].EmpNo
+		})
+	}
+	if len(birthdays) > dashboardListLimit {
+		birthdays = birthdays[:dashboardListLimit]
+	}
+	if len(anniversaries) > dashboardListLimit {
+		anniversaries = anniversaries[:dashboardListLimit]
+	}
+	return birthdays, anniversaries, nil
+}
+
+// 获取在职人数最多的部门
+func getTopDepartments() ([]models.DepartmentStats, error) {
+	departments := []models.DepartmentStats{}
+	if err := utils.DB.Model(&models.Department{}).
+		Select("DeptNo, DeptName, DeptPeopleCount as EmployeeCount").
+		Order("DeptPeopleCount DESC, DeptNo").
+		Limit(dashboardTopDeptSize).
+		Scan(&departments).Error; err != nil {
+		return nil, errors.New("获取部门人数排行失败")
+	}
+	return departments, nil
+}
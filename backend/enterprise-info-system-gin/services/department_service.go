@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"enterprise-info-system-gin/models"
 	"enterprise-info-system-gin/utils"
 	"errors"
@@ -19,51 +20,75 @@ func GetAllDepartments() ([]models.Department, error) {
 
 // 添加部门
 func CreateDepartment(department *models.Department) (*models.Department, error) {
+	if err := createDepartment(utils.DB, department); err != nil {
+		return nil, err
+	}
+	return department, nil
+}
+
+// 在 tx 中添加部门
+func createDepartment(tx *gorm.DB, department *models.Department) error {
 	if department.DeptName == "" {
-		return nil, errors.New("部门名称不能为空")
+		return errors.New("部门名称不能为空")
 	}
 
 	// 检查部门名称是否已存在
 	var existingDept models.Department
-	if err := utils.DB.Where("DeptName = ?", department.DeptName).First(&existingDept).Error; err == nil {
-		return nil, errors.New("部门名称已存在")
+	if err := tx.Where("DeptName = ?", department.DeptName).First(&existingDept).Error; err == nil {
+		return errors.New("部门名称已存在")
+	}
+
+	// 检查上级部门是否存在
+	if department.ParentDeptNo != nil {
+		var parent models.Department
+		if err := tx.First(&parent, *department.ParentDeptNo).Error; err != nil {
+			return errors.New("上级部门不存在")
+		}
 	}
 
 	// 添加日志
 	log.Printf("Creating department: %+v\n", department)
 
-	result := utils.DB.Create(department)
+	result := tx.Create(department)
 	if result.Error != nil {
 		// 添加错误日志
 		log.Printf("Error creating department: %v\n", result.Error)
-		return nil, errors.New("创建部门失败")
+		return errors.New("创建部门失败")
 	}
 
-	return department, nil
+	return nil
 }
 
 // 更新部门
 func UpdateDepartment(department *models.Department) error {
+	return updateDepartment(utils.DB, department)
+}
+
+// 在 tx 中更新部门
+func updateDepartment(tx *gorm.DB, department *models.Department) error {
 	if department.DeptNo == 0 {
 		return errors.New("部门ID不能为空")
 	}
 
 	// 检查部门是否存在
 	var existingDept models.Department
-	if err := utils.DB.First(&existingDept, department.DeptNo).Error; err != nil {
+	if err := tx.First(&existingDept, department.DeptNo).Error; err != nil {
 		return errors.New("部门不存在")
 	}
 
+	// 上级部门只能通过移动部门修改
+	department.ParentDeptNo = existingDept.ParentDeptNo
+
 	// 检查新的部门名称是否与其他部门重复
 	if department.DeptName != existingDept.DeptName {
 		var duplicateDept models.Department
-		if err := utils.DB.Where("DeptName = ? AND DeptNo != ?", department.DeptName, department.DeptNo).
+		if err := tx.Where("DeptName = ? AND DeptNo != ?", department.DeptName, department.DeptNo).
 			First(&duplicateDept).Error; err == nil {
 			return errors.New("部门名称已存在")
 		}
 	}
 
-	result := utils.DB.Save(department)
+	result := tx.Save(department)
 	if result.Error != nil {
 		return errors.New("更新部门失败")
 	}
@@ -71,8 +96,22 @@ func UpdateDepartment(department *models.Department) error {
 	return nil
 }
 
-// 删除部门
-func DeleteDepartment(id int) error {
+// 删除部门时对子部门的处理方式
+const (
+	DeleteChildrenRefuse   = "refuse"
+	DeleteChildrenReparent = "reparent"
+)
+
+// 删除部门，childrenMode 为 reparent 时子部门改挂到被删除部门的上级，
+// 否则存在子部门时拒绝删除
+func DeleteDepartment(id int, childrenMode string) error {
+	if childrenMode == "" {
+		childrenMode = DeleteChildrenRefuse
+	}
+	if childrenMode != DeleteChildrenRefuse && childrenMode != DeleteChildrenReparent {
+		return errors.New("无效的子部门处理方式")
+	}
+
 	return utils.DB.Transaction(func(tx *gorm.DB) error {
 		// 首先获取部门信息
 		var dept models.Department
@@ -80,11 +119,49 @@ func DeleteDepartment(id int) error {
 			return errors.New("部门不存在")
 		}
 
+		// 处理子部门
+		var childCount int64
+		if err := tx.Model(&models.Department{}).Where("ParentDeptNo = ?", id).Count(&childCount).Error; err != nil {
+			return errors.New("查询子部门失败")
+		}
+		if childCount > 0 {
+			if childrenMode == DeleteChildrenRefuse {
+				return errors.New("该部门存在子部门，请先移动或删除子部门")
+			}
+			if err := tx.Model(&models.Department{}).Where("ParentDeptNo = ?", id).
+				Update("ParentDeptNo", dept.ParentDeptNo).Error; err != nil {
+				return errors.New("调整子部门失败")
+			}
+		}
+
 		// 删除部门的所有员工关系
 		if err := tx.Where("DeptNo = ?", id).Delete(&models.EmployeeDepartment{}).Error; err != nil {
 			return errors.New("删除部门关系失败")
 		}
 
+		// 删除部门经理任命记录
+		if err := tx.Where("DeptNo = ?", id).Delete(&models.DepartmentManager{}).Error; err != nil {
+			return errors.New("删除部门经理记录失败")
+		}
+
+		// 删除该部门的绩效评估和仅针对该部门的考核周期
+		if err := tx.Where("DeptNo = ?", id).Delete(&models.PerformanceReview{}).Error; err != nil {
+			return errors.New("删除部门绩效评估失败")
+		}
+		if err := tx.Where("DeptNo = ?", id).Delete(&models.ReviewCycle{}).Error; err != nil {
+			return errors.New("删除部门考核周期失败")
+		}
+
+		// 删除部门的人数快照
+		if err := tx.Where("DeptNo = ?", id).Delete(&models.HeadcountSnapshot{}).Error; err != nil {
+			return errors.New("删除部门人数快照失败")
+		}
+
+		// 删除仅适用于该部门的职位
+		if err := tx.Where("DeptNo = ?", id).Delete(&models.Position{}).Error; err != nil {
+			return errors.New("删除部门职位失败")
+		}
+
 		// 最后删除部门
 		if err := tx.Delete(&dept).Error; err != nil {
 			return errors.New("删除部门失败")
@@ -141,10 +218,12 @@ func GetGroupedEmployeeDepartments() ([]models.GroupedEmployeeDepartment, error)
 		var relations []models.DepartmentRelation
 		err := utils.DB.Raw(`
 			SELECT ed.EdID, ed.DeptNo, d.DeptName as DepartmentName, 
-				   ed.EdEntryDate, ed.EdLeaveDate, ed.EdStatus
+				   ed.EdEntryDate, ed.EdLeaveDate, ed.EdStatus,
+				   ed.PositionID, COALESCE(p.Title, '') as PositionTitle
 			FROM Employee_Department ed
 			FORCE INDEX (idx_emp_dept)
 			INNER JOIN Departments d ON ed.DeptNo = d.DeptNo
+			LEFT JOIN Positions p ON ed.PositionID = p.PositionID
 			WHERE ed.EmpNo = ?
 			ORDER BY ed.EdEntryDate DESC
 		`, grouped.EmpNo).Scan(&relations).Error
@@ -162,99 +241,151 @@ func GetGroupedEmployeeDepartments() ([]models.GroupedEmployeeDepartment, error)
 // 添加员工部门关系
 func AddEmployeeDepartment(req *models.EmployeeDepartmentRequest) error {
 	return utils.DB.Transaction(func(tx *gorm.DB) error {
-		// 验证员工和部门是否存在
-		var emp models.Employee
-		if err := tx.First(&emp, req.EmpNo).Error; err != nil {
-			return errors.New("员工不存在")
-		}
+		return addEmployeeDepartment(tx, req)
+	})
+}
 
-		var dept models.Department
-		if err := tx.First(&dept, req.DeptNo).Error; err != nil {
-			return errors.New("部门不存在")
-		}
+// 在事务中添加员工部门关系
+func addEmployeeDepartment(tx *gorm.DB, req *models.EmployeeDepartmentRequest) error {
+	verr := &ValidationError{}
 
-		// 检查是否已存在有效的部门关系
-		var existingEd models.EmployeeDepartment
-		if err := tx.Where("EmpNo = ? AND DeptNo = ? AND EdStatus = 1", req.EmpNo, req.DeptNo).
-			First(&existingEd).Error; err == nil {
-			return errors.New("该员工已在此部门中")
-		}
+	// 验证员工和部门是否存在
+	var emp models.Employee
+	if err := tx.First(&emp, req.EmpNo).Error; err != nil {
+		verr.Add("empNo", "员工不存在")
+	}
 
-		// 解析日期
-		entryDate, err := time.Parse("2006-01-02", req.EdEntryDate)
-		if err != nil {
-			return errors.New("无效的入职日期格式")
-		}
+	var dept models.Department
+	if err := tx.First(&dept, req.DeptNo).Error; err != nil {
+		verr.Add("deptNo", "部门不存在")
+	}
+	if verr.HasErrors() {
+		return verr
+	}
 
-		ed := models.EmployeeDepartment{
-			EmpNo:       req.EmpNo,
-			DeptNo:      req.DeptNo,
-			EdEntryDate: entryDate,
-			EdStatus:    req.EdStatus,
-		}
+	// 检查是否已存在有效的部门关系
+	var existingEd models.EmployeeDepartment
+	if err := tx.Where("EmpNo = ? AND DeptNo = ? AND EdStatus = 1", req.EmpNo, req.DeptNo).
+		First(&existingEd).Error; err == nil {
+		verr.Add("deptNo", "该员工已在此部门中")
+		return verr
+	}
 
-		// 如果有离职日期，则解析
-		if req.EdLeaveDate != "" && req.EdLeaveDate != "null" {
-			leaveDate, err := time.Parse("2006-01-02", req.EdLeaveDate)
-			if err != nil {
-				return errors.New("无效的离职日期格式")
-			}
-			ed.EdLeaveDate = &leaveDate
-		}
+	// 解析日期
+	entryDate, leaveDate := parseEmployeeDepartmentDates(req, verr)
+	if verr.HasErrors() {
+		return verr
+	}
 
-		// 创建关系（触发器会自动更新部门人数）
-		return tx.Create(&ed).Error
-	})
+	ed := models.EmployeeDepartment{
+		EmpNo:       req.EmpNo,
+		DeptNo:      req.DeptNo,
+		EdEntryDate: entryDate,
+		EdLeaveDate: leaveDate,
+		EdStatus:    req.EdStatus,
+		PositionID:  req.PositionID,
+	}
+
+	if err := validateEmployeeDepartment(tx, &ed, &emp, verr); err != nil {
+		return err
+	}
+	if verr.HasErrors() {
+		return verr
+	}
+
+	// 创建关系（触发器会自动更新部门人数）
+	if err := tx.Create(&ed).Error; err != nil {
+		return err
+	}
+
+	// 按部门模板生成入职任务
+	if ed.EdStatus == 1 {
+		if err := createOnboardingTasks(tx, &emp, &ed.DeptNo); err != nil {
+			return errors.New("生成入职任务失败")
+		}
+	}
+	return nil
 }
 
 // 更新员工部门关系
 func UpdateEmployeeDepartment(edID int, req *models.EmployeeDepartmentRequest) error {
 	return utils.DB.Transaction(func(tx *gorm.DB) error {
-		// 获取原有的部门关系记录
-		var ed models.EmployeeDepartment
-		if err := tx.First(&ed, edID).Error; err != nil {
-			return errors.New("部门关系不存在")
-		}
+		return updateEmployeeDepartment(tx, edID, req)
+	})
+}
 
-		// 如果部门发生变化，需要检查新部门是否存在
-		if ed.DeptNo != req.DeptNo {
-			var dept models.Department
-			if err := tx.First(&dept, req.DeptNo).Error; err != nil {
-				return errors.New("新部门不存在")
-			}
+// 在事务中更新员工部门关系
+func updateEmployeeDepartment(tx *gorm.DB, edID int, req *models.EmployeeDepartmentRequest) error {
+	verr := &ValidationError{}
 
-			// 检查是否已存在于新部门
-			var existingEd models.EmployeeDepartment
-			if err := tx.Where("EmpNo = ? AND DeptNo = ? AND EdStatus = 1 AND EdID != ?", 
-				req.EmpNo, req.DeptNo, edID).First(&existingEd).Error; err == nil {
-				return errors.New("该员工已在目标部门中")
-			}
+	// 获取原有的部门关系记录
+	var ed models.EmployeeDepartment
+	if err := tx.First(&ed, edID).Error; err != nil {
+		return errors.New("部门关系不存在")
+	}
+
+	var emp models.Employee
+	if err := tx.First(&emp, ed.EmpNo).Error; err != nil {
+		return errors.New("员工不存在")
+	}
+
+	// 如果部门发生变化，需要检查新部门是否存在
+	if ed.DeptNo != req.DeptNo {
+		var dept models.Department
+		if err := tx.First(&dept, req.DeptNo).Error; err != nil {
+			verr.Add("deptNo", "新部门不存在")
+			return verr
 		}
 
-		// 解析日期
-		entryDate, err := time.Parse("2006-01-02", req.EdEntryDate)
-		if err != nil {
-			return errors.New("无效的入职日期格式")
+		// 检查是否已存在于新部门
+		var existingEd models.EmployeeDepartment
+		if err := tx.Where("EmpNo = ? AND DeptNo = ? AND EdStatus = 1 AND EdID != ?",
+			ed.EmpNo, req.DeptNo, edID).First(&existingEd).Error; err == nil {
+			verr.Add("deptNo", "该员工已在目标部门中")
+			return verr
 		}
+	}
 
-		var leaveDate *time.Time
-		if req.EdLeaveDate != "" && req.EdLeaveDate != "null" {
-			parsedLeaveDate, err := time.Parse("2006-01-02", req.EdLeaveDate)
-			if err != nil {
-				return errors.New("无效的离职日期格式")
-			}
-			leaveDate = &parsedLeaveDate
+	// 解析日期
+	entryDate, leaveDate := parseEmployeeDepartmentDates(req, verr)
+	if verr.HasErrors() {
+		return verr
+	}
+
+	updated := ed
+	updated.DeptNo = req.DeptNo
+	updated.EdEntryDate = entryDate
+	updated.EdLeaveDate = leaveDate
+	updated.EdStatus = req.EdStatus
+	// 未传 positionId 时保留原职位，传 0 表示清除职位
+	if req.PositionID != nil {
+		if *req.PositionID == 0 {
+			updated.PositionID = nil
+		} else {
+			updated.PositionID = req.PositionID
 		}
+	}
 
-		// 更新所有字段
-		ed.DeptNo = req.DeptNo      // 更新部门编号
-		ed.EdEntryDate = entryDate
-		ed.EdLeaveDate = leaveDate
-		ed.EdStatus = req.EdStatus
+	if err := validateEmployeeDepartment(tx, &updated, &emp, verr); err != nil {
+		return err
+	}
+	if verr.HasErrors() {
+		return verr
+	}
 
-		// 保存更新（触发器会自动处理部门人数的变化）
-		return tx.Save(&ed).Error
-	})
+	// 员工离开原部门时结束其在原部门的经理任期
+	if ed.EdStatus == 1 && (req.EdStatus != 1 || req.DeptNo != ed.DeptNo) {
+		endDate := truncateToDay(time.Now())
+		if leaveDate != nil {
+			endDate = *leaveDate
+		}
+		if err := endManagerAssignments(tx, ed.EmpNo, ed.DeptNo, endDate); err != nil {
+			return errors.New("结束部门经理任期失败")
+		}
+	}
+
+	// 保存更新（触发器会自动处理部门人数的变化）
+	return tx.Save(&updated).Error
 }
 
 // 删除员工部门关系
@@ -265,6 +396,10 @@ func DeleteEmployeeDepartment(edID int) error {
 			return errors.New("部门关系不存在")
 		}
 
+		if err := endManagerAssignments(tx, ed.EmpNo, ed.DeptNo, truncateToDay(time.Now())); err != nil {
+			return errors.New("结束部门经理任期失败")
+		}
+
 		// 删除关系（触发器会自动更新部门人数）
 		return tx.Delete(&ed).Error
 	})
@@ -298,6 +433,77 @@ func DeleteEmployeeAllDepartments(empNo int) error {
 			return errors.New("删除员工部门关系失败")
 		}
 
+		if err := endManagerAssignments(tx, empNo, 0, truncateToDay(time.Now())); err != nil {
+			return errors.New("结束部门经理任期失败")
+		}
+
 		return nil
 	})
-} 
\ No newline at end of file
+}
+
+func init() {
+	RegisterJobHandler("department-headcount-reconcile", func(job *JobContext) error {
+		corrections, err := ReconcileDepartmentHeadcounts(job, job.SetProgressCount)
+		job.SetResult(map[string]interface{}{"corrections": corrections})
+		return err
+	})
+}
+
+// 创建部门人数核对任务
+func EnqueueHeadcountReconcileJob(userID *int) (*models.Job, error) {
+	return EnqueueJob("department-headcount-reconcile", nil, userID)
+}
+
+// 根据有效的员工部门关系重新计算各部门人数，修正与触发器维护的人数不一致的部门
+func ReconcileDepartmentHeadcounts(ctx context.Context, progress func(done, total int)) ([]models.HeadcountCorrection, error) {
+	var departments []models.Department
+	if err := utils.DB.WithContext(ctx).Find(&departments).Error; err != nil {
+		return nil, errors.New("获取部门列表失败")
+	}
+
+	type deptCount struct {
+		DeptNo int
+		Count  int
+	}
+	var counts []deptCount
+	if err := utils.DB.WithContext(ctx).Raw(`
+		SELECT DeptNo, COUNT(DISTINCT EmpNo) as Count
+		FROM Employee_Department
+		WHERE EdStatus = 1
+		GROUP BY DeptNo
+	`).Scan(&counts).Error; err != nil {
+		return nil, errors.New("统计部门人数失败")
+	}
+	actual := make(map[int]int, len(counts))
+	for _, c := range counts {
+		actual[c.DeptNo] = c.Count
+	}
+
+	corrections := []models.HeadcountCorrection{}
+	for i, dept := range departments {
+		if ctx.Err() != nil {
+			return corrections, errors.New("核对已取消")
+		}
+		if progress != nil {
+			progress(i, len(departments))
+		}
+
+		count := actual[dept.DeptNo]
+		if count == dept.DeptPeopleCount {
+			continue
+		}
+		if err := utils.DB.Model(&models.Department{}).
+			Where("DeptNo = ?", dept.DeptNo).
+			UpdateColumn("DeptPeopleCount", count).Error; err != nil {
+			return corrections, errors.New("更新部门人数失败")
+		}
+		corrections = append(corrections, models.HeadcountCorrection{
+			DeptNo:   dept.DeptNo,
+			DeptName: dept.DeptName,
+			Before:   dept.DeptPeopleCount,
+			After:    count,
+		})
+	}
+
+	return corrections, nil
+}
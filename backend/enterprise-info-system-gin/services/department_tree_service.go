@@ -0,0 +1,219 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// 获取部门树，rootDeptNo 为 0 时返回所有顶层部门
+func GetDepartmentTree(rootDeptNo int) ([]*models.DepartmentNode, error) {
+	nodes, roots, err := loadDepartmentNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if rootDeptNo != 0 {
+		node, ok := nodes[rootDeptNo]
+		if !ok {
+			return nil, errors.New("部门不存在")
+		}
+		roots = []*models.DepartmentNode{node}
+	}
+
+	// 统计各子树的在职人数，同一员工在多个下级部门中只计一次
+	var relations []struct {
+		EmpNo  int
+		DeptNo int
+	}
+	if err := utils.DB.Raw(`
+		SELECT DISTINCT EmpNo, DeptNo FROM Employee_Department WHERE EdStatus = 1
+	`).Scan(&relations).Error; err != nil {
+		return nil, errors.New("统计部门人数失败")
+	}
+	members := make(map[int][]int)
+	for _, r := range relations {
+		members[r.DeptNo] = append(members[r.DeptNo], r.EmpNo)
+	}
+	visited := make(map[int]bool)
+	for _, root := range roots {
+		rollUpHeadcount(root, members, visited)
+	}
+
+	return roots, nil
+}
+
+// 读取所有部门并按上下级关系连接，返回编号到节点的映射以及顶层节点
+func loadDepartmentNodes() (map[int]*models.DepartmentNode, []*models.DepartmentNode, error) {
+	var departments []models.Department
+	if err := utils.DB.Order("DeptNo").Find(&departments).Error; err != nil {
+		return nil, nil, errors.New("获取部门列表失败")
+	}
+
+	nodes := make(map[int]*models.DepartmentNode, len(departments))
+	for _, d := range departments {
+		nodes[d.DeptNo] = &models.DepartmentNode{
+			DeptNo:          d.DeptNo,
+			DeptName:        d.DeptName,
+			ParentDeptNo:    d.ParentDeptNo,
+			DeptPeopleCount: d.DeptPeopleCount,
+			Children:        []*models.DepartmentNode{},
+		}
+	}
+
+	var roots []*models.DepartmentNode
+	for _, d := range departments {
+		node := nodes[d.DeptNo]
+		// 上级部门不存在时视为顶层部门
+		if d.ParentDeptNo == nil {
+			roots = append(roots, node)
+			continue
+		}
+		parent, ok := nodes[*d.ParentDeptNo]
+		if !ok {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	// 上下级关系出现循环的部门不能从顶层部门访问到，
+	// 沿上级部门找到循环中的部门，断开它与上级部门的连接并作为顶层部门
+	reached := make(map[int]bool)
+	for _, root := range roots {
+		markDepartmentSubtree(root, reached)
+	}
+	for _, d := range departments {
+		if reached[d.DeptNo] {
+			continue
+		}
+		seen := make(map[int]bool)
+		node := nodes[d.DeptNo]
+		for !seen[node.DeptNo] {
+			seen[node.DeptNo] = true
+			node = nodes[*node.ParentDeptNo]
+		}
+		parent := nodes[*node.ParentDeptNo]
+		for i, child := range parent.Children {
+			if child == node {
+				parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+				break
+			}
+		}
+		log.Printf("部门 %d 的上级部门关系存在循环，已作为顶层部门处理", node.DeptNo)
+		roots = append(roots, node)
+		markDepartmentSubtree(node, reached)
+	}
+	return nodes, roots, nil
+}
+
+// 标记从 root 可以访问到的所有部门
+func markDepartmentSubtree(root *models.DepartmentNode, reached map[int]bool) {
+	reached[root.DeptNo] = true
+	stack := []*models.DepartmentNode{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, child := range node.Children {
+			if !reached[child.DeptNo] {
+				reached[child.DeptNo] = true
+				stack = append(stack, child)
+			}
+		}
+	}
+}
+
+// 自下而上汇总子树人数，返回子树内的员工集合；
+// visited 记录已访问的部门，数据中的上下级关系出现循环时从子节点中去掉已访问的部门
+func rollUpHeadcount(node *models.DepartmentNode, members map[int][]int, visited map[int]bool) map[int]bool {
+	visited[node.DeptNo] = true
+	set := make(map[int]bool)
+	for _, emp := range members[node.DeptNo] {
+		set[emp] = true
+	}
+	children := node.Children[:0]
+	for _, child := range node.Children {
+		if visited[child.DeptNo] {
+			continue
+		}
+		children = append(children, child)
+		for emp := range rollUpHeadcount(child, members, visited) {
+			set[emp] = true
+		}
+	}
+	node.Children = children
+	node.TotalPeopleCount = len(set)
+	return set
+}
+
+// 获取部门及其所有下级部门的编号（包含自身）
+func GetDepartmentSubtreeIDs(deptNo int) ([]int, error) {
+	nodes, _, err := loadDepartmentNodes()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := nodes[deptNo]
+	if !ok {
+		return nil, errors.New("部门不存在")
+	}
+
+	var ids []int
+	visited := map[int]bool{root.DeptNo: true}
+	stack := []*models.DepartmentNode{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		ids = append(ids, node.DeptNo)
+		for _, child := range node.Children {
+			if !visited[child.DeptNo] {
+				visited[child.DeptNo] = true
+				stack = append(stack, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// 移动部门到新的上级部门，newParent 为空表示移动到顶层；
+// 下级部门随之移动，不允许移动到自身或自身的下级部门之下
+func MoveDepartment(deptNo int, newParent *int) error {
+	return utils.DB.Transaction(func(tx *gorm.DB) error {
+		var dept models.Department
+		if err := tx.First(&dept, deptNo).Error; err != nil {
+			return errors.New("部门不存在")
+		}
+
+		if newParent != nil {
+			if *newParent == deptNo {
+				return errors.New("不能将部门移动到自身之下")
+			}
+
+			// 沿新上级部门向上查找，如果遇到当前部门则说明会形成环
+			visited := map[int]bool{}
+			current := newParent
+			for current != nil {
+				if *current == deptNo {
+					return errors.New("不能将部门移动到其下级部门之下")
+				}
+				if visited[*current] {
+					return errors.New("部门层级数据存在循环")
+				}
+				visited[*current] = true
+
+				var ancestor models.Department
+				if err := tx.First(&ancestor, *current).Error; err != nil {
+					if current == newParent {
+						return errors.New("上级部门不存在")
+					}
+					break
+				}
+				current = ancestor.ParentDeptNo
+			}
+		}
+
+		return tx.Model(&dept).Update("ParentDeptNo", newParent).Error
+	})
+}
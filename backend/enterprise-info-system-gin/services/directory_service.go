@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 通讯录导出的默认 LDAP 基准 DN
+const defaultDirectoryBaseDN = "dc=example,dc=com"
+
+// 通讯录导出的过滤条件，Status 为 0 时包含所有员工
+type DirectoryFilter struct {
+	DeptNo int
+	Status int
+}
+
+// 通讯录中的一名员工
+type directoryEntry struct {
+	EmpNo       int
+	FirstName   string
+	LastName    string
+	Telephone   string
+	Address     string
+	Email       string
+	Departments []string
+	Titles      []string
+}
+
+// 解析通讯录过滤条件，默认只导出在职员工，employmentStatus=0 时导出所有员工
+func ParseDirectoryFilter(deptNo, employmentStatus string) (*DirectoryFilter, error) {
+	filter := &DirectoryFilter{Status: models.EmploymentActive}
+	if employmentStatus != "" {
+		status, err := strconv.Atoi(employmentStatus)
+		if err != nil || (status != 0 && status != models.EmploymentActive && status != models.EmploymentTerminated) {
+			return nil, errors.New("无效的在职状态")
+		}
+		filter.Status = status
+	}
+	if deptNo != "" && deptNo != "0" {
+		id, err := strconv.Atoi(deptNo)
+		if err != nil {
+			return nil, errors.New("无效的部门ID")
+		}
+		var dept models.Department
+		if err := utils.DB.First(&dept, id).Error; err != nil {
+			return nil, errors.New("部门不存在")
+		}
+		filter.DeptNo = id
+	}
+	return filter, nil
+}
+
+// 获取通讯录中的员工及其所在部门、职位和主邮箱，empNo 不为 0 时只获取该员工；
+// 用户没有权限查看的员工不包含电话和住址
+func loadDirectoryEntries(ctx context.Context, filter *DirectoryFilter, empNo int, user *models.User) ([]directoryEntry, error) {
+	contactAccess, err := accessibleProfiles(utils.DB.WithContext(ctx), user, "contact-details")
+	if err != nil {
+		return nil, err
+	}
+
+	query := utils.DB.WithContext(ctx).Model(&models.Employee{})
+	if empNo != 0 {
+		query = query.Where("EmpNo = ?", empNo)
+	}
+	if filter.Status != 0 {
+		query = query.Where("EmploymentStatus = ?", filter.Status)
+	}
+	if filter.DeptNo != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM Employee_Department ed WHERE ed.EmpNo = Employees.EmpNo AND ed.DeptNo = ? AND ed.EdStatus = 1)", filter.DeptNo)
+	}
+	var employees []models.Employee
+	if err := query.Order("EmpNo").Find(&employees).Error; err != nil {
+		return nil, errors.New("获取员工信息失败")
+	}
+	if len(employees) == 0 {
+		return nil, nil
+	}
+
+	empNos := make([]int, len(employees))
+	for i, e := range employees {
+		empNos[i] = e.EmpNo
+	}
+
+	var relations []struct {
+		EmpNo    int
+		DeptName string
+		Title    string
+	}
+	if err := utils.DB.WithContext(ctx).Table("Employee_Department ed").
+		Select("ed.EmpNo, d.DeptName, COALESCE(p.Title, '') as Title").
+		Joins("INNER JOIN Departments d ON ed.DeptNo = d.DeptNo").
+		Joins("LEFT JOIN Positions p ON ed.PositionID = p.PositionID").
+		Where("ed.EmpNo IN ? AND ed.EdStatus = 1", empNos).
+		Order("ed.EmpNo, ed.EdEntryDate, ed.EdID").
+		Scan(&relations).Error; err != nil {
+		return nil, errors.New("获取员工部门失败")
+	}
+
+	var emails []models.EmployeeEmail
+	if err := utils.DB.WithContext(ctx).
+		Where("EmpNo IN ? AND IsPrimary = ?", empNos, true).
+		Find(&emails).Error; err != nil {
+		return nil, errors.New("获取员工邮箱失败")
+	}
+	primaryEmails := make(map[int]string, len(emails))
+	for _, e := range emails {
+		primaryEmails[e.EmpNo] = e.Email
+	}
+
+	entries := make([]directoryEntry, len(employees))
+	index := make(map[int]int, len(employees))
+	for i, e := range employees {
+		entries[i] = directoryEntry{
+			EmpNo:     e.EmpNo,
+			FirstName: e.FirstName,
+			LastName:  e.LastName,
+			Telephone: e.Telephone,
+			Address:   e.Address,
+			Email:     primaryEmails[e.EmpNo],
+		}
+		if contactAccess != nil && !contactAccess[e.EmpNo] {
+			entries[i].Telephone, entries[i].Address = "", ""
+		}
+		index[e.EmpNo] = i
+	}
+	for _, r := range relations {
+		entry := &entries[index[r.EmpNo]]
+		entry.Departments = append(entry.Departments, r.DeptName)
+		if r.Title != "" {
+			entry.Titles = append(entry.Titles, r.Title)
+		}
+	}
+	return entries, nil
+}
+
+// 将员工转换为 vCard 联系人
+func directoryVCard(e directoryEntry) utils.VCard {
+	prop := func(name, value string, types ...string) utils.VCardProperty {
+		p := utils.VCardProperty{Name: name, Params: map[string][]string{}, Value: value}
+		if len(types) > 0 {
+			p.Params["TYPE"] = types
+		}
+		return p
+	}
+
+	card := utils.VCard{Properties: []utils.VCardProperty{
+		prop("UID", "urn:employee:"+strconv.Itoa(e.EmpNo)),
+		prop("FN", utils.EscapeVCardText(e.LastName+e.FirstName)),
+		prop("N", utils.EscapeVCardText(e.LastName)+";"+utils.EscapeVCardText(e.FirstName)+";;;"),
+	}}
+	if e.Telephone != "" {
+		card.Properties = append(card.Properties, prop("TEL", utils.EscapeVCardText(e.Telephone), "WORK", "VOICE"))
+	}
+	if e.Email != "" {
+		card.Properties = append(card.Properties, prop("EMAIL", utils.EscapeVCardText(e.Email), "INTERNET", "WORK"))
+	}
+	if e.Address != "" {
+		card.Properties = append(card.Properties, prop("ADR", ";;"+utils.EscapeVCardText(e.Address)+";;;;", "WORK"))
+	}
+	for _, dept := range e.Departments {
+		// ORG 的第一部分为组织名称，部门放在第二部分
+		card.Properties = append(card.Properties, prop("ORG", ";"+utils.EscapeVCardText(dept)))
+	}
+	if len(e.Titles) > 0 {
+		card.Properties = append(card.Properties, prop("TITLE", utils.EscapeVCardText(strings.Join(e.Titles, " / "))))
+	}
+	return card
+}
+
+// 导出通讯录为 vCard 文件（.vcf），每名员工一个联系人
+func ExportDirectoryVCards(ctx context.Context, filter *DirectoryFilter, user *models.User, w io.Writer) error {
+	entries, err := loadDirectoryEntries(ctx, filter, 0, user)
+	if err != nil {
+		return err
+	}
+	cards := make([]utils.VCard, len(entries))
+	for i, e := range entries {
+		cards[i] = directoryVCard(e)
+	}
+	return utils.WriteVCards(w, cards)
+}
+
+// 导出单名员工的 vCard，离职员工同样可以导出
+func ExportEmployeeVCard(ctx context.Context, empNo int, user *models.User, w io.Writer) error {
+	entries, err := loadDirectoryEntries(ctx, &DirectoryFilter{}, empNo, user)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("员工不存在")
+	}
+	return utils.WriteVCards(w, []utils.VCard{directoryVCard(entries[0])})
+}
+
+// 导出通讯录为 LDIF 文件，条目为 uid=<员工编号>,ou=people,<baseDN> 的 inetOrgPerson
+func ExportDirectoryLDIF(ctx context.Context, filter *DirectoryFilter, baseDN string, user *models.User, w io.Writer) error {
+	baseDN = strings.TrimSpace(baseDN)
+	if baseDN == "" {
+		baseDN = defaultDirectoryBaseDN
+	}
+	if !strings.Contains(baseDN, "=") {
+		return errors.New("无效的基准 DN")
+	}
+
+	entries, err := loadDirectoryEntries(ctx, filter, 0, user)
+	if err != nil {
+		return err
+	}
+	ldif := make([]utils.LDIFEntry, 0, len(entries))
+	for _, e := range entries {
+		uid := strconv.Itoa(e.EmpNo)
+		sn := e.LastName
+		if sn == "" {
+			sn = e.FirstName // sn 为必填属性
+		}
+		attrs := []utils.LDIFAttribute{
+			{Name: "objectClass", Value: "top"},
+			{Name: "objectClass", Value: "person"},
+			{Name: "objectClass", Value: "organizationalPerson"},
+			{Name: "objectClass", Value: "inetOrgPerson"},
+			{Name: "uid", Value: uid},
+			{Name: "employeeNumber", Value: uid},
+			{Name: "cn", Value: e.LastName + e.FirstName},
+			{Name: "displayName", Value: e.LastName + e.FirstName},
+			{Name: "sn", Value: sn},
+			{Name: "givenName", Value: e.FirstName},
+			{Name: "telephoneNumber", Value: e.Telephone},
+			{Name: "mail", Value: e.Email},
+			{Name: "postalAddress", Value: ldapPostalAddress(e.Address)},
+		}
+		for _, dept := range e.Departments {
+			attrs = append(attrs, utils.LDIFAttribute{Name: "ou", Value: dept})
+		}
+		for _, title := range e.Titles {
+			attrs = append(attrs, utils.LDIFAttribute{Name: "title", Value: title})
+		}
+		ldif = append(ldif, utils.LDIFEntry{
+			DN:         "uid=" + utils.EscapeLDAPDNValue(uid) + ",ou=people," + baseDN,
+			Attributes: attrs,
+		})
+	}
+	return utils.WriteLDIF(w, ldif)
+}
+
+// postalAddress 以 "$" 分隔各行，值中的反斜杠和 "$" 需要转义
+func ldapPostalAddress(address string) string {
+	return strings.NewReplacer(`\`, `\5C`, "$", `\24`, "\r\n", "$", "\n", "$").Replace(address)
+}
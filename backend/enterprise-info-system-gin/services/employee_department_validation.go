@@ -0,0 +1,113 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 解析员工部门关系请求中的日期，格式错误记录到 verr
+func parseEmployeeDepartmentDates(req *models.EmployeeDepartmentRequest, verr *ValidationError) (time.Time, *time.Time) {
+	entryDate, err := time.Parse("2006-01-02", req.EdEntryDate)
+	if err != nil {
+		verr.Add("edEntryDate", "无效的入职日期格式")
+	}
+
+	var leaveDate *time.Time
+	if req.EdLeaveDate != "" && req.EdLeaveDate != "null" {
+		parsed, err := time.Parse("2006-01-02", req.EdLeaveDate)
+		if err != nil {
+			verr.Add("edLeaveDate", "无效的离职日期格式")
+		} else {
+			leaveDate = &parsed
+		}
+	}
+	return entryDate, leaveDate
+}
+
+// 校验员工部门关系的生命周期约束：
+//   - 状态与离开日期一致：在职不能有离开日期，离职必须有离开日期
+//   - 离开日期不早于加入日期
+//   - 加入日期不早于员工入职日期，已离职员工的加入和离开日期不晚于离职日期
+//   - 同一部门内的任职时间段互不重叠
+//   - 已离职员工不能有在职的部门关系
+//   - 职位存在且适用于该部门
+//
+// ed.EdID 为 0 表示新建的关系。校验不通过的原因记录在 verr 中，查询数据库失败时返回错误
+func validateEmployeeDepartment(tx *gorm.DB, ed *models.EmployeeDepartment, emp *models.Employee, verr *ValidationError) error {
+	switch ed.EdStatus {
+	case 1:
+		if ed.EdLeaveDate != nil {
+			verr.Add("edLeaveDate", "在职状态不能设置离开日期")
+		}
+	case 2:
+		if ed.EdLeaveDate == nil {
+			verr.Add("edLeaveDate", "离职状态必须设置离开日期")
+		}
+	default:
+		verr.Add("edStatus", "状态必须为 1（在职）或 2（离职）")
+	}
+
+	if ed.EdLeaveDate != nil && dateOnly(*ed.EdLeaveDate) < dateOnly(ed.EdEntryDate) {
+		verr.Add("edLeaveDate", "离开日期不能早于加入日期")
+	}
+
+	if ed.EdStatus == 1 && emp.EmploymentStatus == models.EmploymentTerminated {
+		verr.Add("empNo", "该员工已离职")
+	}
+
+	if ed.PositionID != nil {
+		var position models.Position
+		if err := tx.First(&position, *ed.PositionID).Error; err != nil {
+			verr.Add("positionId", "职位不存在")
+		} else if position.DeptNo != nil && *position.DeptNo != ed.DeptNo {
+			verr.Add("positionId", "该职位不适用于此部门")
+		}
+	}
+
+	if dateOnly(ed.EdEntryDate) < dateOnly(emp.HireDate) {
+		verr.Add("edEntryDate", "加入日期不能早于员工入职日期（"+dateOnly(emp.HireDate)+"）")
+	}
+	if emp.EmploymentStatus == models.EmploymentTerminated && emp.TerminationDate != nil {
+		terminated := dateOnly(*emp.TerminationDate)
+		if dateOnly(ed.EdEntryDate) > terminated {
+			verr.Add("edEntryDate", "加入日期不能晚于员工离职日期（"+terminated+"）")
+		}
+		if ed.EdLeaveDate != nil && dateOnly(*ed.EdLeaveDate) > terminated {
+			verr.Add("edLeaveDate", "离开日期不能晚于员工离职日期（"+terminated+"）")
+		}
+	}
+
+	// 同一部门的其他任职时间段，离开当天重新加入不算重叠
+	var others []models.EmployeeDepartment
+	if err := tx.Where("EmpNo = ? AND DeptNo = ? AND EdID != ?", ed.EmpNo, ed.DeptNo, ed.EdID).Find(&others).Error; err != nil {
+		return errors.New("获取员工部门关系失败")
+	}
+	start, end := periodBounds(ed.EdEntryDate, ed.EdLeaveDate)
+	for _, other := range others {
+		otherStart, otherEnd := periodBounds(other.EdEntryDate, other.EdLeaveDate)
+		if start < otherEnd && otherStart < end {
+			until := "至今"
+			if other.EdLeaveDate != nil {
+				until = "至 " + dateOnly(*other.EdLeaveDate)
+			}
+			verr.Add("edEntryDate", "与该部门已有的任职时间段（"+otherStart+" "+until+"）重叠")
+			break
+		}
+	}
+	return nil
+}
+
+// 以日期字符串表示的时间段，未离开时结束日期视为无限远
+func periodBounds(entry time.Time, leave *time.Time) (string, string) {
+	if leave == nil {
+		return dateOnly(entry), "9999-12-31"
+	}
+	return dateOnly(entry), dateOnly(*leave)
+}
+
+func dateOnly(t time.Time) string {
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,577 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 没有权限时返回的错误，控制器据此返回 403
+var ErrProfileForbidden = errors.New("没有访问该信息的权限")
+
+// 个人信息的访问级别，管理员始终可以访问
+const (
+	profileAnyUser = iota // 所有登录用户
+	profileManager        // 员工本人和上级
+	profileSelf           // 员工本人
+	profileAdmin          // 仅管理员
+)
+
+// 各类个人信息的查看和修改权限
+var profileAccess = map[string]struct{ View, Edit int }{
+	"emergency-contacts": {profileManager, profileSelf},
+	"emails":             {profileAnyUser, profileSelf},
+	"id-documents":       {profileSelf, profileAdmin},
+	"education":          {profileAnyUser, profileSelf},
+	"bank-accounts":      {profileSelf, profileSelf},
+	"skills":             {profileAnyUser, profileSelf},
+	"reviews":            {profileManager, profileAdmin},
+	"calendar":           {profileAnyUser, profileSelf},
+	"leave":              {profileManager, profileManager},
+	"attendance":         {profileManager, profileAdmin},
+	"contact-details":    {profileManager, profileAdmin}, // 电话和住址，用于通讯录导出
+	"onboarding":         {profileAnyUser, profileManager},
+	"transfers":          {profileManager, profileAdmin},
+}
+
+// 用户是否可以按指定级别访问该员工的个人信息；
+// 上级包括直属上级和员工所在部门的在任经理
+func canAccessProfile(tx *gorm.DB, user *models.User, empNo, level int) (bool, error) {
+	if user.Role == "Admin" || level == profileAnyUser {
+		return true, nil
+	}
+	if level == profileAdmin || user.EmpNo == nil {
+		return false, nil
+	}
+	if *user.EmpNo == empNo {
+		return true, nil
+	}
+	if level != profileManager {
+		return false, nil
+	}
+
+	var emp models.Employee
+	if err := tx.Select("EmpNo", "ManagerEmpNo").First(&emp, empNo).Error; err == nil &&
+		emp.ManagerEmpNo != nil && *emp.ManagerEmpNo == *user.EmpNo {
+		return true, nil
+	}
+	return isDepartmentManagerOf(tx, *user.EmpNo, empNo)
+}
+
+// 用户可以查看该类个人信息的员工，规则与 checkProfileAccess 相同：
+// 返回 nil 表示所有员工，否则只包括本人、直属下级和用户在任经理的部门的成员
+func accessibleProfiles(tx *gorm.DB, user *models.User, resource string) (map[int]bool, error) {
+	level := profileAccess[resource].View
+	if user.Role == "Admin" || level == profileAnyUser {
+		return nil, nil
+	}
+	allowed := make(map[int]bool)
+	if level == profileAdmin || user.EmpNo == nil {
+		return allowed, nil
+	}
+	allowed[*user.EmpNo] = true
+	if level != profileManager {
+		return allowed, nil
+	}
+
+	var reports []int
+	if err := tx.Model(&models.Employee{}).
+		Where("ManagerEmpNo = ?", *user.EmpNo).
+		Pluck("EmpNo", &reports).Error; err != nil {
+		return nil, errors.New("获取下属员工失败")
+	}
+	today := truncateToDay(time.Now())
+	var members []int
+	if err := tx.Table("Employee_Department ed").
+		Joins("INNER JOIN Department_Managers dm ON dm.DeptNo = ed.DeptNo").
+		Where("dm.EmpNo = ? AND ed.EdStatus = 1", *user.EmpNo).
+		Where("dm.StartDate <= ? AND (dm.EndDate IS NULL OR dm.EndDate >= ?)", today, today).
+		Pluck("ed.EmpNo", &members).Error; err != nil {
+		return nil, errors.New("获取部门成员失败")
+	}
+	for _, empNo := range append(reports, members...) {
+		allowed[empNo] = true
+	}
+	return allowed, nil
+}
+
+// 校验员工存在且用户有相应权限，edit 为 true 时检查修改权限
+func checkProfileAccess(tx *gorm.DB, user *models.User, empNo int, resource string, edit bool) error {
+	var emp models.Employee
+	if err := tx.Select("EmpNo").First(&emp, empNo).Error; err != nil {
+		return errors.New("员工不存在")
+	}
+	access := profileAccess[resource]
+	level := access.View
+	if edit {
+		level = access.Edit
+	}
+	allowed, err := canAccessProfile(tx, user, empNo, level)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrProfileForbidden
+	}
+	return nil
+}
+
+// 解析可选的日期，空字符串返回 nil
+func parseOptionalDate(value, field, label string, verr *ValidationError) *time.Time {
+	if value == "" || value == "null" {
+		return nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		verr.Add(field, "无效的"+label+"格式")
+		return nil
+	}
+	return &parsed
+}
+
+// 校验必填的文本字段
+func checkProfileText(value, field, label string, max int, required bool, verr *ValidationError) string {
+	value = strings.TrimSpace(value)
+	if required && value == "" {
+		verr.Add(field, label+"不能为空")
+	} else if utf8.RuneCountInString(value) > max {
+		verr.Add(field, label+"不能超过"+strconv.Itoa(max)+"个字符")
+	}
+	return value
+}
+
+// 获取员工的紧急联系人
+func GetEmergencyContacts(empNo int, user *models.User) ([]models.EmergencyContact, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "emergency-contacts", false); err != nil {
+		return nil, err
+	}
+	contacts := []models.EmergencyContact{}
+	if err := utils.DB.Where("EmpNo = ?", empNo).Order("ContactID").Find(&contacts).Error; err != nil {
+		return nil, errors.New("获取紧急联系人失败")
+	}
+	return contacts, nil
+}
+
+// 添加或更新紧急联系人，contactID 为 0 时添加
+func SaveEmergencyContact(empNo, contactID int, req *models.EmergencyContactRequest, user *models.User) (*models.EmergencyContact, error) {
+	contact := models.EmergencyContact{EmpNo: empNo}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "emergency-contacts", true); err != nil {
+			return err
+		}
+		if contactID != 0 {
+			if err := tx.Where("EmpNo = ?", empNo).First(&contact, contactID).Error; err != nil {
+				return errors.New("紧急联系人不存在")
+			}
+		}
+
+		verr := &ValidationError{}
+		contact.Name = checkProfileText(req.Name, "name", "姓名", 50, true, verr)
+		contact.Relationship = checkProfileText(req.Relationship, "relationship", "关系", 20, true, verr)
+		contact.Telephone = checkProfileText(normalizeTelephone(req.Telephone), "telephone", "电话", 20, true, verr)
+		contact.Address = checkProfileText(req.Address, "address", "地址", 100, false, verr)
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&contact).Error; err != nil {
+			return errors.New("保存紧急联系人失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &contact, nil
+}
+
+// 删除紧急联系人
+func DeleteEmergencyContact(empNo, contactID int, user *models.User) error {
+	if err := checkProfileAccess(utils.DB, user, empNo, "emergency-contacts", true); err != nil {
+		return err
+	}
+	return deleteProfileRecord(&models.EmergencyContact{}, "ContactID", empNo, contactID, "紧急联系人")
+}
+
+// 获取员工的邮箱，主邮箱在前
+func GetEmployeeEmails(empNo int, user *models.User) ([]models.EmployeeEmail, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "emails", false); err != nil {
+		return nil, err
+	}
+	emails := []models.EmployeeEmail{}
+	if err := utils.DB.Where("EmpNo = ?", empNo).Order("IsPrimary DESC, EmailID").Find(&emails).Error; err != nil {
+		return nil, errors.New("获取邮箱失败")
+	}
+	return emails, nil
+}
+
+// 添加或更新邮箱，emailID 为 0 时添加；设为主邮箱时取消其他邮箱的主邮箱标记，
+// 员工的第一个邮箱自动成为主邮箱
+func SaveEmployeeEmail(empNo, emailID int, req *models.EmployeeEmailRequest, user *models.User) (*models.EmployeeEmail, error) {
+	email := models.EmployeeEmail{EmpNo: empNo}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "emails", true); err != nil {
+			return err
+		}
+		if emailID != 0 {
+			if err := tx.Where("EmpNo = ?", empNo).First(&email, emailID).Error; err != nil {
+				return errors.New("邮箱不存在")
+			}
+		}
+
+		verr := &ValidationError{}
+		address := strings.ToLower(strings.TrimSpace(req.Email))
+		if parsed, err := mail.ParseAddress(address); err != nil || parsed.Address != address {
+			verr.Add("email", "无效的邮箱地址")
+		} else if len(address) > 100 {
+			verr.Add("email", "邮箱地址不能超过100个字符")
+		}
+		emailType := req.EmailType
+		if emailType == "" {
+			emailType = models.EmailWork
+		}
+		if emailType != models.EmailWork && emailType != models.EmailPersonal {
+			verr.Add("emailType", "无效的邮箱类型")
+		}
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		var duplicate int64
+		if err := tx.Model(&models.EmployeeEmail{}).
+			Where("EmpNo = ? AND Email = ? AND EmailID != ?", empNo, address, email.EmailID).
+			Count(&duplicate).Error; err != nil {
+			return errors.New("检查邮箱失败")
+		}
+		if duplicate > 0 {
+			return errors.New("该邮箱已存在")
+		}
+
+		var others int64
+		if err := tx.Model(&models.EmployeeEmail{}).
+			Where("EmpNo = ? AND EmailID != ?", empNo, email.EmailID).
+			Count(&others).Error; err != nil {
+			return errors.New("保存邮箱失败")
+		}
+
+		email.Email = address
+		email.EmailType = emailType
+		email.IsPrimary = req.IsPrimary || others == 0
+		if email.IsPrimary {
+			if err := tx.Model(&models.EmployeeEmail{}).
+				Where("EmpNo = ? AND EmailID != ?", empNo, email.EmailID).
+				Update("IsPrimary", false).Error; err != nil {
+				return errors.New("保存邮箱失败")
+			}
+		}
+		if err := tx.Save(&email).Error; err != nil {
+			return errors.New("保存邮箱失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &email, nil
+}
+
+// 删除邮箱
+func DeleteEmployeeEmail(empNo, emailID int, user *models.User) error {
+	if err := checkProfileAccess(utils.DB, user, empNo, "emails", true); err != nil {
+		return err
+	}
+	return deleteProfileRecord(&models.EmployeeEmail{}, "EmailID", empNo, emailID, "邮箱")
+}
+
+var documentTypes = []string{models.DocumentIDCard, models.DocumentPassport, models.DocumentOther}
+
+// 获取员工的身份证件，默认只返回脱敏号码，reveal 为 true 时返回完整号码
+func GetIdentityDocuments(empNo int, user *models.User, reveal bool) ([]models.IdentityDocument, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "id-documents", false); err != nil {
+		return nil, err
+	}
+
+	documents := []models.IdentityDocument{}
+	if err := utils.DB.Where("EmpNo = ?", empNo).Order("DocumentID").Find(&documents).Error; err != nil {
+		return nil, errors.New("获取证件失败")
+	}
+	if reveal {
+		for i := range documents {
+			number, err := utils.DecryptString(documents[i].NumberEncrypted)
+			if err != nil {
+				return nil, errors.New("解密证件号码失败")
+			}
+			documents[i].Number = number
+		}
+	}
+	return documents, nil
+}
+
+// 添加或更新身份证件，documentID 为 0 时添加；居民身份证号码须为 18 位
+func SaveIdentityDocument(empNo, documentID int, req *models.IdentityDocumentRequest, user *models.User) (*models.IdentityDocument, error) {
+	document := models.IdentityDocument{EmpNo: empNo}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "id-documents", true); err != nil {
+			return err
+		}
+		if documentID != 0 {
+			if err := tx.Where("EmpNo = ?", empNo).First(&document, documentID).Error; err != nil {
+				return errors.New("证件不存在")
+			}
+		}
+
+		verr := &ValidationError{}
+		validType := false
+		for _, t := range documentTypes {
+			if req.DocumentType == t {
+				validType = true
+				break
+			}
+		}
+		if !validType {
+			verr.Add("documentType", "无效的证件类型")
+		}
+
+		number := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(req.Number), " ", ""))
+		switch {
+		case number == "" && document.DocumentID == 0:
+			verr.Add("number", "证件号码不能为空")
+		case len(number) > 50:
+			verr.Add("number", "证件号码不能超过50个字符")
+		case number != "" && req.DocumentType == models.DocumentIDCard && !isValidIDCardNumber(number):
+			verr.Add("number", "无效的居民身份证号码")
+		}
+		expiryDate := parseOptionalDate(req.ExpiryDate, "expiryDate", "有效期", verr)
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		if number != "" {
+			encrypted, err := utils.EncryptString(number)
+			if err != nil {
+				return errors.New("加密证件号码失败")
+			}
+			document.NumberEncrypted = encrypted
+			document.MaskedNumber = utils.MaskString(number, 3)
+		}
+		document.DocumentType = req.DocumentType
+		document.ExpiryDate = expiryDate
+		if err := tx.Save(&document).Error; err != nil {
+			return errors.New("保存证件失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// 校验 18 位居民身份证号码的格式和校验位
+func isValidIDCardNumber(number string) bool {
+	if len(number) != 18 {
+		return false
+	}
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		if number[i] < '0' || number[i] > '9' {
+			return false
+		}
+		sum += int(number[i]-'0') * weights[i]
+	}
+	return number[17] == "10X98765432"[sum%11]
+}
+
+// 删除身份证件
+func DeleteIdentityDocument(empNo, documentID int, user *models.User) error {
+	if err := checkProfileAccess(utils.DB, user, empNo, "id-documents", true); err != nil {
+		return err
+	}
+	return deleteProfileRecord(&models.IdentityDocument{}, "DocumentID", empNo, documentID, "证件")
+}
+
+// 获取员工的教育经历（按开始日期倒序）
+func GetEducationRecords(empNo int, user *models.User) ([]models.EducationRecord, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "education", false); err != nil {
+		return nil, err
+	}
+	records := []models.EducationRecord{}
+	if err := utils.DB.Where("EmpNo = ?", empNo).Order("StartDate DESC, EducationID").Find(&records).Error; err != nil {
+		return nil, errors.New("获取教育经历失败")
+	}
+	return records, nil
+}
+
+// 添加或更新教育经历，educationID 为 0 时添加
+func SaveEducationRecord(empNo, educationID int, req *models.EducationRecordRequest, user *models.User) (*models.EducationRecord, error) {
+	record := models.EducationRecord{EmpNo: empNo}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "education", true); err != nil {
+			return err
+		}
+		if educationID != 0 {
+			if err := tx.Where("EmpNo = ?", empNo).First(&record, educationID).Error; err != nil {
+				return errors.New("教育经历不存在")
+			}
+		}
+
+		verr := &ValidationError{}
+		record.School = checkProfileText(req.School, "school", "学校", 100, true, verr)
+		record.Degree = checkProfileText(req.Degree, "degree", "学历", 50, false, verr)
+		record.Major = checkProfileText(req.Major, "major", "专业", 100, false, verr)
+		record.StartDate = parseOptionalDate(req.StartDate, "startDate", "开始日期", verr)
+		record.EndDate = parseOptionalDate(req.EndDate, "endDate", "结束日期", verr)
+		if record.StartDate != nil && record.EndDate != nil && record.EndDate.Before(*record.StartDate) {
+			verr.Add("endDate", "结束日期不能早于开始日期")
+		}
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		if err := tx.Save(&record).Error; err != nil {
+			return errors.New("保存教育经历失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// 删除教育经历
+func DeleteEducationRecord(empNo, educationID int, user *models.User) error {
+	if err := checkProfileAccess(utils.DB, user, empNo, "education", true); err != nil {
+		return err
+	}
+	return deleteProfileRecord(&models.EducationRecord{}, "EducationID", empNo, educationID, "教育经历")
+}
+
+// 获取员工的银行账户，默认只返回脱敏账号，reveal 为 true 时返回完整账号
+func GetBankAccounts(empNo int, user *models.User, reveal bool) ([]models.BankAccount, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "bank-accounts", false); err != nil {
+		return nil, err
+	}
+
+	accounts := []models.BankAccount{}
+	if err := utils.DB.Where("EmpNo = ?", empNo).Order("IsPrimary DESC, AccountID").Find(&accounts).Error; err != nil {
+		return nil, errors.New("获取银行账户失败")
+	}
+	if reveal {
+		for i := range accounts {
+			number, err := utils.DecryptString(accounts[i].NumberEncrypted)
+			if err != nil {
+				return nil, errors.New("解密银行账号失败")
+			}
+			accounts[i].AccountNumber = number
+		}
+	}
+	return accounts, nil
+}
+
+// 添加或更新银行账户，accountID 为 0 时添加；主账户的处理方式与主邮箱相同
+func SaveBankAccount(empNo, accountID int, req *models.BankAccountRequest, user *models.User) (*models.BankAccount, error) {
+	account := models.BankAccount{EmpNo: empNo}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "bank-accounts", true); err != nil {
+			return err
+		}
+		if accountID != 0 {
+			if err := tx.Where("EmpNo = ?", empNo).First(&account, accountID).Error; err != nil {
+				return errors.New("银行账户不存在")
+			}
+		}
+
+		verr := &ValidationError{}
+		account.BankName = checkProfileText(req.BankName, "bankName", "开户银行", 100, true, verr)
+		account.AccountName = checkProfileText(req.AccountName, "accountName", "户名", 50, true, verr)
+		number := strings.ReplaceAll(strings.TrimSpace(req.AccountNumber), " ", "")
+		switch {
+		case number == "" && account.AccountID == 0:
+			verr.Add("accountNumber", "银行账号不能为空")
+		case number != "" && (len(number) < 8 || len(number) > 30 || strings.Trim(number, "0123456789") != ""):
+			verr.Add("accountNumber", "银行账号必须是8到30位数字")
+		}
+		if err := verr.Err(); err != nil {
+			return err
+		}
+
+		if number != "" {
+			encrypted, err := utils.EncryptString(number)
+			if err != nil {
+				return errors.New("加密银行账号失败")
+			}
+			account.NumberEncrypted = encrypted
+			account.MaskedNumber = strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+		}
+
+		var others int64
+		if err := tx.Model(&models.BankAccount{}).
+			Where("EmpNo = ? AND AccountID != ?", empNo, account.AccountID).
+			Count(&others).Error; err != nil {
+			return errors.New("保存银行账户失败")
+		}
+		account.IsPrimary = req.IsPrimary || others == 0
+		if account.IsPrimary {
+			if err := tx.Model(&models.BankAccount{}).
+				Where("EmpNo = ? AND AccountID != ?", empNo, account.AccountID).
+				Update("IsPrimary", false).Error; err != nil {
+				return errors.New("保存银行账户失败")
+			}
+		}
+		if err := tx.Save(&account).Error; err != nil {
+			return errors.New("保存银行账户失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// 删除银行账户
+func DeleteBankAccount(empNo, accountID int, user *models.User) error {
+	if err := checkProfileAccess(utils.DB, user, empNo, "bank-accounts", true); err != nil {
+		return err
+	}
+	return deleteProfileRecord(&models.BankAccount{}, "AccountID", empNo, accountID, "银行账户")
+}
+
+// 删除属于该员工的一条个人信息记录
+func deleteProfileRecord(model interface{}, idColumn string, empNo, id int, label string) error {
+	result := utils.DB.Where(idColumn+" = ? AND EmpNo = ?", id, empNo).Delete(model)
+	if result.Error != nil {
+		return errors.New("删除" + label + "失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New(label + "不存在")
+	}
+	return nil
+}
+
+// 删除员工的所有个人信息，用于删除员工
+func deleteEmployeeProfile(tx *gorm.DB, empNo int) error {
+	for _, model := range []interface{}{
+		&models.EmergencyContact{},
+		&models.EmployeeEmail{},
+		&models.IdentityDocument{},
+		&models.EducationRecord{},
+		&models.BankAccount{},
+		&models.EmployeeSkill{},
+		&models.CalendarPreference{},
+	} {
+		if err := tx.Where("EmpNo = ?", empNo).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -26,10 +26,25 @@ func CreateEmployee(employee *models.Employee) (*models.Employee, error) {
 	if employee.HireDate.IsZero() {
 		employee.HireDate = time.Now()
 	}
+	// 直属上级只能由管理员通过单独的接口设置
+	employee.ManagerEmpNo = nil
+	employee.EmploymentStatus = models.EmploymentActive
+	employee.TerminationDate = nil
+	employee.TerminationReason = ""
 
-	result := utils.DB.Create(employee)
-	if result.Error != nil {
-		return nil, errors.New("创建员工失败")
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(employee).Error; err != nil {
+			return errors.New("创建员工失败")
+		}
+
+		// 按通用模板生成入职任务
+		if err := createOnboardingTasks(tx, employee, nil); err != nil {
+			return errors.New("生成入职任务失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return employee, nil
@@ -47,6 +62,12 @@ func UpdateEmployee(employee *models.Employee) error {
 		return errors.New("员工不��在")
 	}
 
+	// 直属上级和在职状态只能通过单独的接口修改
+	employee.ManagerEmpNo = existingEmployee.ManagerEmpNo
+	employee.EmploymentStatus = existingEmployee.EmploymentStatus
+	employee.TerminationDate = existingEmployee.TerminationDate
+	employee.TerminationReason = existingEmployee.TerminationReason
+
 	result := utils.DB.Save(employee)
 	if result.Error != nil {
 		return errors.New("更新员工失败")
@@ -57,24 +78,115 @@ func UpdateEmployee(employee *models.Employee) error {
 
 // 删除员工
 func DeleteEmployee(id int) error {
-	return utils.DB.Transaction(func(tx *gorm.DB) error {
-		// 首先删除员工的所有部门关系（触发器会自动更新部门人数）
-		if err := tx.Where("EmpNo = ?", id).Delete(&models.EmployeeDepartment{}).Error; err != nil {
-			return errors.New("删除员工部门关系失败")
-		}
+	var attachments []models.EmployeeAttachment
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		attachments, err = deleteEmployeeRecords(tx, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
-		// 然后删除员工
-		if err := tx.Delete(&models.Employee{}, id).Error; err != nil {
-			return errors.New("删除员工失败")
-		}
+	deleteEmployeeAttachmentObjects(attachments)
+	return nil
+}
 
-		return nil
-	})
+// 在事务中删除员工及其所有记录，返回的附件需要在事务提交后删除文件
+func deleteEmployeeRecords(tx *gorm.DB, id int) ([]models.EmployeeAttachment, error) {
+	var attachments []models.EmployeeAttachment
+
+	// 首先删除员工的所有部门关系（触发器会自动更新部门人数）
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.EmployeeDepartment{}).Error; err != nil {
+		return nil, errors.New("删除员工部门关系失败")
+	}
+
+	// 删除入职任务
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.OnboardingTask{}).Error; err != nil {
+		return nil, errors.New("删除入职任务失败")
+	}
+
+	// 删除附件记录，文件在事务提交后删除
+	if err := tx.Where("EmpNo = ?", id).Find(&attachments).Error; err != nil {
+		return nil, errors.New("获取员工附件失败")
+	}
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.EmployeeAttachment{}).Error; err != nil {
+		return nil, errors.New("删除员工附件失败")
+	}
+
+	if err := deleteEmployeeProfile(tx, id); err != nil {
+		return nil, errors.New("删除员工个人信息失败")
+	}
+
+	if err := deleteEmployeeReviews(tx, id); err != nil {
+		return nil, errors.New("删除绩效评估失败")
+	}
+
+	// 删除合同和相关通知
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.EmployeeContract{}).Error; err != nil {
+		return nil, errors.New("删除合同失败")
+	}
+	if err := tx.Where("NotificationID IN (?)",
+		tx.Model(&models.Notification{}).Select("NotificationID").Where("EmpNo = ?", id)).
+		Delete(&models.NotificationRead{}).Error; err != nil {
+		return nil, errors.New("删除通知失败")
+	}
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.Notification{}).Error; err != nil {
+		return nil, errors.New("删除通知失败")
+	}
+
+	// 删除考勤记录
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.AttendanceRecord{}).Error; err != nil {
+		return nil, errors.New("删除考勤记录失败")
+	}
+
+	// 删除请假记录
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.LeaveRequest{}).Error; err != nil {
+		return nil, errors.New("删除请假记录失败")
+	}
+
+	// 删除薪资记录
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.Salary{}).Error; err != nil {
+		return nil, errors.New("删除薪资记录失败")
+	}
+
+	// 删除调岗记录
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.EmployeeTransfer{}).Error; err != nil {
+		return nil, errors.New("删除调岗记录失败")
+	}
+
+	// 删除经理任命记录，并取消其下属的直属上级
+	if err := tx.Where("EmpNo = ?", id).Delete(&models.DepartmentManager{}).Error; err != nil {
+		return nil, errors.New("删除部门经理记录失败")
+	}
+	if err := tx.Model(&models.Employee{}).Where("ManagerEmpNo = ?", id).
+		Update("ManagerEmpNo", nil).Error; err != nil {
+		return nil, errors.New("更新下属员工失败")
+	}
+
+	// 然后删除员工
+	if err := tx.Delete(&models.Employee{}, id).Error; err != nil {
+		return nil, errors.New("删除员工失败")
+	}
+
+	return attachments, nil
 }
 
 // 搜索员工
 func SearchEmployees(params map[string]interface{}) ([]models.Employee, error) {
 	var employees []models.Employee
+
+	// 执行查询并按员工编号降序排序
+	err := buildEmployeeSearchQuery(params).Find(&employees).Error
+	if err != nil {
+		return nil, errors.New("查询员工信息失败")
+	}
+
+	return employees, nil
+}
+
+// 根据搜索参数构建员工查询，搜索和导出共用
+func buildEmployeeSearchQuery(params map[string]interface{}) *gorm.DB {
 	query := utils.DB.Model(&models.Employee{}).Select("DISTINCT Employees.*")
 
 	// 姓名搜索（支持姓、名、全名搜索）
@@ -93,6 +205,13 @@ func SearchEmployees(params map[string]interface{}) ([]models.Employee, error) {
 		query = query.Where("DATE(HireDate) <= ?", endDate)
 	}
 
+	// 在职状态搜索
+	if status, ok := params["employmentStatus"].(float64); ok && status > 0 {
+		query = query.Where("Employees.EmploymentStatus = ?", int(status))
+	} else if status, ok := params["employmentStatus"].(string); ok && status != "" && status != "0" {
+		query = query.Where("Employees.EmploymentStatus = ?", status)
+	}
+
 	// 部门搜索
 	// 先尝试获取 float64 类型（JSON 数字会被解析为 float64）
 	if deptNoFloat, ok := params["department"].(float64); ok && deptNoFloat > 0 {
@@ -110,13 +229,11 @@ func SearchEmployees(params map[string]interface{}) ([]models.Employee, error) {
 		`, deptNoStr)
 	}
 
-	// 执行查询并按员工编号降序排序
-	err := query.Order("Employees.EmpNo DESC").Find(&employees).Error
-	if err != nil {
-		return nil, errors.New("查询员工信息失败")
-	}
+	// 技能搜索，skillMatch 为 any 时满足任一技能条件即可
+	matchAny := params["skillMatch"] == "any"
+	query = applySkillCriteria(query, parseSkillCriteria(params["skills"]), matchAny)
 
-	return employees, nil
+	return query.Order("Employees.EmpNo DESC")
 }
 
 // 获取员工详细信息（包括部门信息）
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+// 可导出的数据类型
+const (
+	ExportEntityEmployees           = "employees"
+	ExportEntityCustomers           = "customers"
+	ExportEntityDepartments         = "departments"
+	ExportEntityEmployeeDepartments = "employee_departments"
+)
+
+// 后台导出任务的参数
+type exportJobPayload struct {
+	Entity string                 `json:"entity"`
+	Format string                 `json:"format"`
+	Params map[string]interface{} `json:"params"`
+}
+
+func init() {
+	RegisterJobHandler("export", func(job *JobContext) error {
+		var payload exportJobPayload
+		if err := job.Bind(&payload); err != nil {
+			return errors.New("无效的任务参数")
+		}
+		f, err := job.CreateResultFile(ExportFilename(payload.Entity, payload.Format))
+		if err != nil {
+			return errors.New("创建导出文件失败")
+		}
+		defer f.Close()
+		return Export(job, payload.Entity, payload.Params, payload.Format, f)
+	})
+}
+
+// 创建后台导出任务
+func EnqueueExportJob(entity, format string, params map[string]interface{}, userID *int) (*models.Job, error) {
+	if !isValidExportEntity(entity) {
+		return nil, errors.New("不支持的导出类型")
+	}
+	if !utils.IsValidExportFormat(format) {
+		return nil, errors.New("不支持的导出格式")
+	}
+	return EnqueueJob("export", exportJobPayload{Entity: entity, Format: format, Params: params}, userID)
+}
+
+// 导出文件名，例如 employees_20240101120000.xlsx
+func ExportFilename(entity, format string) string {
+	return entity + "_" + time.Now().Format("20060102150405") + "." + format
+}
+
+func isValidExportEntity(entity string) bool {
+	switch entity {
+	case ExportEntityEmployees, ExportEntityCustomers, ExportEntityDepartments, ExportEntityEmployeeDepartments:
+		return true
+	}
+	return false
+}
+
+// 按数据类型导出，params 仅对员工导出生效
+func Export(ctx context.Context, entity string, params map[string]interface{}, format string, w io.Writer) error {
+	switch entity {
+	case ExportEntityEmployees:
+		return ExportEmployees(ctx, params, format, w)
+	case ExportEntityCustomers:
+		return ExportCustomers(ctx, format, w)
+	case ExportEntityDepartments:
+		return ExportDepartments(ctx, format, w)
+	case ExportEntityEmployeeDepartments:
+		return ExportEmployeeDepartments(ctx, format, w)
+	}
+	return errors.New("不支持的导出类型")
+}
+
+// 各导出文件的列定义
+var (
+	employeeExportColumns = []utils.TableColumn{
+		{Header: "员工编号", Width: 1},
+		{Header: "姓名", Width: 1.5},
+		{Header: "性别", Width: 0.7},
+		{Header: "出生日期", Width: 1.3},
+		{Header: "入职日期", Width: 1.3},
+		{Header: "在职状态", Width: 0.8},
+		{Header: "离职日期", Width: 1.3},
+		{Header: "联系电话", Width: 1.8},
+		{Header: "地址", Width: 3},
+	}
+	customerExportColumns = []utils.TableColumn{
+		{Header: "客户编号", Width: 1},
+		{Header: "客户名称", Width: 1.5},
+		{Header: "公司", Width: 2.5},
+		{Header: "性别", Width: 0.7},
+		{Header: "年龄", Width: 0.7},
+		{Header: "联系电话", Width: 1.8},
+		{Header: "地址", Width: 3.5},
+	}
+	departmentExportColumns = []utils.TableColumn{
+		{Header: "部门编号", Width: 1},
+		{Header: "部门名称", Width: 3},
+		{Header: "部门人数", Width: 1},
+	}
+	employeeDepartmentExportColumns = []utils.TableColumn{
+		{Header: "关系编号", Width: 1},
+		{Header: "员工编号", Width: 1},
+		{Header: "员工姓名", Width: 1.5},
+		{Header: "部门编号", Width: 1},
+		{Header: "部门名称", Width: 2},
+		{Header: "职位", Width: 1.5},
+		{Header: "加入日期", Width: 1.3},
+		{Header: "离开日期", Width: 1.3},
+		{Header: "状态", Width: 0.8},
+	}
+)
+
+// 导出员工，支持与员工搜索相同的过滤条件
+func ExportEmployees(ctx context.Context, params map[string]interface{}, format string, w io.Writer) error {
+	rows, err := buildEmployeeSearchQuery(params).WithContext(ctx).Rows()
+	if err != nil {
+		return errors.New("查询员工信息失败")
+	}
+	defer rows.Close()
+
+	return writeExportRows(rows, format, w, "员工信息", employeeExportColumns, func() ([]string, error) {
+		var e models.Employee
+		if err := utils.DB.ScanRows(rows, &e); err != nil {
+			return nil, err
+		}
+		terminationDate := ""
+		if e.TerminationDate != nil {
+			terminationDate = formatExportDate(*e.TerminationDate)
+		}
+		return []string{
+			strconv.Itoa(e.EmpNo),
+			e.LastName + e.FirstName,
+			genderText(e.Gender),
+			formatExportDate(e.Birthday),
+			formatExportDate(e.HireDate),
+			edStatusText(e.EmploymentStatus),
+			terminationDate,
+			e.Telephone,
+			e.Address,
+		}, nil
+	})
+}
+
+// 导出客户
+func ExportCustomers(ctx context.Context, format string, w io.Writer) error {
+	rows, err := utils.DB.WithContext(ctx).Model(&models.Customer{}).Order("CustomerID").Rows()
+	if err != nil {
+		return errors.New("查询客户信息失败")
+	}
+	defer rows.Close()
+
+	return writeExportRows(rows, format, w, "客户信息", customerExportColumns, func() ([]string, error) {
+		var c models.Customer
+		if err := utils.DB.ScanRows(rows, &c); err != nil {
+			return nil, err
+		}
+		age := ""
+		if c.Age > 0 {
+			age = strconv.Itoa(c.Age)
+		}
+		return []string{
+			strconv.Itoa(c.CustomerID),
+			c.CustomerName,
+			c.Company,
+			c.Sex,
+			age,
+			c.Telephone,
+			c.Address,
+		}, nil
+	})
+}
+
+// 导出部门
+func ExportDepartments(ctx context.Context, format string, w io.Writer) error {
+	rows, err := utils.DB.WithContext(ctx).Model(&models.Department{}).Order("DeptNo").Rows()
+	if err != nil {
+		return errors.New("查询部门信息失败")
+	}
+	defer rows.Close()
+
+	return writeExportRows(rows, format, w, "部门信息", departmentExportColumns, func() ([]string, error) {
+		var d models.Department
+		if err := utils.DB.ScanRows(rows, &d); err != nil {
+			return nil, err
+		}
+		return []string{
+			strconv.Itoa(d.DeptNo),
+			d.DeptName,
+			strconv.Itoa(d.DeptPeopleCount),
+		}, nil
+	})
+}
+
+// 员工部门关系导出时的一行数据
+type employeeDepartmentExportRow struct {
+	EdID         int
+	EmpNo        int
+	EmployeeName string
+	DeptNo       int
+	DeptName     string
+	PositionName string
+	EdEntryDate  time.Time
+	EdLeaveDate  *time.Time
+	EdStatus     int
+}
+
+// 导出员工部门关系，排序与分组列表保持一致
+func ExportEmployeeDepartments(ctx context.Context, format string, w io.Writer) error {
+	rows, err := utils.DB.WithContext(ctx).Raw(`
+		SELECT ed.EdID, ed.EmpNo, CONCAT(e.LastName, e.FirstName) as EmployeeName,
+			   ed.DeptNo, d.DeptName, COALESCE(p.Title, '') as PositionName,
+			   ed.EdEntryDate, ed.EdLeaveDate, ed.EdStatus
+		FROM Employee_Department ed
+		INNER JOIN Employees e ON ed.EmpNo = e.EmpNo
+		INNER JOIN Departments d ON ed.DeptNo = d.DeptNo
+		LEFT JOIN Positions p ON ed.PositionID = p.PositionID
+		ORDER BY ed.EmpNo, ed.EdEntryDate DESC
+	`).Rows()
+	if err != nil {
+		return errors.New("查询员工部门关系失败")
+	}
+	defer rows.Close()
+
+	return writeExportRows(rows, format, w, "员工部门关系", employeeDepartmentExportColumns, func() ([]string, error) {
+		var r employeeDepartmentExportRow
+		if err := utils.DB.ScanRows(rows, &r); err != nil {
+			return nil, err
+		}
+		leaveDate := ""
+		if r.EdLeaveDate != nil {
+			leaveDate = formatExportDate(*r.EdLeaveDate)
+		}
+		return []string{
+			strconv.Itoa(r.EdID),
+			strconv.Itoa(r.EmpNo),
+			r.EmployeeName,
+			strconv.Itoa(r.DeptNo),
+			r.DeptName,
+			r.PositionName,
+			formatExportDate(r.EdEntryDate),
+			leaveDate,
+			edStatusText(r.EdStatus),
+		}, nil
+	})
+}
+
+// 逐行读取查询结果并写入导出文件，不会一次性加载整张表
+func writeExportRows(rows *sql.Rows, format string, w io.Writer, title string,
+	columns []utils.TableColumn, scan func() ([]string, error)) error {
+	tw, err := utils.NewTableWriter(format, w, title, columns)
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		values, err := scan()
+		if err != nil {
+			return errors.New("读取导出数据失败")
+		}
+		if err := tw.WriteRow(values); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return errors.New("读取导出数据失败")
+	}
+
+	return tw.Close()
+}
+
+func genderText(gender int) string {
+	if gender == 1 {
+		return "男"
+	}
+	return "女"
+}
+
+func edStatusText(status int) string {
+	if status == 1 {
+		return "在职"
+	}
+	return "离职"
+}
+
+func formatExportDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 人数趋势的时间间隔
+const (
+	HeadcountIntervalDay   = "day"
+	HeadcountIntervalWeek  = "week"
+	HeadcountIntervalMonth = "month"
+)
+
+// 人数趋势最多返回的时间点数
+const maxHeadcountPoints = 400
+
+func init() {
+	RegisterJobHandler("headcount-snapshot", func(job *JobContext) error {
+		var payload struct {
+			Date string `json:"date"`
+		}
+		if err := job.Bind(&payload); err != nil {
+			return errors.New("无效的任务参数")
+		}
+		day := truncateToDay(time.Now()).AddDate(0, 0, -1)
+		if payload.Date != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", payload.Date, time.Local)
+			if err != nil {
+				return errors.New("无效的快照日期")
+			}
+			day = parsed
+		}
+		departments, err := TakeHeadcountSnapshot(job, day)
+		job.SetResult(map[string]interface{}{"date": dateOnly(day), "departments": departments})
+		return err
+	})
+
+	RegisterJobHandler("headcount-backfill", func(job *JobContext) error {
+		var payload struct {
+			From string `json:"from"`
+			To   string `json:"to"`
+		}
+		if err := job.Bind(&payload); err != nil {
+			return errors.New("无效的任务参数")
+		}
+		from, to, err := parseBackfillRange(payload.From, payload.To)
+		if err != nil {
+			return err
+		}
+		days, err := BackfillHeadcountSnapshots(job, from, to, job.SetProgressCount)
+		job.SetResult(map[string]interface{}{"days": days})
+		return err
+	})
+
+	// 每天凌晨 1 点记录前一天结束时的部门人数
+	RegisterDailyJob("headcount-snapshot", 1, func() interface{} {
+		return map[string]string{"date": dateOnly(truncateToDay(time.Now()).AddDate(0, 0, -1))}
+	})
+}
+
+// 根据部门关系统计各部门在某一天结束时的在职人数
+func countDepartmentHeadcounts(ctx context.Context, day time.Time) (map[int]int, error) {
+	until := day.AddDate(0, 0, 1)
+	var counts []struct {
+		DeptNo int
+		Count  int
+	}
+	if err := utils.DB.WithContext(ctx).Table("Employee_Department ed").
+		Select("ed.DeptNo, COUNT(DISTINCT ed.EmpNo) as Count").
+		Where(relationActiveAtSQL, until, until).
+		Group("ed.DeptNo").
+		Scan(&counts).Error; err != nil {
+		return nil, errors.New("统计部门人数失败")
+	}
+
+	result := make(map[int]int, len(counts))
+	for _, c := range counts {
+		result[c.DeptNo] = c.Count
+	}
+	return result, nil
+}
+
+// 记录各部门在某一天结束时的人数，已有的当天快照会被覆盖，返回记录的部门数
+func TakeHeadcountSnapshot(ctx context.Context, day time.Time) (int, error) {
+	day = truncateToDay(day)
+	counts, err := countDepartmentHeadcounts(ctx, day)
+	if err != nil {
+		return 0, err
+	}
+
+	var departments []models.Department
+	if err := utils.DB.WithContext(ctx).Select("DeptNo").Find(&departments).Error; err != nil {
+		return 0, errors.New("获取部门列表失败")
+	}
+	snapshots := make([]models.HeadcountSnapshot, 0, len(departments))
+	for _, d := range departments {
+		snapshots = append(snapshots, models.HeadcountSnapshot{
+			DeptNo:       d.DeptNo,
+			SnapshotDate: day,
+			Headcount:    counts[d.DeptNo],
+		})
+	}
+
+	err = utils.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("SnapshotDate = ?", day).Delete(&models.HeadcountSnapshot{}).Error; err != nil {
+			return errors.New("删除旧的人数快照失败")
+		}
+		if len(snapshots) > 0 {
+			if err := tx.CreateInBatches(snapshots, 200).Error; err != nil {
+				return errors.New("保存人数快照失败")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// 解析补录范围，结束日期不能晚于昨天（当天尚未结束）
+func parseBackfillRange(from, to string) (time.Time, time.Time, error) {
+	filter, err := ParseAnalyticsFilter(from, to, "", 0)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if from == "" {
+		return time.Time{}, time.Time{}, errors.New("请指定开始日期")
+	}
+	if yesterday := truncateToDay(time.Now()).AddDate(0, 0, -1); filter.To.After(yesterday) {
+		filter.To = yesterday
+	}
+	if filter.To.Before(filter.From) {
+		return time.Time{}, time.Time{}, errors.New("只能补录昨天及以前的人数")
+	}
+	return filter.From, filter.To, nil
+}
+
+// 创建人数快照补录任务
+func EnqueueHeadcountBackfillJob(from, to string, userID *int) (*models.Job, error) {
+	if _, _, err := parseBackfillRange(from, to); err != nil {
+		return nil, err
+	}
+	return EnqueueJob("headcount-backfill", map[string]string{"from": from, "to": to}, userID)
+}
+
+// 根据部门关系的加入和离开日期补录历史人数快照，已有快照的日期保持不变，
+// 返回补录的天数
+func BackfillHeadcountSnapshots(ctx context.Context, from, to time.Time, progress func(done, total int)) (int, error) {
+	var existing []time.Time
+	if err := utils.DB.WithContext(ctx).Model(&models.HeadcountSnapshot{}).
+		Distinct("SnapshotDate").
+		Where("SnapshotDate BETWEEN ? AND ?", from, to).
+		Pluck("SnapshotDate", &existing).Error; err != nil {
+		return 0, errors.New("获取已有的人数快照失败")
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, d := range existing {
+		taken[dateOnly(d)] = true
+	}
+
+	total := int(to.Sub(from).Hours()/24) + 1
+	filled := 0
+	for i, day := 0, from; !day.After(to); i, day = i+1, day.AddDate(0, 0, 1) {
+		if ctx.Err() != nil {
+			return filled, errors.New("补录已取消")
+		}
+		if progress != nil {
+			progress(i, total)
+		}
+		if taken[dateOnly(day)] {
+			continue
+		}
+		if _, err := TakeHeadcountSnapshot(ctx, day); err != nil {
+			return filled, err
+		}
+		filled++
+	}
+	return filled, nil
+}
+
+// 计算人数趋势的时间点：每个周期取最后一天，最后一个时间点为结束日期
+func headcountPointDates(from, to time.Time, interval string) ([]time.Time, error) {
+	var dates []time.Time
+	for start := from; !start.After(to); {
+		var next time.Time
+		switch interval {
+		case HeadcountIntervalDay:
+			next = start.AddDate(0, 0, 1)
+		case HeadcountIntervalWeek:
+			next = start.AddDate(0, 0, 7)
+		case HeadcountIntervalMonth:
+			next = time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, start.Location())
+		default:
+			return nil, errors.New("无效的时间间隔")
+		}
+		end := next.AddDate(0, 0, -1)
+		if end.After(to) {
+			end = to
+		}
+		dates = append(dates, end)
+		if len(dates) > maxHeadcountPoints {
+			return nil, errors.New("时间点过多，请缩小范围或增大时间间隔（最多" + strconv.Itoa(maxHeadcountPoints) + "个）")
+		}
+		start = next
+	}
+	return dates, nil
+}
+
+// 获取部门人数趋势：有快照的日期使用快照，其余日期根据部门关系的加入和离开日期推算
+func GetDepartmentHeadcountTrend(deptNo int, from, to, interval string) (*models.HeadcountTrend, error) {
+	var dept models.Department
+	if err := utils.DB.First(&dept, deptNo).Error; err != nil {
+		return nil, errors.New("部门不存在")
+	}
+	if interval == "" {
+		interval = HeadcountIntervalMonth
+	}
+	filter, err := ParseAnalyticsFilter(from, to, "", 12)
+	if err != nil {
+		return nil, err
+	}
+	dates, err := headcountPointDates(filter.From, filter.To, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []models.HeadcountSnapshot
+	if err := utils.DB.Where("DeptNo = ? AND SnapshotDate BETWEEN ? AND ?", deptNo, filter.From, filter.To).
+		Find(&snapshots).Error; err != nil {
+		return nil, errors.New("获取人数快照失败")
+	}
+	snapshotCounts := make(map[string]int, len(snapshots))
+	for _, s := range snapshots {
+		snapshotCounts[dateOnly(s.SnapshotDate)] = s.Headcount
+	}
+
+	var relations []models.EmployeeDepartment
+	if err := utils.DB.Select("EmpNo", "EdEntryDate", "EdLeaveDate", "EdStatus").
+		Where("DeptNo = ?", deptNo).
+		Find(&relations).Error; err != nil {
+		return nil, errors.New("获取部门关系失败")
+	}
+
+	trend := &models.HeadcountTrend{
+		DeptNo:   dept.DeptNo,
+		DeptName: dept.DeptName,
+		Interval: interval,
+		Points:   make([]models.HeadcountPoint, 0, len(dates)),
+	}
+	for _, d := range dates {
+		key := dateOnly(d)
+		if count, ok := snapshotCounts[key]; ok {
+			trend.Points = append(trend.Points, models.HeadcountPoint{Date: key, Headcount: count, Source: "snapshot"})
+			continue
+		}
+
+		until := d.AddDate(0, 0, 1)
+		members := make(map[int]bool)
+		for _, r := range relations {
+			if !r.EdEntryDate.Before(until) {
+				continue
+			}
+			if (r.EdLeaveDate == nil && r.EdStatus == 1) || (r.EdLeaveDate != nil && !r.EdLeaveDate.Before(until)) {
+				members[r.EmpNo] = true
+			}
+		}
+		trend.Points = append(trend.Points, models.HeadcountPoint{Date: key, Headcount: len(members), Source: "computed"})
+	}
+	return trend, nil
+}
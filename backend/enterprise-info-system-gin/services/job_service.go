@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 任务结果文件和上传文件的存放目录
+const (
+	JobResultDir = "data/jobs"
+	JobUploadDir = "data/uploads"
+)
+
+// 服务重启时被中断的任务最多重试的次数
+const maxJobAttempts = 3
+
+// 任务处理函数，返回错误时任务标记为失败；
+// 任务被取消时 ctx 会被关闭，处理函数应尽快返回
+type JobHandler func(job *JobContext) error
+
+// 传递给任务处理函数的上下文
+type JobContext struct {
+	context.Context
+	Job *models.Job
+
+	progress int
+	result   interface{}
+	file     string
+}
+
+// 将任务参数解析到 v
+func (j *JobContext) Bind(v interface{}) error {
+	if j.Job.Payload == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(j.Job.Payload), v)
+}
+
+// 更新任务进度（0-100），仅在数值变化时写库
+func (j *JobContext) SetProgress(percent int) {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	if percent == j.progress {
+		return
+	}
+	j.progress = percent
+	utils.DB.Model(&models.Job{}).Where("JobID = ?", j.Job.JobID).Update("Progress", percent)
+}
+
+// 按已处理数量和总数更新进度
+func (j *JobContext) SetProgressCount(done, total int) {
+	if total <= 0 {
+		return
+	}
+	j.SetProgress(done * 100 / total)
+}
+
+// 设置任务结果，会以 JSON 形式保存
+func (j *JobContext) SetResult(v interface{}) {
+	j.result = v
+}
+
+// 创建任务结果文件，文件名即下载时的文件名
+func (j *JobContext) CreateResultFile(filename string) (*os.File, error) {
+	dir := filepath.Join(JobResultDir, fmt.Sprint(j.Job.JobID))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, filepath.Base(filename))
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	j.file = path
+	return f, nil
+}
+
+var (
+	jobHandlers = map[string]JobHandler{}
+	jobCleanups = map[string]func(job *models.Job){}
+	dailyJobs   = map[string]dailyJob{}
+
+	jobQueue   chan int
+	jobMu      sync.Mutex
+	jobQueued  = map[int]bool{}
+	jobCancels = map[int]context.CancelFunc{}
+)
+
+// 注册任务类型，各业务服务在 init 中调用
+func RegisterJobHandler(jobType string, handler JobHandler) {
+	jobHandlers[jobType] = handler
+}
+
+// 注册任务结束后的清理函数（如删除上传文件），任务成功、失败或取消时都会调用
+func RegisterJobCleanup(jobType string, cleanup func(job *models.Job)) {
+	jobCleanups[jobType] = cleanup
+}
+
+// 执行任务的清理函数
+func cleanupJob(job *models.Job) {
+	if cleanup, ok := jobCleanups[job.JobType]; ok {
+		cleanup(job)
+	}
+}
+
+// 每天定时执行的任务
+type dailyJob struct {
+	hour    int
+	payload func() interface{}
+}
+
+// 注册每天定时执行的任务，在每天 hour 点之后创建当天的任务，
+// 以数据库中当天是否已创建过该类型的任务判断，服务重启不会重复执行
+func RegisterDailyJob(jobType string, hour int, payload func() interface{}) {
+	dailyJobs[jobType] = dailyJob{hour: hour, payload: payload}
+}
+
+// 为到点且当天尚未创建的定时任务创建任务
+func enqueueDailyJobs() {
+	now := time.Now()
+	today := truncateToDay(now)
+	for jobType, daily := range dailyJobs {
+		if now.Hour() < daily.hour {
+			continue
+		}
+		var count int64
+		if err := utils.DB.Model(&models.Job{}).
+			Where("JobType = ? AND CreatedAt >= ?", jobType, today).
+			Count(&count).Error; err != nil {
+			log.Printf("检查定时任务 %s 失败: %v\n", jobType, err)
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+		var payload interface{}
+		if daily.payload != nil {
+			payload = daily.payload()
+		}
+		if _, err := EnqueueJob(jobType, payload, nil); err != nil {
+			log.Printf("创建定时任务 %s 失败: %v\n", jobType, err)
+		}
+	}
+}
+
+// 启动任务工作池，并恢复上次服务停止时未完成的任务
+func StartJobWorkers(workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	jobQueue = make(chan int, 1000)
+	for i := 0; i < workers; i++ {
+		go jobWorker()
+	}
+
+	recoverJobs()
+	enqueueDailyJobs()
+
+	// 队列已满时任务会留在数据库中，定期重新扫描；同时检查定时任务
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for range ticker.C {
+			enqueuePendingJobs()
+			enqueueDailyJobs()
+		}
+	}()
+}
+
+// 将上次运行中断的任务重新置为待执行
+func recoverJobs() {
+	now := time.Now()
+	var exhausted []models.Job
+	if err := utils.DB.Where("Status = ? AND Attempts >= ?", models.JobStatusRunning, maxJobAttempts).
+		Find(&exhausted).Error; err != nil {
+		log.Printf("读取中断任务失败: %v\n", err)
+	}
+	for i := range exhausted {
+		if err := utils.DB.Model(&exhausted[i]).Updates(map[string]interface{}{
+			"Status":     models.JobStatusFailed,
+			"Error":      "服务重启导致任务中断，且已超过最大重试次数",
+			"FinishedAt": &now,
+		}).Error; err == nil {
+			cleanupJob(&exhausted[i])
+		}
+	}
+
+	result := utils.DB.Model(&models.Job{}).
+		Where("Status = ?", models.JobStatusRunning).
+		Updates(map[string]interface{}{"Status": models.JobStatusPending, "Progress": 0})
+	if result.Error != nil {
+		log.Printf("恢复中断任务失败: %v\n", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("已恢复 %d 个中断的任务\n", result.RowsAffected)
+	}
+
+	enqueuePendingJobs()
+}
+
+func enqueuePendingJobs() {
+	var ids []int
+	if err := utils.DB.Model(&models.Job{}).
+		Where("Status = ?", models.JobStatusPending).
+		Order("JobID").
+		Pluck("JobID", &ids).Error; err != nil {
+		log.Printf("读取待执行任务失败: %v\n", err)
+		return
+	}
+	for _, id := range ids {
+		enqueueJobID(id)
+	}
+}
+
+// 放入队列，队列已满时跳过，等待定期扫描
+func enqueueJobID(id int) {
+	jobMu.Lock()
+	defer jobMu.Unlock()
+	if jobQueue == nil || jobQueued[id] {
+		return
+	}
+	select {
+	case jobQueue <- id:
+		jobQueued[id] = true
+	default:
+	}
+}
+
+// 创建任务并放入队列，userID 为提交任务的用户，只有该用户和管理员可以查看任务
+func EnqueueJob(jobType string, payload interface{}, userID *int) (*models.Job, error) {
+	if _, ok := jobHandlers[jobType]; !ok {
+		return nil, errors.New("未知的任务类型")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.New("无效的任务参数")
+	}
+
+	job := &models.Job{
+		JobType: jobType,
+		Status:  models.JobStatusPending,
+		Payload: string(data),
+		UserID:  userID,
+	}
+	if err := utils.DB.Create(job).Error; err != nil {
+		return nil, errors.New("创建任务失败")
+	}
+
+	enqueueJobID(job.JobID)
+	return job, nil
+}
+
+func jobWorker() {
+	for id := range jobQueue {
+		jobMu.Lock()
+		delete(jobQueued, id)
+		jobMu.Unlock()
+
+		runJob(id)
+	}
+}
+
+func runJob(id int) {
+	// 先登记取消函数再抢占任务，任务一旦进入运行状态就可以被取消
+	ctx, cancel := context.WithCancel(context.Background())
+	jobMu.Lock()
+	jobCancels[id] = cancel
+	jobMu.Unlock()
+	defer func() {
+		jobMu.Lock()
+		delete(jobCancels, id)
+		jobMu.Unlock()
+		cancel()
+	}()
+
+	// 通过条件更新抢占任务，避免重复执行或执行已取消的任务
+	now := time.Now()
+	claim := utils.DB.Model(&models.Job{}).
+		Where("JobID = ? AND Status = ?", id, models.JobStatusPending).
+		Updates(map[string]interface{}{
+			"Status":    models.JobStatusRunning,
+			"StartedAt": &now,
+			"Attempts":  gorm.Expr("Attempts + ?", 1),
+		})
+	if claim.Error != nil || claim.RowsAffected == 0 {
+		return
+	}
+
+	var job models.Job
+	if err := utils.DB.First(&job, id).Error; err != nil {
+		return
+	}
+
+	jc := &JobContext{Context: ctx, Job: &job}
+	err := executeJob(jc)
+
+	finished := time.Now()
+	updates := map[string]interface{}{"FinishedAt": &finished}
+	switch {
+	case ctx.Err() != nil:
+		updates["Status"] = models.JobStatusCanceled
+		updates["Error"] = "任务已取消"
+	case err != nil:
+		updates["Status"] = models.JobStatusFailed
+		updates["Error"] = err.Error()
+	default:
+		updates["Status"] = models.JobStatusSucceeded
+		updates["Progress"] = 100
+	}
+	if jc.result != nil {
+		if data, err := json.Marshal(jc.result); err == nil {
+			updates["Result"] = string(data)
+		}
+	}
+	if jc.file != "" && updates["Status"] == models.JobStatusSucceeded {
+		updates["ResultFile"] = jc.file
+	} else if jc.file != "" {
+		os.Remove(jc.file)
+	}
+
+	if err := utils.DB.Model(&models.Job{}).Where("JobID = ?", id).Updates(updates).Error; err != nil {
+		log.Printf("更新任务 %d 状态失败: %v\n", id, err)
+	}
+	cleanupJob(&job)
+}
+
+// 执行任务处理函数，处理函数中的 panic 视为任务失败
+func executeJob(jc *JobContext) (err error) {
+	handler, ok := jobHandlers[jc.Job.JobType]
+	if !ok {
+		return errors.New("未知的任务类型")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("任务 %d 执行异常: %v\n", jc.Job.JobID, r)
+			err = errors.New("任务执行异常")
+		}
+	}()
+	return handler(jc)
+}
+
+// 用户是否可以查看和操作该任务：管理员可以访问所有任务，其他用户只能访问自己提交的任务
+func canAccessJob(job *models.Job, user *models.User) bool {
+	return user.Role == "Admin" || (job.UserID != nil && *job.UserID == user.UserID)
+}
+
+// 获取任务，没有权限时同样返回任务不存在
+func loadJob(id int, user *models.User) (*models.Job, error) {
+	var job models.Job
+	if err := utils.DB.First(&job, id).Error; err != nil || !canAccessJob(&job, user) {
+		return nil, errors.New("任务不存在")
+	}
+	return &job, nil
+}
+
+// 获取任务详情
+func GetJob(id int, user *models.User) (*models.Job, error) {
+	job, err := loadJob(id, user)
+	if err != nil {
+		return nil, err
+	}
+	fillJobView(job)
+	return job, nil
+}
+
+// 获取任务列表，可按状态和类型过滤，非管理员只能看到自己提交的任务
+func GetJobs(status, jobType string, user *models.User) ([]models.Job, error) {
+	var jobs []models.Job
+	query := utils.DB.Order("JobID DESC").Limit(200)
+	if user.Role != "Admin" {
+		query = query.Where("UserID = ?", user.UserID)
+	}
+	if status != "" {
+		query = query.Where("Status = ?", status)
+	}
+	if jobType != "" {
+		query = query.Where("JobType = ?", jobType)
+	}
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, errors.New("获取任务列表失败")
+	}
+	for i := range jobs {
+		fillJobView(&jobs[i])
+	}
+	return jobs, nil
+}
+
+func fillJobView(job *models.Job) {
+	if job.Result != "" {
+		job.ResultData = json.RawMessage(job.Result)
+	}
+	job.HasResultFile = job.ResultFile != ""
+}
+
+// 取消任务：待执行的任务直接标记为已取消，运行中的任务通知处理函数停止
+func CancelJob(id int, user *models.User) error {
+	job, err := loadJob(id, user)
+	if err != nil {
+		return err
+	}
+	if job.IsFinished() {
+		return errors.New("任务已结束，无法取消")
+	}
+
+	now := time.Now()
+	result := utils.DB.Model(&models.Job{}).
+		Where("JobID = ? AND Status = ?", id, models.JobStatusPending).
+		Updates(map[string]interface{}{
+			"Status":     models.JobStatusCanceled,
+			"Error":      "任务已取消",
+			"FinishedAt": &now,
+		})
+	if result.Error != nil {
+		return errors.New("取消任务失败")
+	}
+	if result.RowsAffected > 0 {
+		cleanupJob(job)
+		return nil
+	}
+
+	// 工作协程在抢占任务之前登记取消函数，直到写入最终状态之后才移除，
+	// 因此运行中的任务一定能找到取消函数
+	jobMu.Lock()
+	cancel, ok := jobCancels[id]
+	jobMu.Unlock()
+	if !ok {
+		return errors.New("任务已结束，无法取消")
+	}
+	cancel()
+	return nil
+}
+
+// 获取任务结果文件路径
+func GetJobResultFile(id int, user *models.User) (string, error) {
+	job, err := GetJob(id, user)
+	if err != nil {
+		return "", err
+	}
+	if job.Status != models.JobStatusSucceeded || job.ResultFile == "" {
+		return "", errors.New("任务没有可下载的结果文件")
+	}
+	if _, err := os.Stat(job.ResultFile); err != nil {
+		return "", errors.New("结果文件不存在")
+	}
+	return job.ResultFile, nil
+}
+
+// 保存上传文件供后台任务读取，返回文件路径
+func SaveJobUpload(data []byte, ext string) (string, error) {
+	if err := os.MkdirAll(JobUploadDir, 0o755); err != nil {
+		return "", errors.New("保存上传文件失败")
+	}
+	f, err := os.CreateTemp(JobUploadDir, "upload-*"+ext)
+	if err != nil {
+		return "", errors.New("保存上传文件失败")
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		os.Remove(f.Name())
+		return "", errors.New("保存上传文件失败")
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 各类假期的年度额度（天）
+const (
+	annualLeaveBaseDays = 5  // 年假基础天数，每满一年工龄增加一天
+	annualLeaveMaxDays  = 15 // 年假上限
+	sickLeaveDays       = 10
+	personalLeaveDays   = 3
+)
+
+var leaveTypes = []string{models.LeaveTypeAnnual, models.LeaveTypeSick, models.LeaveTypePersonal}
+
+// 计算员工在指定年度的假期额度：年假按截至年初的工龄计算，
+// 入职当年的各类假期按入职后的剩余天数折算
+func leaveEntitlement(leaveType string, hireDate time.Time, year int) int {
+	hireDate = truncateToDay(hireDate)
+	yearStart := time.Date(year, 1, 1, 0, 0, 0, 0, hireDate.Location())
+	yearEnd := yearStart.AddDate(1, 0, 0)
+	if !hireDate.Before(yearEnd) {
+		return 0
+	}
+
+	var days int
+	switch leaveType {
+	case models.LeaveTypeAnnual:
+		tenure := 0
+		for hireDate.AddDate(tenure+1, 0, 0).Compare(yearStart) <= 0 {
+			tenure++
+		}
+		days = annualLeaveBaseDays + tenure
+		if days > annualLeaveMaxDays {
+			days = annualLeaveMaxDays
+		}
+	case models.LeaveTypeSick:
+		days = sickLeaveDays
+	case models.LeaveTypePersonal:
+		days = personalLeaveDays
+	default:
+		return 0
+	}
+
+	// 入职当年按比例折算，不足一天的部分舍去
+	if hireDate.After(yearStart) {
+		total := yearEnd.Sub(yearStart).Hours() / 24
+		remaining := yearEnd.Sub(hireDate).Hours() / 24
+		days = int(float64(days) * remaining / total)
+	}
+	return days
+}
+
+// 统计起止日期之间（含首尾）的工作日天数
+func countWorkdays(start, end time.Time) int {
+	days := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// 计算员工在指定年度各类假期的额度、已用和审批中的天数，
+// excludeLeaveID 对应的申请不计入（用于审批时重新校验）
+func getLeaveBalances(tx *gorm.DB, emp *models.Employee, year, excludeLeaveID int) ([]models.LeaveBalance, error) {
+	var usage []struct {
+		LeaveType string
+		Status    string
+		Days      int
+	}
+	if err := tx.Model(&models.LeaveRequest{}).
+		Select("LeaveType, Status, SUM(Days) as Days").
+		Where("EmpNo = ? AND YEAR(StartDate) = ? AND Status IN ? AND LeaveID != ?",
+			emp.EmpNo, year, []string{models.LeaveStatusPending, models.LeaveStatusApproved}, excludeLeaveID).
+		Group("LeaveType, Status").
+		Scan(&usage).Error; err != nil {
+		return nil, errors.New("统计假期使用情况失败")
+	}
+
+	balances := make([]models.LeaveBalance, 0, len(leaveTypes))
+	for _, leaveType := range leaveTypes {
+		balance := models.LeaveBalance{
+			LeaveType: leaveType,
+			Entitled:  leaveEntitlement(leaveType, emp.HireDate, year),
+		}
+		for _, u := range usage {
+			if u.LeaveType != leaveType {
+				continue
+			}
+			if u.Status == models.LeaveStatusApproved {
+				balance.Used += u.Days
+			} else {
+				balance.Pending += u.Days
+			}
+		}
+		balance.Remaining = balance.Entitled - balance.Used - balance.Pending
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// 校验请假申请的时间段和额度，leave.LeaveID 为 0 表示新申请。
+// 调用前需用 lockLeaveEmployee 锁定员工，避免并发申请超出余额
+func checkLeaveRequest(tx *gorm.DB, emp *models.Employee, leave *models.LeaveRequest) error {
+	var overlapping int64
+	if err := tx.Model(&models.LeaveRequest{}).
+		Where("EmpNo = ? AND LeaveID != ? AND Status IN ? AND StartDate <= ? AND EndDate >= ?",
+			emp.EmpNo, leave.LeaveID, []string{models.LeaveStatusPending, models.LeaveStatusApproved},
+			leave.EndDate, leave.StartDate).
+		Count(&overlapping).Error; err != nil {
+		return errors.New("检查请假时间失败")
+	}
+	if overlapping > 0 {
+		return errors.New("与已有的请假申请时间重叠")
+	}
+
+	balances, err := getLeaveBalances(tx, emp, leave.StartDate.Year(), leave.LeaveID)
+	if err != nil {
+		return err
+	}
+	for _, b := range balances {
+		if b.LeaveType == leave.LeaveType && leave.Days > b.Remaining {
+			return errors.New("假期余额不足，剩余 " + strconv.Itoa(b.Remaining) + " 天")
+		}
+	}
+	return nil
+}
+
+// 在事务中锁定员工记录，同一员工的请假申请和审批依次执行
+func lockLeaveEmployee(tx *gorm.DB, empNo int) (*models.Employee, error) {
+	var emp models.Employee
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+	return &emp, nil
+}
+
+// 提交请假申请：员工本人、上级或管理员可以提交
+func ApplyLeave(empNo int, req *models.LeaveApplyRequest, user *models.User) (*models.LeaveRequest, error) {
+	validType := false
+	for _, t := range leaveTypes {
+		if req.LeaveType == t {
+			validType = true
+			break
+		}
+	}
+	if !validType {
+		return nil, errors.New("无效的假期类型")
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, errors.New("无效的开始日期格式")
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, errors.New("无效的结束日期格式")
+	}
+	if endDate.Before(startDate) {
+		return nil, errors.New("结束日期不能早于开始日期")
+	}
+	if startDate.Year() != endDate.Year() {
+		return nil, errors.New("请假不能跨年，请按年度分别申请")
+	}
+	if utf8.RuneCountInString(req.Reason) > 200 {
+		return nil, errors.New("请假原因不能超过200个字符")
+	}
+
+	days := countWorkdays(startDate, endDate)
+	if days == 0 {
+		return nil, errors.New("请假时间段内没有工作日")
+	}
+
+	leave := &models.LeaveRequest{
+		EmpNo:     empNo,
+		LeaveType: req.LeaveType,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Days:      days,
+		Reason:    req.Reason,
+		Status:    models.LeaveStatusPending,
+	}
+
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "leave", true); err != nil {
+			return err
+		}
+		emp, err := lockLeaveEmployee(tx, empNo)
+		if err != nil {
+			return err
+		}
+		if emp.EmploymentStatus == models.EmploymentTerminated {
+			return errors.New("该员工已离职")
+		}
+		if dateOnly(startDate) < dateOnly(emp.HireDate) {
+			return errors.New("开始日期不能早于员工入职日期")
+		}
+
+		if err := checkLeaveRequest(tx, emp, leave); err != nil {
+			return err
+		}
+		if err := tx.Create(leave).Error; err != nil {
+			return errors.New("提交请假申请失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leave, nil
+}
+
+// 审批请假申请：管理员或申请人所在部门的在任经理可以审批，不能审批自己的申请
+func ReviewLeave(leaveID int, reviewer *models.User, approve bool, comment string) (*models.LeaveRequest, error) {
+	if utf8.RuneCountInString(comment) > 200 {
+		return nil, errors.New("审批意见不能超过200个字符")
+	}
+
+	var leave models.LeaveRequest
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&leave, leaveID).Error; err != nil {
+			return errors.New("请假申请不存在")
+		}
+		if leave.Status != models.LeaveStatusPending {
+			return errors.New("该申请已处理")
+		}
+		if reviewer.EmpNo != nil && *reviewer.EmpNo == leave.EmpNo {
+			return errors.New("不能审批自己的请假申请")
+		}
+		canReview, err := canReviewLeave(tx, reviewer, leave.EmpNo)
+		if err != nil {
+			return err
+		}
+		if !canReview {
+			return errors.New("只有管理员或部门经理可以审批")
+		}
+
+		status := models.LeaveStatusRejected
+		if approve {
+			emp, err := lockLeaveEmployee(tx, leave.EmpNo)
+			if err != nil {
+				return err
+			}
+			if err := checkLeaveRequest(tx, emp, &leave); err != nil {
+				return err
+			}
+			status = models.LeaveStatusApproved
+		}
+
+		now := time.Now()
+		leave.Status = status
+		leave.ReviewerUserID = &reviewer.UserID
+		leave.ReviewComment = comment
+		leave.ReviewedAt = &now
+		if err := tx.Save(&leave).Error; err != nil {
+			return errors.New("审批请假申请失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+// 是否可以审批该员工的请假申请
+func canReviewLeave(tx *gorm.DB, reviewer *models.User, empNo int) (bool, error) {
+	if reviewer.Role == "Admin" {
+		return true, nil
+	}
+	if reviewer.EmpNo == nil {
+		return false, nil
+	}
+	return isDepartmentManagerOf(tx, *reviewer.EmpNo, empNo)
+}
+
+// managerEmpNo 是否为该员工所在部门的在任经理
+func isDepartmentManagerOf(tx *gorm.DB, managerEmpNo, empNo int) (bool, error) {
+	today := truncateToDay(time.Now())
+	var count int64
+	if err := tx.Model(&models.DepartmentManager{}).
+		Joins("INNER JOIN Employee_Department ed ON ed.DeptNo = Department_Managers.DeptNo AND ed.EdStatus = 1").
+		Where("Department_Managers.EmpNo = ? AND ed.EmpNo = ?", managerEmpNo, empNo).
+		Where("Department_Managers.StartDate <= ? AND (Department_Managers.EndDate IS NULL OR Department_Managers.EndDate >= ?)", today, today).
+		Count(&count).Error; err != nil {
+		return false, errors.New("检查部门经理失败")
+	}
+	return count > 0, nil
+}
+
+// 撤销请假申请：申请人本人或有审批权限的用户可以撤销审批中或已批准的申请
+func CancelLeave(leaveID int, user *models.User) error {
+	return utils.DB.Transaction(func(tx *gorm.DB) error {
+		var leave models.LeaveRequest
+		if err := tx.First(&leave, leaveID).Error; err != nil {
+			return errors.New("请假申请不存在")
+		}
+		if leave.Status != models.LeaveStatusPending && leave.Status != models.LeaveStatusApproved {
+			return errors.New("该申请不能撤销")
+		}
+		isOwner := user.EmpNo != nil && *user.EmpNo == leave.EmpNo
+		if !isOwner {
+			canReview, err := canReviewLeave(tx, user, leave.EmpNo)
+			if err != nil {
+				return err
+			}
+			if !canReview {
+				return errors.New("没有撤销该申请的权限")
+			}
+		}
+		return tx.Model(&leave).Update("Status", models.LeaveStatusCanceled).Error
+	})
+}
+
+// 撤销员工所有审批中的请假申请，用于员工离职
+func cancelPendingLeaves(tx *gorm.DB, empNo int) error {
+	return tx.Model(&models.LeaveRequest{}).
+		Where("EmpNo = ? AND Status = ?", empNo, models.LeaveStatusPending).
+		Update("Status", models.LeaveStatusCanceled).Error
+}
+
+// 获取员工指定年度的假期余额和请假记录：员工本人、上级或管理员可以查看
+func GetEmployeeLeave(empNo, year int, user *models.User) (*models.EmployeeLeaveSummary, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "leave", false); err != nil {
+		return nil, err
+	}
+	var emp models.Employee
+	if err := utils.DB.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+
+	balances, err := getLeaveBalances(utils.DB, &emp, year, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	history := []models.LeaveRequest{}
+	if err := utils.DB.Where("EmpNo = ?", empNo).
+		Order("StartDate DESC").
+		Find(&history).Error; err != nil {
+		return nil, errors.New("获取请假记录失败")
+	}
+
+	return &models.EmployeeLeaveSummary{
+		EmpNo:    empNo,
+		Year:     year,
+		Balances: balances,
+		History:  history,
+	}, nil
+}
+
+// 查询请假申请，status 为空时返回全部；
+// 管理员可以看到所有申请，其他用户只能看到本人和下属员工的申请
+func GetLeaveRequests(status string, user *models.User) ([]models.LeaveRequestDetail, error) {
+	allowed, err := accessibleProfiles(utils.DB, user, "leave")
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := []models.LeaveRequestDetail{}
+	query := utils.DB.Table("Leave_Requests l").
+		Select("l.*, CONCAT(e.LastName, e.FirstName) as EmployeeName").
+		Joins("INNER JOIN Employees e ON l.EmpNo = e.EmpNo")
+	if allowed != nil {
+		if len(allowed) == 0 {
+			return leaves, nil
+		}
+		empNos := make([]int, 0, len(allowed))
+		for empNo := range allowed {
+			empNos = append(empNos, empNo)
+		}
+		query = query.Where("l.EmpNo IN ?", empNos)
+	}
+	if status != "" {
+		query = query.Where("l.Status = ?", status)
+	}
+
+	if err := query.Order("l.StartDate, l.LeaveID").Scan(&leaves).Error; err != nil {
+		return nil, errors.New("获取请假申请失败")
+	}
+	return leaves, nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 获取部门经理任命记录（按任命日期倒序）
+func GetDepartmentManagers(deptNo int) ([]models.DepartmentManager, error) {
+	var dept models.Department
+	if err := utils.DB.First(&dept, deptNo).Error; err != nil {
+		return nil, errors.New("部门不存在")
+	}
+
+	var managers []models.DepartmentManager
+	err := utils.DB.Preload("Employee").
+		Where("DeptNo = ?", deptNo).
+		Order("StartDate DESC").
+		Find(&managers).Error
+	if err != nil {
+		return nil, errors.New("获取部门经理失败")
+	}
+	return managers, nil
+}
+
+// 获取各部门当前在任的经理，键为部门编号
+func GetCurrentDepartmentManagers() (map[int]models.DepartmentManager, error) {
+	today := truncateToDay(time.Now())
+
+	var managers []models.DepartmentManager
+	err := utils.DB.Preload("Employee").
+		Where("StartDate <= ? AND (EndDate IS NULL OR EndDate >= ?)", today, today).
+		Order("StartDate").
+		Find(&managers).Error
+	if err != nil {
+		return nil, errors.New("获取部门经理失败")
+	}
+
+	result := make(map[int]models.DepartmentManager, len(managers))
+	for _, m := range managers {
+		result[m.DeptNo] = m
+	}
+	return result, nil
+}
+
+// 任命部门经理：经理必须是该部门的在职成员；
+// 新任命生效前一天自动结束上一任经理的任期
+func AssignDepartmentManager(deptNo int, req *models.DepartmentManagerRequest) (*models.DepartmentManager, error) {
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, errors.New("无效的任命日期格式")
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" && req.EndDate != "null" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return nil, errors.New("无效的离任日期格式")
+		}
+		if parsed.Before(startDate) {
+			return nil, errors.New("离任日期不能早于任命日期")
+		}
+		endDate = &parsed
+	}
+
+	manager := &models.DepartmentManager{
+		DeptNo:    deptNo,
+		EmpNo:     req.EmpNo,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		var dept models.Department
+		if err := tx.First(&dept, deptNo).Error; err != nil {
+			return errors.New("部门不存在")
+		}
+
+		var emp models.Employee
+		if err := tx.First(&emp, req.EmpNo).Error; err != nil {
+			return errors.New("员工不存在")
+		}
+
+		// 经理必须是该部门的在职成员
+		var relation models.EmployeeDepartment
+		if err := tx.Where("EmpNo = ? AND DeptNo = ? AND EdStatus = 1", req.EmpNo, deptNo).
+			First(&relation).Error; err != nil {
+			return errors.New("该员工不是此部门的在职成员")
+		}
+		if relation.EdEntryDate.After(startDate) {
+			return errors.New("任命日期不能早于该员工加入部门的日期")
+		}
+
+		// 任命日期之后已有的任命说明时间段重叠
+		var later int64
+		if err := tx.Model(&models.DepartmentManager{}).
+			Where("DeptNo = ? AND StartDate >= ?", deptNo, startDate).
+			Count(&later).Error; err != nil {
+			return errors.New("获取经理任命记录失败")
+		}
+		if later > 0 {
+			return errors.New("该日期之后已有经理任命记录")
+		}
+
+		// 结束上一任经理的任期
+		previousEnd := startDate.AddDate(0, 0, -1)
+		if err := tx.Model(&models.DepartmentManager{}).
+			Where("DeptNo = ? AND StartDate < ? AND (EndDate IS NULL OR EndDate >= ?)", deptNo, startDate, startDate).
+			Update("EndDate", previousEnd).Error; err != nil {
+			return errors.New("结束上一任经理任期失败")
+		}
+
+		if err := tx.Create(manager).Error; err != nil {
+			return errors.New("任命部门经理失败")
+		}
+		manager.Employee = emp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manager, nil
+}
+
+// 结束员工在指定部门（deptNo 为 0 时为所有部门）的经理任期，
+// 用于员工离开部门或离职时
+func endManagerAssignments(tx *gorm.DB, empNo, deptNo int, endDate time.Time) error {
+	query := tx.Model(&models.DepartmentManager{}).
+		Where("EmpNo = ? AND (EndDate IS NULL OR EndDate > ?)", empNo, endDate)
+	if deptNo != 0 {
+		query = query.Where("DeptNo = ?", deptNo)
+	}
+	return query.Update("EndDate", endDate).Error
+}
+
+// 设置员工的直属上级，managerEmpNo 为空表示取消
+func SetEmployeeManager(empNo int, managerEmpNo *int) error {
+	return utils.DB.Transaction(func(tx *gorm.DB) error {
+		var emp models.Employee
+		if err := tx.First(&emp, empNo).Error; err != nil {
+			return errors.New("员工不存在")
+		}
+
+		if managerEmpNo != nil {
+			if err := validateEmployeeManager(tx, empNo, *managerEmpNo); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&emp).Update("ManagerEmpNo", managerEmpNo).Error
+	})
+}
+
+// 校验直属上级：必须存在且在职，且不能形成循环汇报关系。
+// empNo 为 0 表示新员工，只需校验上级本身
+func validateEmployeeManager(tx *gorm.DB, empNo, managerEmpNo int) error {
+	if managerEmpNo == empNo {
+		return errors.New("不能将员工设置为自己的上级")
+	}
+
+	var manager models.Employee
+	if err := tx.First(&manager, managerEmpNo).Error; err != nil {
+		return errors.New("上级员工不存在")
+	}
+
+	var active int64
+	if err := tx.Model(&models.EmployeeDepartment{}).
+		Where("EmpNo = ? AND EdStatus = 1", managerEmpNo).
+		Count(&active).Error; err != nil {
+		return errors.New("获取上级员工部门关系失败")
+	}
+	if active == 0 {
+		return errors.New("上级员工没有在职的部门关系")
+	}
+
+	if empNo == 0 {
+		return nil
+	}
+
+	// 沿上级链向上查找，遇到当前员工说明会形成循环
+	visited := map[int]bool{}
+	current := manager.ManagerEmpNo
+	for current != nil {
+		if *current == empNo {
+			return errors.New("不能将下属设置为上级")
+		}
+		if visited[*current] {
+			break
+		}
+		visited[*current] = true
+
+		var next models.Employee
+		if err := tx.Select("EmpNo", "ManagerEmpNo").First(&next, *current).Error; err != nil {
+			break
+		}
+		current = next.ManagerEmpNo
+	}
+	return nil
+}
+
+// 获取员工的下属，transitive 为 true 时包含所有间接下属
+func GetEmployeeReports(empNo int, transitive bool) ([]models.EmployeeReport, error) {
+	var emp models.Employee
+	if err := utils.DB.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+
+	reports := []models.EmployeeReport{}
+	visited := map[int]bool{empNo: true}
+	level := []int{empNo}
+	for depth := 1; len(level) > 0; depth++ {
+		var employees []models.Employee
+		if err := utils.DB.Where("ManagerEmpNo IN ?", level).
+			Order("EmpNo").
+			Find(&employees).Error; err != nil {
+			return nil, errors.New("获取下属信息失败")
+		}
+
+		var next []int
+		for _, e := range employees {
+			if visited[e.EmpNo] {
+				continue
+			}
+			visited[e.EmpNo] = true
+			next = append(next, e.EmpNo)
+			reports = append(reports, models.EmployeeReport{
+				EmpNo:        e.EmpNo,
+				EmployeeName: e.LastName + e.FirstName,
+				ManagerEmpNo: *e.ManagerEmpNo,
+				Level:        depth,
+			})
+		}
+
+		if !transitive {
+			break
+		}
+		level = next
+	}
+
+	return reports, nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
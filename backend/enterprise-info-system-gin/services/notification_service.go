@@ -0,0 +1,70 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 创建通知，DedupKey 相同的通知只创建一次（依赖唯一索引），返回是否新建
+func createNotification(n *models.Notification) (bool, error) {
+	if err := utils.DB.Create(n).Error; err != nil {
+		if n.DedupKey != nil && errors.Is(err, gorm.ErrDuplicatedKey) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// 发给用户本人或其角色的通知，ReadAt 为该用户的阅读时间
+func userNotificationQuery(user *models.User) *gorm.DB {
+	return utils.DB.Table("Notifications n").
+		Joins("LEFT JOIN NotificationReads r ON r.NotificationID = n.NotificationID AND r.UserID = ?", user.UserID).
+		Where("n.UserID = ? OR (n.UserID IS NULL AND n.Role = ?)", user.UserID, user.Role)
+}
+
+// 获取当前用户的通知，unreadOnly 为 true 时只返回未读通知
+func GetNotifications(user *models.User, unreadOnly bool) ([]models.Notification, error) {
+	query := userNotificationQuery(user).
+		Select("n.NotificationID, n.UserID, n.Role, n.EmpNo, n.Category, n.Title, n.Content, n.CreatedAt, r.ReadAt")
+	if unreadOnly {
+		query = query.Where("r.NotificationID IS NULL")
+	}
+
+	notifications := []models.Notification{}
+	if err := query.Order("n.CreatedAt DESC").Limit(200).Scan(&notifications).Error; err != nil {
+		return nil, errors.New("获取通知失败")
+	}
+	return notifications, nil
+}
+
+// 将通知标记为已读，id 为 0 时标记当前用户的所有通知；
+// 只记录当前用户的已读状态，同一角色的其他用户不受影响
+func MarkNotificationsRead(user *models.User, id int) error {
+	query := userNotificationQuery(user).Where("r.NotificationID IS NULL")
+	if id != 0 {
+		query = query.Where("n.NotificationID = ?", id)
+	}
+	var ids []int
+	if err := query.Pluck("n.NotificationID", &ids).Error; err != nil {
+		return errors.New("更新通知状态失败")
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	reads := make([]models.NotificationRead, len(ids))
+	for i, notificationID := range ids {
+		reads[i] = models.NotificationRead{NotificationID: notificationID, UserID: user.UserID, ReadAt: now}
+	}
+	if err := utils.DB.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(reads, 500).Error; err != nil {
+		return errors.New("更新通知状态失败")
+	}
+	return nil
+}
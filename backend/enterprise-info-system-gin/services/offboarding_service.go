@@ -0,0 +1,112 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 员工离职：在同一事务中结束所有在职部门关系和经理任期、
+// 将员工标记为离职并停用关联的用户账号，员工及其历史记录保留用于统计
+func TerminateEmployee(empNo int, req *models.EmployeeTerminationRequest) (*models.EmployeeTerminationResult, error) {
+	var result *models.EmployeeTerminationResult
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = terminateEmployee(tx, empNo, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// 在事务中办理员工离职，供需要与其他修改在同一事务中完成的调用方使用
+func terminateEmployee(tx *gorm.DB, empNo int, req *models.EmployeeTerminationRequest) (*models.EmployeeTerminationResult, error) {
+	terminationDate, err := time.Parse("2006-01-02", req.TerminationDate)
+	if err != nil {
+		return nil, errors.New("无效的离职日期格式")
+	}
+	if utf8.RuneCountInString(req.Reason) > 200 {
+		return nil, errors.New("离职原因不能超过200个字符")
+	}
+
+	result := &models.EmployeeTerminationResult{}
+
+	var emp models.Employee
+	if err := tx.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+	if emp.EmploymentStatus == models.EmploymentTerminated {
+		return nil, errors.New("该员工已离职")
+	}
+	if dateOnly(terminationDate) < dateOnly(emp.HireDate) {
+		return nil, errors.New("离职日期不能早于入职日期")
+	}
+
+	// 结束所有在职的部门关系
+	var relations []models.EmployeeDepartment
+	if err := tx.Where("EmpNo = ? AND EdStatus = 1", empNo).Find(&relations).Error; err != nil {
+		return nil, errors.New("获取员工部门关系失败")
+	}
+	for _, ed := range relations {
+		if dateOnly(terminationDate) < dateOnly(ed.EdEntryDate) {
+			return nil, errors.New("离职日期不能早于员工加入部门的日期")
+		}
+	}
+	for _, ed := range relations {
+		if err := tx.Model(&ed).Updates(map[string]interface{}{
+			"EdStatus":    2,
+			"EdLeaveDate": terminationDate,
+		}).Error; err != nil {
+			return nil, errors.New("结束部门关系失败")
+		}
+	}
+	result.ClosedRelations = len(relations)
+
+	if err := endManagerAssignments(tx, empNo, 0, terminationDate); err != nil {
+		return nil, errors.New("结束部门经理任期失败")
+	}
+
+	if err := cancelOnboardingTasks(tx, empNo); err != nil {
+		return nil, errors.New("取消入职任务失败")
+	}
+	if err := cancelPendingLeaves(tx, empNo); err != nil {
+		return nil, errors.New("撤销请假申请失败")
+	}
+
+	// 下属的直属上级改为离职员工的上级
+	reassigned := tx.Model(&models.Employee{}).
+		Where("ManagerEmpNo = ?", empNo).
+		Update("ManagerEmpNo", emp.ManagerEmpNo)
+	if reassigned.Error != nil {
+		return nil, errors.New("更新下属员工失败")
+	}
+	result.ReassignedReports = int(reassigned.RowsAffected)
+
+	// 停用关联的用户账号
+	revoked := tx.Model(&models.User{}).
+		Where("EmpNo = ? AND Disabled = ?", empNo, false).
+		Update("Disabled", true)
+	if revoked.Error != nil {
+		return nil, errors.New("停用用户账号失败")
+	}
+	result.RevokedUserAccounts = int(revoked.RowsAffected)
+
+	if err := tx.Model(&emp).Updates(map[string]interface{}{
+		"EmploymentStatus":  models.EmploymentTerminated,
+		"TerminationDate":   terminationDate,
+		"TerminationReason": req.Reason,
+	}).Error; err != nil {
+		return nil, errors.New("更新员工状态失败")
+	}
+	emp.EmploymentStatus = models.EmploymentTerminated
+	emp.TerminationDate = &terminationDate
+	emp.TerminationReason = req.Reason
+	result.Employee = emp
+	return result, nil
+}
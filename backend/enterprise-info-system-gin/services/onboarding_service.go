@@ -0,0 +1,270 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 获取入职任务模板，按适用部门和顺序排列
+func GetOnboardingTemplates() ([]models.OnboardingTemplate, error) {
+	var templates []models.OnboardingTemplate
+	err := utils.DB.Order("DeptNo IS NOT NULL, DeptNo, SortOrder, TemplateID").Find(&templates).Error
+	if err != nil {
+		return nil, errors.New("获取入职任务模板失败")
+	}
+	return templates, nil
+}
+
+// 创建入职任务模板
+func CreateOnboardingTemplate(req *models.OnboardingTemplateRequest) (*models.OnboardingTemplate, error) {
+	template := &models.OnboardingTemplate{Active: true}
+	if err := applyOnboardingTemplateRequest(template, req); err != nil {
+		return nil, err
+	}
+	if err := utils.DB.Create(template).Error; err != nil {
+		return nil, errors.New("创建入职任务模板失败")
+	}
+	return template, nil
+}
+
+// 更新入职任务模板，已生成的任务不受影响
+func UpdateOnboardingTemplate(id int, req *models.OnboardingTemplateRequest) (*models.OnboardingTemplate, error) {
+	var template models.OnboardingTemplate
+	if err := utils.DB.First(&template, id).Error; err != nil {
+		return nil, errors.New("入职任务模板不存在")
+	}
+	if err := applyOnboardingTemplateRequest(&template, req); err != nil {
+		return nil, err
+	}
+	if err := utils.DB.Save(&template).Error; err != nil {
+		return nil, errors.New("更新入职任务模板失败")
+	}
+	return &template, nil
+}
+
+// 删除入职任务模板，已生成的任务保留
+func DeleteOnboardingTemplate(id int) error {
+	result := utils.DB.Delete(&models.OnboardingTemplate{}, id)
+	if result.Error != nil {
+		return errors.New("删除入职任务模板失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("入职任务模板不存在")
+	}
+	return nil
+}
+
+// 校验模板请求并写入模板
+func applyOnboardingTemplateRequest(template *models.OnboardingTemplate, req *models.OnboardingTemplateRequest) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return errors.New("任务名称不能为空")
+	}
+	if utf8.RuneCountInString(title) > 100 {
+		return errors.New("任务名称不能超过100个字符")
+	}
+	if utf8.RuneCountInString(req.Description) > 500 {
+		return errors.New("任务说明不能超过500个字符")
+	}
+
+	if req.DeptNo != nil {
+		var dept models.Department
+		if err := utils.DB.First(&dept, *req.DeptNo).Error; err != nil {
+			return errors.New("部门不存在")
+		}
+	}
+
+	assigneeType := req.AssigneeType
+	if assigneeType == "" {
+		assigneeType = models.OnboardingAssigneeManager
+	}
+	var assigneeEmpNo *int
+	switch assigneeType {
+	case models.OnboardingAssigneeFixed:
+		if req.AssigneeEmpNo == nil {
+			return errors.New("请指定任务负责人")
+		}
+		var assignee models.Employee
+		if err := utils.DB.First(&assignee, *req.AssigneeEmpNo).Error; err != nil {
+			return errors.New("任务负责人不存在")
+		}
+		assigneeEmpNo = req.AssigneeEmpNo
+	case models.OnboardingAssigneeManager, models.OnboardingAssigneeSelf:
+	default:
+		return errors.New("无效的负责人类型")
+	}
+
+	template.Title = title
+	template.Description = req.Description
+	template.DeptNo = req.DeptNo
+	template.AssigneeType = assigneeType
+	template.AssigneeEmpNo = assigneeEmpNo
+	template.DueOffsetDays = req.DueOffsetDays
+	template.SortOrder = req.SortOrder
+	if req.Active != nil {
+		template.Active = *req.Active
+	}
+	return nil
+}
+
+// 按模板为员工生成入职任务：deptNo 为空时使用通用模板，
+// 否则使用该部门的模板；已由同一模板生成过的任务不再重复生成
+func createOnboardingTasks(tx *gorm.DB, emp *models.Employee, deptNo *int) error {
+	query := tx.Where("Active = ?", true)
+	if deptNo == nil {
+		query = query.Where("DeptNo IS NULL")
+	} else {
+		query = query.Where("DeptNo = ?", *deptNo)
+	}
+	var templates []models.OnboardingTemplate
+	if err := query.Order("SortOrder, TemplateID").Find(&templates).Error; err != nil {
+		return err
+	}
+	if len(templates) == 0 {
+		return nil
+	}
+
+	var existing []int
+	if err := tx.Model(&models.OnboardingTask{}).
+		Where("EmpNo = ? AND TemplateID IS NOT NULL", emp.EmpNo).
+		Pluck("TemplateID", &existing).Error; err != nil {
+		return err
+	}
+	instantiated := make(map[int]bool, len(existing))
+	for _, id := range existing {
+		instantiated[id] = true
+	}
+
+	manager := emp.ManagerEmpNo
+	if deptNo != nil {
+		today := truncateToDay(time.Now())
+		var current models.DepartmentManager
+		if err := tx.Where("DeptNo = ? AND StartDate <= ? AND (EndDate IS NULL OR EndDate >= ?)", *deptNo, today, today).
+			Order("StartDate DESC").
+			First(&current).Error; err == nil && current.EmpNo != emp.EmpNo {
+			manager = &current.EmpNo
+		}
+	}
+
+	hireDate := truncateToDay(emp.HireDate)
+	var tasks []models.OnboardingTask
+	for _, t := range templates {
+		if instantiated[t.TemplateID] {
+			continue
+		}
+		templateID := t.TemplateID
+		task := models.OnboardingTask{
+			EmpNo:       emp.EmpNo,
+			TemplateID:  &templateID,
+			DeptNo:      t.DeptNo,
+			Title:       t.Title,
+			Description: t.Description,
+			DueDate:     hireDate.AddDate(0, 0, t.DueOffsetDays),
+			Status:      models.OnboardingTaskOpen,
+		}
+		switch t.AssigneeType {
+		case models.OnboardingAssigneeFixed:
+			task.AssigneeEmpNo = t.AssigneeEmpNo
+		case models.OnboardingAssigneeSelf:
+			empNo := emp.EmpNo
+			task.AssigneeEmpNo = &empNo
+		default:
+			task.AssigneeEmpNo = manager
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+	return tx.Create(&tasks).Error
+}
+
+// 取消员工所有未完成的入职任务，用于员工离职
+func cancelOnboardingTasks(tx *gorm.DB, empNo int) error {
+	return tx.Model(&models.OnboardingTask{}).
+		Where("EmpNo = ? AND Status = ?", empNo, models.OnboardingTaskOpen).
+		Update("Status", models.OnboardingTaskCanceled).Error
+}
+
+// 查询入职任务，status、empNo、assigneeEmpNo 为空值时不过滤
+func GetOnboardingTasks(status string, empNo, assigneeEmpNo int) ([]models.OnboardingTaskDetail, error) {
+	query := utils.DB.Table("Onboarding_Tasks t").
+		Select(`t.*, CONCAT(e.LastName, e.FirstName) as EmployeeName,
+			CONCAT(a.LastName, a.FirstName) as AssigneeName, d.DeptName`).
+		Joins("INNER JOIN Employees e ON t.EmpNo = e.EmpNo").
+		Joins("LEFT JOIN Employees a ON t.AssigneeEmpNo = a.EmpNo").
+		Joins("LEFT JOIN Departments d ON t.DeptNo = d.DeptNo")
+	if status != "" {
+		query = query.Where("t.Status = ?", status)
+	}
+	if empNo != 0 {
+		query = query.Where("t.EmpNo = ?", empNo)
+	}
+	if assigneeEmpNo != 0 {
+		query = query.Where("t.AssigneeEmpNo = ?", assigneeEmpNo)
+	}
+
+	var tasks []models.OnboardingTaskDetail
+	if err := query.Order("t.DueDate, t.TaskID").Scan(&tasks).Error; err != nil {
+		return nil, errors.New("获取入职任务失败")
+	}
+
+	today := truncateToDay(time.Now())
+	for i := range tasks {
+		tasks[i].Overdue = tasks[i].Status == models.OnboardingTaskOpen && tasks[i].DueDate.Before(today)
+	}
+	return tasks, nil
+}
+
+// 更新入职任务的状态、负责人或到期日：管理员、员工本人或上级可以更新
+func UpdateOnboardingTask(id int, req *models.OnboardingTaskUpdateRequest, user *models.User) (*models.OnboardingTask, error) {
+	var task models.OnboardingTask
+	if err := utils.DB.First(&task, id).Error; err != nil {
+		return nil, errors.New("入职任务不存在")
+	}
+	if err := checkProfileAccess(utils.DB, user, task.EmpNo, "onboarding", true); err != nil {
+		return nil, err
+	}
+
+	if req.AssigneeEmpNo != nil {
+		var assignee models.Employee
+		if err := utils.DB.First(&assignee, *req.AssigneeEmpNo).Error; err != nil {
+			return nil, errors.New("任务负责人不存在")
+		}
+		task.AssigneeEmpNo = req.AssigneeEmpNo
+	}
+
+	if req.DueDate != "" {
+		dueDate, err := time.Parse("2006-01-02", req.DueDate)
+		if err != nil {
+			return nil, errors.New("无效的到期日期格式")
+		}
+		task.DueDate = dueDate
+	}
+
+	switch req.Status {
+	case "":
+	case models.OnboardingTaskCompleted:
+		if task.Status != models.OnboardingTaskCompleted {
+			now := time.Now()
+			task.CompletedAt = &now
+		}
+		task.Status = req.Status
+	case models.OnboardingTaskOpen, models.OnboardingTaskCanceled:
+		task.Status = req.Status
+		task.CompletedAt = nil
+	default:
+		return nil, errors.New("无效的任务状态")
+	}
+
+	if err := utils.DB.Save(&task).Error; err != nil {
+		return nil, errors.New("更新入职任务失败")
+	}
+	return &task, nil
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 组织架构图的输出格式
+const (
+	OrgChartJSON = "json"
+	OrgChartDOT  = "dot"
+	OrgChartSVG  = "svg"
+)
+
+// 构建组织架构图：rootDeptNo 为 0 时从所有顶层部门开始，
+// depth 为 0 表示不限层级，includeEmployees 为 false 时只显示经理
+func BuildOrgChart(rootDeptNo, depth int, includeEmployees bool) ([]*models.OrgChartNode, error) {
+	deptNodes, roots, err := loadDepartmentNodes()
+	if err != nil {
+		return nil, err
+	}
+	if rootDeptNo != 0 {
+		node, ok := deptNodes[rootDeptNo]
+		if !ok {
+			return nil, errors.New("部门不存在")
+		}
+		roots = []*models.DepartmentNode{node}
+	}
+
+	managers, err := GetCurrentDepartmentManagers()
+	if err != nil {
+		return nil, err
+	}
+
+	var members []struct {
+		DeptNo       int
+		EmpNo        int
+		EmployeeName string
+	}
+	if err := utils.DB.Raw(`
+		SELECT DISTINCT ed.DeptNo, e.EmpNo, CONCAT(e.LastName, e.FirstName) as EmployeeName
+		FROM Employee_Department ed
+		INNER JOIN Employees e ON ed.EmpNo = e.EmpNo
+		WHERE ed.EdStatus = 1
+		ORDER BY e.EmpNo
+	`).Scan(&members).Error; err != nil {
+		return nil, errors.New("获取部门员工失败")
+	}
+	employees := make(map[int][]models.OrgChartEmployee)
+	for _, m := range members {
+		employees[m.DeptNo] = append(employees[m.DeptNo], models.OrgChartEmployee{
+			EmpNo:        m.EmpNo,
+			EmployeeName: m.EmployeeName,
+		})
+	}
+
+	// 上下级关系出现循环时已访问的部门不再展开
+	visited := make(map[int]bool)
+	var build func(node *models.DepartmentNode, level int) *models.OrgChartNode
+	build = func(node *models.DepartmentNode, level int) *models.OrgChartNode {
+		visited[node.DeptNo] = true
+		chart := &models.OrgChartNode{
+			DeptNo:        node.DeptNo,
+			DeptName:      node.DeptName,
+			Employees:     []models.OrgChartEmployee{},
+			EmployeeCount: len(employees[node.DeptNo]),
+			Children:      []*models.OrgChartNode{},
+		}
+		if m, ok := managers[node.DeptNo]; ok {
+			chart.Manager = &models.OrgChartEmployee{
+				EmpNo:        m.EmpNo,
+				EmployeeName: m.Employee.LastName + m.Employee.FirstName,
+			}
+		}
+		if includeEmployees {
+			for _, e := range employees[node.DeptNo] {
+				if chart.Manager != nil && e.EmpNo == chart.Manager.EmpNo {
+					continue
+				}
+				chart.Employees = append(chart.Employees, e)
+			}
+		}
+		if depth == 0 || level < depth {
+			for _, child := range node.Children {
+				if !visited[child.DeptNo] {
+					chart.Children = append(chart.Children, build(child, level+1))
+				}
+			}
+		}
+		return chart
+	}
+
+	charts := make([]*models.OrgChartNode, 0, len(roots))
+	for _, root := range roots {
+		charts = append(charts, build(root, 1))
+	}
+	return charts, nil
+}
+
+// 生成 Graphviz DOT 格式的组织架构图
+func RenderOrgChartDOT(charts []*models.OrgChartNode) string {
+	var sb strings.Builder
+	sb.WriteString("digraph orgchart {\n")
+	sb.WriteString("  rankdir=TB;\n")
+	sb.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=\"#f8fafc\", fontname=\"Microsoft YaHei\"];\n")
+	sb.WriteString("  edge [arrowhead=none];\n")
+
+	var walk func(node *models.OrgChartNode)
+	walk = func(node *models.OrgChartNode) {
+		fmt.Fprintf(&sb, "  dept%d [label=%s];\n", node.DeptNo, dotQuote(strings.Join(orgChartLabelLines(node), "\n")))
+		for _, child := range node.Children {
+			walk(child)
+			fmt.Fprintf(&sb, "  dept%d -> dept%d;\n", node.DeptNo, child.DeptNo)
+		}
+	}
+	for _, chart := range charts {
+		walk(chart)
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// 节点中显示的文本：部门名称、经理和员工
+func orgChartLabelLines(node *models.OrgChartNode) []string {
+	lines := []string{fmt.Sprintf("%s（%d人）", node.DeptName, node.EmployeeCount)}
+	if node.Manager != nil {
+		lines = append(lines, "经理："+node.Manager.EmployeeName)
+	}
+	const maxListed = 8
+	for i, e := range node.Employees {
+		if i == maxListed {
+			lines = append(lines, fmt.Sprintf("另有 %d 人", len(node.Employees)-maxListed))
+			break
+		}
+		lines = append(lines, e.EmployeeName)
+	}
+	return lines
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// SVG 排版参数
+const (
+	orgBoxWidth   = 180.0
+	orgLineHeight = 18.0
+	orgBoxPadding = 10.0
+	orgHGap       = 24.0
+	orgVGap       = 48.0
+	orgMargin     = 20.0
+)
+
+// 排版后的节点位置
+type orgLayoutNode struct {
+	lines    []string
+	x, y     float64
+	height   float64
+	children []*orgLayoutNode
+}
+
+// 在进程内生成 SVG 组织架构图：子树自左向右排列，上级居中于下级之上
+func RenderOrgChartSVG(charts []*models.OrgChartNode) []byte {
+	var roots []*orgLayoutNode
+	for _, chart := range charts {
+		roots = append(roots, newOrgLayoutNode(chart))
+	}
+
+	// 每一层的高度取该层最高的节点
+	var levelHeights []float64
+	var measure func(n *orgLayoutNode, level int)
+	measure = func(n *orgLayoutNode, level int) {
+		if len(levelHeights) <= level {
+			levelHeights = append(levelHeights, 0)
+		}
+		if n.height > levelHeights[level] {
+			levelHeights[level] = n.height
+		}
+		for _, c := range n.children {
+			measure(c, level+1)
+		}
+	}
+	for _, r := range roots {
+		measure(r, 0)
+	}
+	levelY := make([]float64, len(levelHeights))
+	y := orgMargin
+	for i, h := range levelHeights {
+		levelY[i] = y
+		y += h + orgVGap
+	}
+
+	// 叶子节点依次向右排列，父节点位于子节点中间
+	nextX := orgMargin
+	var place func(n *orgLayoutNode, level int)
+	place = func(n *orgLayoutNode, level int) {
+		n.y = levelY[level]
+		if len(n.children) == 0 {
+			n.x = nextX
+			nextX += orgBoxWidth + orgHGap
+			return
+		}
+		for _, c := range n.children {
+			place(c, level+1)
+		}
+		first, last := n.children[0], n.children[len(n.children)-1]
+		n.x = (first.x + last.x) / 2
+	}
+	for _, r := range roots {
+		place(r, 0)
+	}
+
+	width := nextX - orgHGap + orgMargin
+	if width < orgBoxWidth+2*orgMargin {
+		width = orgBoxWidth + 2*orgMargin
+	}
+	height := y - orgVGap + orgMargin
+	if len(levelHeights) == 0 {
+		height = 2 * orgMargin
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Microsoft YaHei, PingFang SC, Noto Sans CJK SC, sans-serif" font-size="12">
+<rect width="100%%" height="100%%" fill="#ffffff"/>
+`, width, height, width, height)
+
+	var draw func(n *orgLayoutNode)
+	draw = func(n *orgLayoutNode) {
+		// 连接线：从父节点底部向下，再水平连到各子节点
+		for _, c := range n.children {
+			parentX := n.x + orgBoxWidth/2
+			childX := c.x + orgBoxWidth/2
+			midY := c.y - orgVGap/2
+			fmt.Fprintf(&buf, `<path d="M%.1f %.1f V%.1f H%.1f V%.1f" fill="none" stroke="#94a3b8" stroke-width="1"/>`+"\n",
+				parentX, n.y+n.height, midY, childX, c.y)
+			draw(c)
+		}
+
+		fmt.Fprintf(&buf, `<g><rect x="%.1f" y="%.1f" width="%.0f" height="%.1f" rx="6" fill="#f8fafc" stroke="#334155"/>`,
+			n.x, n.y, orgBoxWidth, n.height)
+		for i, line := range n.lines {
+			weight := "normal"
+			if i == 0 {
+				weight = "bold"
+			}
+			fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle" font-weight="%s">`,
+				n.x+orgBoxWidth/2, n.y+orgBoxPadding+orgLineHeight*float64(i)+13, weight)
+			xml.EscapeText(&buf, []byte(line))
+			buf.WriteString("</text>")
+		}
+		buf.WriteString("</g>\n")
+	}
+	for _, r := range roots {
+		draw(r)
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+func newOrgLayoutNode(chart *models.OrgChartNode) *orgLayoutNode {
+	lines := orgChartLabelLines(chart)
+	for i, line := range lines {
+		lines[i] = truncateSVGText(line, orgBoxWidth-2*orgBoxPadding)
+	}
+	n := &orgLayoutNode{
+		lines:  lines,
+		height: float64(len(lines))*orgLineHeight + 2*orgBoxPadding,
+	}
+	for _, c := range chart.Children {
+		n.children = append(n.children, newOrgLayoutNode(c))
+	}
+	return n
+}
+
+// 按 12px 字号估算文本宽度，超出时截断
+func truncateSVGText(s string, maxWidth float64) string {
+	width := 0.0
+	for i, r := range s {
+		w := 12.0
+		if r < 0x80 {
+			w = 7
+		}
+		if width+w > maxWidth {
+			return s[:i] + "…"
+		}
+		width += w
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// 获取职位列表，deptNo 不为 0 时只返回该部门可用的职位（含通用职位）
+func GetPositions(deptNo int) ([]models.Position, error) {
+	query := utils.DB.Order("Level, Title")
+	if deptNo != 0 {
+		query = query.Where("DeptNo IS NULL OR DeptNo = ?", deptNo)
+	}
+
+	var positions []models.Position
+	if err := query.Find(&positions).Error; err != nil {
+		return nil, errors.New("获取职位列表失败")
+	}
+	return positions, nil
+}
+
+// 创建职位
+func CreatePosition(req *models.PositionRequest) (*models.Position, error) {
+	position := &models.Position{}
+	if err := applyPositionRequest(position, req); err != nil {
+		return nil, err
+	}
+	if err := utils.DB.Create(position).Error; err != nil {
+		return nil, errors.New("创建职位失败")
+	}
+	return position, nil
+}
+
+// 更新职位，缩小适用部门时不能影响已有的在职关系
+func UpdatePosition(id int, req *models.PositionRequest) (*models.Position, error) {
+	var position models.Position
+	if err := utils.DB.First(&position, id).Error; err != nil {
+		return nil, errors.New("职位不存在")
+	}
+	if err := applyPositionRequest(&position, req); err != nil {
+		return nil, err
+	}
+
+	if position.DeptNo != nil {
+		var outside int64
+		if err := utils.DB.Model(&models.EmployeeDepartment{}).
+			Where("PositionID = ? AND EdStatus = 1 AND DeptNo != ?", id, *position.DeptNo).
+			Count(&outside).Error; err != nil {
+			return nil, errors.New("检查职位使用情况失败")
+		}
+		if outside > 0 {
+			return nil, errors.New("其他部门仍有员工担任该职位，不能限定适用部门")
+		}
+	}
+
+	if err := utils.DB.Save(&position).Error; err != nil {
+		return nil, errors.New("更新职位失败")
+	}
+	return &position, nil
+}
+
+// 删除职位，已被员工部门关系引用的职位不能删除
+func DeletePosition(id int) error {
+	var position models.Position
+	if err := utils.DB.First(&position, id).Error; err != nil {
+		return errors.New("职位不存在")
+	}
+
+	var used int64
+	if err := utils.DB.Model(&models.EmployeeDepartment{}).Where("PositionID = ?", id).Count(&used).Error; err != nil {
+		return errors.New("检查职位使用情况失败")
+	}
+	if used > 0 {
+		return errors.New("该职位已被使用，不能删除")
+	}
+
+	if err := utils.DB.Delete(&position).Error; err != nil {
+		return errors.New("删除职位失败")
+	}
+	return nil
+}
+
+// 校验职位请求并写入职位
+func applyPositionRequest(position *models.Position, req *models.PositionRequest) error {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return errors.New("职位名称不能为空")
+	}
+	if utf8.RuneCountInString(title) > 50 {
+		return errors.New("职位名称不能超过50个字符")
+	}
+	if utf8.RuneCountInString(req.Description) > 200 {
+		return errors.New("职位说明不能超过200个字符")
+	}
+	if req.Level < 1 {
+		return errors.New("职级必须大于0")
+	}
+
+	if req.DeptNo != nil {
+		var dept models.Department
+		if err := utils.DB.First(&dept, *req.DeptNo).Error; err != nil {
+			return errors.New("部门不存在")
+		}
+	}
+
+	// 同一范围内职位名称不能重复
+	query := utils.DB.Model(&models.Position{}).Where("Title = ? AND PositionID != ?", title, position.PositionID)
+	if req.DeptNo == nil {
+		query = query.Where("DeptNo IS NULL")
+	} else {
+		query = query.Where("DeptNo = ?", *req.DeptNo)
+	}
+	var duplicate int64
+	if err := query.Count(&duplicate).Error; err != nil {
+		return errors.New("检查职位名称失败")
+	}
+	if duplicate > 0 {
+		return errors.New("职位名称已存在")
+	}
+
+	position.Title = title
+	position.Level = req.Level
+	position.DeptNo = req.DeptNo
+	position.Description = req.Description
+	return nil
+}
+
+// 按职位和职级统计在职人数，同一员工在同一职位的多个部门关系只计一次
+func GetPositionHeadcount() (*models.PositionHeadcountReport, error) {
+	report := &models.PositionHeadcountReport{
+		ByPosition: []models.PositionHeadcount{},
+		ByLevel:    []models.LevelHeadcount{},
+	}
+
+	if err := utils.DB.Raw(`
+		SELECT p.PositionID, p.Title, p.Level, p.DeptNo, COALESCE(d.DeptName, '') as DeptName,
+			   COUNT(DISTINCT ed.EmpNo) as Headcount
+		FROM Positions p
+		LEFT JOIN Departments d ON p.DeptNo = d.DeptNo
+		LEFT JOIN Employee_Department ed ON ed.PositionID = p.PositionID AND ed.EdStatus = 1
+		GROUP BY p.PositionID, p.Title, p.Level, p.DeptNo, d.DeptName
+		ORDER BY p.Level, p.Title
+	`).Scan(&report.ByPosition).Error; err != nil {
+		return nil, errors.New("统计职位人数失败")
+	}
+
+	if err := utils.DB.Raw(`
+		SELECT p.Level, COUNT(DISTINCT ed.EmpNo) as Headcount
+		FROM Employee_Department ed
+		INNER JOIN Positions p ON ed.PositionID = p.PositionID
+		WHERE ed.EdStatus = 1
+		GROUP BY p.Level
+		ORDER BY p.Level
+	`).Scan(&report.ByLevel).Error; err != nil {
+		return nil, errors.New("统计职级人数失败")
+	}
+
+	var unpositioned int64
+	if err := utils.DB.Model(&models.EmployeeDepartment{}).
+		Where("EdStatus = 1 AND PositionID IS NULL").
+		Distinct("EmpNo").
+		Count(&unpositioned).Error; err != nil {
+		return nil, errors.New("统计职位人数失败")
+	}
+	report.Unpositioned = int(unpositioned)
+
+	return report, nil
+}
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 没有处理评估的权限时返回的错误，控制器据此返回 403
+var ErrReviewForbidden = errors.New("没有处理该评估的权限")
+
+// 获取考核周期（按开始日期倒序）
+func GetReviewCycles() ([]models.ReviewCycle, error) {
+	cycles := []models.ReviewCycle{}
+	if err := utils.DB.Order("PeriodStart DESC, CycleID DESC").Find(&cycles).Error; err != nil {
+		return nil, errors.New("获取考核周期失败")
+	}
+	return cycles, nil
+}
+
+// 创建考核周期，并为每条在职的员工部门关系生成一份草稿评估：
+// 只包含考核期结束前入职该部门的在职员工，评估人为部门的在任经理，
+// 员工本人是部门经理时由其直属上级评估
+func CreateReviewCycle(req *models.ReviewCycleRequest) (*models.ReviewCycle, int, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, 0, errors.New("周期名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > 50 {
+		return nil, 0, errors.New("周期名称不能超过50个字符")
+	}
+	periodStart, err := time.Parse("2006-01-02", req.PeriodStart)
+	if err != nil {
+		return nil, 0, errors.New("无效的开始日期格式")
+	}
+	periodEnd, err := time.Parse("2006-01-02", req.PeriodEnd)
+	if err != nil {
+		return nil, 0, errors.New("无效的结束日期格式")
+	}
+	if periodEnd.Before(periodStart) {
+		return nil, 0, errors.New("结束日期不能早于开始日期")
+	}
+
+	cycle := &models.ReviewCycle{
+		Name:        name,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		DeptNo:      req.DeptNo,
+		Status:      models.ReviewCycleOpen,
+	}
+	participants := 0
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if req.DeptNo != nil {
+			var dept models.Department
+			if err := tx.First(&dept, *req.DeptNo).Error; err != nil {
+				return errors.New("部门不存在")
+			}
+		}
+		if err := tx.Create(cycle).Error; err != nil {
+			return errors.New("创建考核周期失败")
+		}
+
+		var relations []struct {
+			EmpNo        int
+			DeptNo       int
+			ManagerEmpNo *int
+		}
+		query := tx.Table("Employee_Department ed").
+			Select("DISTINCT ed.EmpNo, ed.DeptNo, e.ManagerEmpNo").
+			Joins("INNER JOIN Employees e ON ed.EmpNo = e.EmpNo").
+			Where("ed.EdStatus = 1 AND ed.EdEntryDate <= ? AND e.EmploymentStatus = ?", periodEnd, models.EmploymentActive)
+		if req.DeptNo != nil {
+			query = query.Where("ed.DeptNo = ?", *req.DeptNo)
+		}
+		if err := query.Order("ed.DeptNo, ed.EmpNo").Scan(&relations).Error; err != nil {
+			return errors.New("获取考核参与者失败")
+		}
+
+		today := truncateToDay(time.Now())
+		var managers []models.DepartmentManager
+		if err := tx.Where("StartDate <= ? AND (EndDate IS NULL OR EndDate >= ?)", today, today).
+			Order("StartDate").
+			Find(&managers).Error; err != nil {
+			return errors.New("获取部门经理失败")
+		}
+		deptManagers := make(map[int]int, len(managers))
+		for _, m := range managers {
+			deptManagers[m.DeptNo] = m.EmpNo
+		}
+
+		reviews := make([]models.PerformanceReview, 0, len(relations))
+		for _, r := range relations {
+			review := models.PerformanceReview{
+				CycleID: cycle.CycleID,
+				EmpNo:   r.EmpNo,
+				DeptNo:  r.DeptNo,
+				Status:  models.ReviewDraft,
+			}
+			if manager, ok := deptManagers[r.DeptNo]; ok && manager != r.EmpNo {
+				review.ReviewerEmpNo = &manager
+			} else if r.ManagerEmpNo != nil && *r.ManagerEmpNo != r.EmpNo {
+				manager := *r.ManagerEmpNo
+				review.ReviewerEmpNo = &manager
+			}
+			reviews = append(reviews, review)
+		}
+		if len(reviews) > 0 {
+			if err := tx.CreateInBatches(reviews, 100).Error; err != nil {
+				return errors.New("生成绩效评估失败")
+			}
+		}
+		participants = len(reviews)
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return cycle, participants, nil
+}
+
+// 关闭考核周期，关闭后不能再填写和提交评估
+func CloseReviewCycle(id int) (*models.ReviewCycle, error) {
+	var cycle models.ReviewCycle
+	if err := utils.DB.First(&cycle, id).Error; err != nil {
+		return nil, errors.New("考核周期不存在")
+	}
+	if cycle.Status == models.ReviewCycleClosed {
+		return nil, errors.New("考核周期已关闭")
+	}
+	if err := utils.DB.Model(&cycle).Update("Status", models.ReviewCycleClosed).Error; err != nil {
+		return nil, errors.New("关闭考核周期失败")
+	}
+	return &cycle, nil
+}
+
+// 删除考核周期及其所有评估
+func DeleteReviewCycle(id int) error {
+	return utils.DB.Transaction(func(tx *gorm.DB) error {
+		var cycle models.ReviewCycle
+		if err := tx.First(&cycle, id).Error; err != nil {
+			return errors.New("考核周期不存在")
+		}
+		if err := tx.Where("CycleID = ?", id).Delete(&models.PerformanceReview{}).Error; err != nil {
+			return errors.New("删除绩效评估失败")
+		}
+		if err := tx.Delete(&cycle).Error; err != nil {
+			return errors.New("删除考核周期失败")
+		}
+		return nil
+	})
+}
+
+// 查询绩效评估（含姓名）
+func reviewDetailQuery() *gorm.DB {
+	return utils.DB.Table("Performance_Reviews r").
+		Select(`r.*, CONCAT(e.LastName, e.FirstName) as EmployeeName, d.DeptName,
+			COALESCE(CONCAT(m.LastName, m.FirstName), '') as ReviewerName`).
+		Joins("INNER JOIN Employees e ON r.EmpNo = e.EmpNo").
+		Joins("INNER JOIN Departments d ON r.DeptNo = d.DeptNo").
+		Joins("LEFT JOIN Employees m ON r.ReviewerEmpNo = m.EmpNo")
+}
+
+// 获取考核周期内的评估：管理员可以看到全部，
+// 其他用户只能看到自己作为评估人或被评估人的评估，被评估人看不到尚未提交的草稿
+func GetCycleReviews(cycleID int, user *models.User, status string) ([]models.PerformanceReviewDetail, error) {
+	var cycle models.ReviewCycle
+	if err := utils.DB.First(&cycle, cycleID).Error; err != nil {
+		return nil, errors.New("考核周期不存在")
+	}
+
+	reviews := []models.PerformanceReviewDetail{}
+	query := reviewDetailQuery().Where("r.CycleID = ?", cycleID)
+	if user.Role != "Admin" {
+		if user.EmpNo == nil {
+			return reviews, nil
+		}
+		query = query.Where("r.ReviewerEmpNo = ? OR (r.EmpNo = ? AND r.Status != ?)",
+			*user.EmpNo, *user.EmpNo, models.ReviewDraft)
+	}
+	if status != "" {
+		query = query.Where("r.Status = ?", status)
+	}
+	if err := query.Order("r.DeptNo, r.EmpNo").Scan(&reviews).Error; err != nil {
+		return nil, errors.New("获取绩效评估失败")
+	}
+	return reviews, nil
+}
+
+// 获取员工的历次绩效评估，管理员、员工本人和上级可以查看；
+// 员工本人看不到尚未提交的草稿（自己作为评估人的除外）
+func GetEmployeeReviews(empNo int, user *models.User) ([]models.PerformanceReviewDetail, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "reviews", false); err != nil {
+		return nil, err
+	}
+
+	reviews := []models.PerformanceReviewDetail{}
+	query := reviewDetailQuery().
+		Joins("INNER JOIN Review_Cycles c ON r.CycleID = c.CycleID").
+		Where("r.EmpNo = ?", empNo)
+	if user.Role != "Admin" && user.EmpNo != nil && *user.EmpNo == empNo {
+		query = query.Where("r.Status != ? OR r.ReviewerEmpNo = ?", models.ReviewDraft, empNo)
+	}
+	if err := query.
+		Order("c.PeriodStart DESC, r.DeptNo").
+		Scan(&reviews).Error; err != nil {
+		return nil, errors.New("获取绩效评估失败")
+	}
+	return reviews, nil
+}
+
+// 加载评估和所属周期，并检查周期是否仍开放
+func loadOpenReview(tx *gorm.DB, reviewID int) (*models.PerformanceReview, error) {
+	var review models.PerformanceReview
+	if err := tx.First(&review, reviewID).Error; err != nil {
+		return nil, errors.New("绩效评估不存在")
+	}
+	var cycle models.ReviewCycle
+	if err := tx.First(&cycle, review.CycleID).Error; err != nil {
+		return nil, errors.New("考核周期不存在")
+	}
+	if cycle.Status != models.ReviewCycleOpen {
+		return nil, errors.New("考核周期已关闭")
+	}
+	return &review, nil
+}
+
+// 是否为评估人或管理员
+func isReviewer(user *models.User, review *models.PerformanceReview) bool {
+	if user.Role == "Admin" {
+		return true
+	}
+	return user.EmpNo != nil && review.ReviewerEmpNo != nil && *user.EmpNo == *review.ReviewerEmpNo
+}
+
+// 填写评分和评语，只能修改草稿；管理员可以更换评估人
+func UpdateReview(reviewID int, req *models.PerformanceReviewRequest, user *models.User) (*models.PerformanceReview, error) {
+	if req.Rating != nil && (*req.Rating < models.ReviewRatingMin || *req.Rating > models.ReviewRatingMax) {
+		return nil, errors.New("评分必须在1到5之间")
+	}
+	if utf8.RuneCountInString(req.Comment) > 2000 {
+		return nil, errors.New("评语不能超过2000个字符")
+	}
+
+	var review *models.PerformanceReview
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if review, err = loadOpenReview(tx, reviewID); err != nil {
+			return err
+		}
+		if !isReviewer(user, review) {
+			return ErrReviewForbidden
+		}
+		if review.Status != models.ReviewDraft {
+			return errors.New("评估已提交，不能修改")
+		}
+
+		if req.ReviewerEmpNo != nil && (review.ReviewerEmpNo == nil || *req.ReviewerEmpNo != *review.ReviewerEmpNo) {
+			if user.Role != "Admin" {
+				return errors.New("只有管理员可以更换评估人")
+			}
+			if *req.ReviewerEmpNo == review.EmpNo {
+				return errors.New("评估人不能是被评估的员工")
+			}
+			var reviewer models.Employee
+			if err := tx.First(&reviewer, *req.ReviewerEmpNo).Error; err != nil {
+				return errors.New("评估人不存在")
+			}
+			if reviewer.EmploymentStatus == models.EmploymentTerminated {
+				return errors.New("评估人已离职")
+			}
+			review.ReviewerEmpNo = req.ReviewerEmpNo
+		}
+
+		review.Rating = req.Rating
+		review.Comment = req.Comment
+		if err := tx.Save(review).Error; err != nil {
+			return errors.New("保存绩效评估失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// 提交评估，提交前必须填写评分
+func SubmitReview(reviewID int, user *models.User) (*models.PerformanceReview, error) {
+	var review *models.PerformanceReview
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if review, err = loadOpenReview(tx, reviewID); err != nil {
+			return err
+		}
+		if !isReviewer(user, review) {
+			return ErrReviewForbidden
+		}
+		if review.Status != models.ReviewDraft {
+			return errors.New("评估已提交")
+		}
+		if review.Rating == nil {
+			return errors.New("请先填写评分")
+		}
+
+		now := time.Now()
+		review.Status = models.ReviewSubmitted
+		review.SubmittedAt = &now
+		if err := tx.Save(review).Error; err != nil {
+			return errors.New("提交绩效评估失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// 被评估的员工确认已提交的评估，可以附上员工意见
+func AcknowledgeReview(reviewID int, user *models.User, comment string) (*models.PerformanceReview, error) {
+	if utf8.RuneCountInString(comment) > 500 {
+		return nil, errors.New("员工意见不能超过500个字符")
+	}
+
+	var review models.PerformanceReview
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&review, reviewID).Error; err != nil {
+			return errors.New("绩效评估不存在")
+		}
+		if user.EmpNo == nil || *user.EmpNo != review.EmpNo {
+			return ErrReviewForbidden
+		}
+		if review.Status != models.ReviewSubmitted {
+			return errors.New("只能确认已提交的评估")
+		}
+
+		now := time.Now()
+		review.Status = models.ReviewAcknowledged
+		review.EmployeeComment = comment
+		review.AcknowledgedAt = &now
+		if err := tx.Save(&review).Error; err != nil {
+			return errors.New("确认绩效评估失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// 按部门汇总考核周期的评分，平均分只统计已提交和已确认的评估
+func GetReviewSummary(cycleID int) ([]models.DepartmentReviewSummary, error) {
+	var cycle models.ReviewCycle
+	if err := utils.DB.First(&cycle, cycleID).Error; err != nil {
+		return nil, errors.New("考核周期不存在")
+	}
+
+	var reviews []struct {
+		DeptNo   int
+		DeptName string
+		Rating   *int
+		Status   string
+	}
+	if err := utils.DB.Table("Performance_Reviews r").
+		Select("r.DeptNo, d.DeptName, r.Rating, r.Status").
+		Joins("INNER JOIN Departments d ON r.DeptNo = d.DeptNo").
+		Where("r.CycleID = ?", cycleID).
+		Order("r.DeptNo").
+		Scan(&reviews).Error; err != nil {
+		return nil, errors.New("获取绩效评估失败")
+	}
+
+	summaries := []models.DepartmentReviewSummary{}
+	index := make(map[int]int)
+	totals := make(map[int]int)
+	for _, r := range reviews {
+		i, ok := index[r.DeptNo]
+		if !ok {
+			i = len(summaries)
+			index[r.DeptNo] = i
+			summaries = append(summaries, models.DepartmentReviewSummary{
+				DeptNo:       r.DeptNo,
+				DeptName:     r.DeptName,
+				Distribution: make(map[int]int),
+			})
+		}
+		s := &summaries[i]
+		s.Participants++
+		if r.Status == models.ReviewDraft || r.Rating == nil {
+			continue
+		}
+		s.Completed++
+		s.Distribution[*r.Rating]++
+		totals[r.DeptNo] += *r.Rating
+	}
+
+	for i := range summaries {
+		s := &summaries[i]
+		if s.Completed > 0 {
+			s.AverageRating = math.Round(float64(totals[s.DeptNo])/float64(s.Completed)*100) / 100
+		}
+	}
+	return summaries, nil
+}
+
+// 删除员工的绩效评估，并清除其作为评估人的记录，用于删除员工
+func deleteEmployeeReviews(tx *gorm.DB, empNo int) error {
+	if err := tx.Where("EmpNo = ?", empNo).Delete(&models.PerformanceReview{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.PerformanceReview{}).
+		Where("ReviewerEmpNo = ?", empNo).
+		Update("ReviewerEmpNo", nil).Error
+}
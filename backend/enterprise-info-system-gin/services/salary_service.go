@@ -0,0 +1,237 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// 获取员工的薪资历史（按生效日期倒序）
+func GetEmployeeSalaries(empNo int) ([]models.Salary, error) {
+	var emp models.Employee
+	if err := utils.DB.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+
+	var salaries []models.Salary
+	if err := utils.DB.Where("EmpNo = ?", empNo).
+		Order("EffectiveDate DESC").
+		Find(&salaries).Error; err != nil {
+		return nil, errors.New("获取薪资记录失败")
+	}
+	return salaries, nil
+}
+
+// 添加薪资记录：新记录没有结束日期时，在其生效前一天自动结束之前仍有效的记录；
+// 与其他记录时间段重叠时拒绝
+func CreateSalary(empNo int, req *models.SalaryRequest) (*models.Salary, error) {
+	salary := &models.Salary{EmpNo: empNo}
+	if err := applySalaryRequest(salary, req); err != nil {
+		return nil, err
+	}
+
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		var emp models.Employee
+		if err := tx.First(&emp, empNo).Error; err != nil {
+			return errors.New("员工不存在")
+		}
+		if dateOnly(salary.EffectiveDate) < dateOnly(emp.HireDate) {
+			return errors.New("生效日期不能早于员工入职日期")
+		}
+
+		// 新记录没有结束日期时结束之前仍有效的薪资记录，
+		// 补录的历史记录不影响当前记录，重叠时由 checkSalaryOverlap 拒绝
+		if salary.EndDate == nil {
+			if err := tx.Model(&models.Salary{}).
+				Where("EmpNo = ? AND EndDate IS NULL AND EffectiveDate < ?", empNo, salary.EffectiveDate).
+				Update("EndDate", salary.EffectiveDate.AddDate(0, 0, -1)).Error; err != nil {
+				return errors.New("结束上一条薪资记录失败")
+			}
+		}
+
+		if err := checkSalaryOverlap(tx, salary); err != nil {
+			return err
+		}
+
+		if err := tx.Create(salary).Error; err != nil {
+			return errors.New("添加薪资记录失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return salary, nil
+}
+
+// 更新薪资记录
+func UpdateSalary(id int, req *models.SalaryRequest) (*models.Salary, error) {
+	var salary models.Salary
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&salary, id).Error; err != nil {
+			return errors.New("薪资记录不存在")
+		}
+		if err := applySalaryRequest(&salary, req); err != nil {
+			return err
+		}
+		if err := checkSalaryOverlap(tx, &salary); err != nil {
+			return err
+		}
+		if err := tx.Save(&salary).Error; err != nil {
+			return errors.New("更新薪资记录失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &salary, nil
+}
+
+// 删除薪资记录
+func DeleteSalary(id int) error {
+	result := utils.DB.Delete(&models.Salary{}, id)
+	if result.Error != nil {
+		return errors.New("删除薪资记录失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("薪资记录不存在")
+	}
+	return nil
+}
+
+// 校验薪资请求并写入薪资记录
+func applySalaryRequest(salary *models.Salary, req *models.SalaryRequest) error {
+	if req.BaseSalary <= 0 {
+		return errors.New("基本工资必须大于0")
+	}
+	if req.BaseSalary >= 1e10 {
+		return errors.New("基本工资超出范围")
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = "CNY"
+	}
+	if !currencyPattern.MatchString(currency) {
+		return errors.New("无效的币种代码")
+	}
+
+	effectiveDate, err := time.Parse("2006-01-02", req.EffectiveDate)
+	if err != nil {
+		return errors.New("无效的生效日期格式")
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" && req.EndDate != "null" {
+		parsed, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return errors.New("无效的结束日期格式")
+		}
+		if parsed.Before(effectiveDate) {
+			return errors.New("结束日期不能早于生效日期")
+		}
+		endDate = &parsed
+	}
+
+	if utf8.RuneCountInString(req.Reason) > 200 {
+		return errors.New("调薪原因不能超过200个字符")
+	}
+
+	salary.BaseSalary = math.Round(req.BaseSalary*100) / 100
+	salary.Currency = currency
+	salary.EffectiveDate = effectiveDate
+	salary.EndDate = endDate
+	salary.Reason = req.Reason
+	return nil
+}
+
+// 检查薪资记录与同一员工的其他记录是否时间段重叠
+func checkSalaryOverlap(tx *gorm.DB, salary *models.Salary) error {
+	var others []models.Salary
+	if err := tx.Where("EmpNo = ? AND SalaryID != ?", salary.EmpNo, salary.SalaryID).
+		Find(&others).Error; err != nil {
+		return errors.New("获取薪资记录失败")
+	}
+
+	start, end := periodBounds(salary.EffectiveDate, salary.EndDate)
+	for _, other := range others {
+		otherStart, otherEnd := periodBounds(other.EffectiveDate, other.EndDate)
+		if start <= otherEnd && otherStart <= end {
+			until := "至今"
+			if other.EndDate != nil {
+				until = "至 " + dateOnly(*other.EndDate)
+			}
+			return errors.New("与已有的薪资记录（" + otherStart + " " + until + "）时间段重叠")
+		}
+	}
+	return nil
+}
+
+// 按部门统计当前薪资成本：只统计在职部门关系中的员工，
+// 同时在多个部门任职的员工，薪资平均分摊到各部门
+func GetDepartmentPayroll() ([]models.DepartmentPayroll, error) {
+	today := truncateToDay(time.Now())
+
+	var rows []struct {
+		EmpNo      int
+		DeptNo     int
+		DeptName   string
+		BaseSalary float64
+		Currency   string
+	}
+	if err := utils.DB.Raw(`
+		SELECT DISTINCT ed.EmpNo, ed.DeptNo, d.DeptName, s.BaseSalary, s.Currency
+		FROM Employee_Department ed
+		INNER JOIN Departments d ON ed.DeptNo = d.DeptNo
+		INNER JOIN Salaries s ON s.EmpNo = ed.EmpNo
+			AND s.EffectiveDate <= ? AND (s.EndDate IS NULL OR s.EndDate >= ?)
+		WHERE ed.EdStatus = 1
+	`, today, today).Scan(&rows).Error; err != nil {
+		return nil, errors.New("统计部门薪资失败")
+	}
+
+	deptCount := make(map[int]int)
+	for _, r := range rows {
+		deptCount[r.EmpNo]++
+	}
+
+	type key struct {
+		DeptNo   int
+		Currency string
+	}
+	totals := make(map[key]*models.DepartmentPayroll)
+	for _, r := range rows {
+		k := key{r.DeptNo, r.Currency}
+		p, ok := totals[k]
+		if !ok {
+			p = &models.DepartmentPayroll{DeptNo: r.DeptNo, DeptName: r.DeptName, Currency: r.Currency}
+			totals[k] = p
+		}
+		p.Headcount++
+		p.TotalCost += r.BaseSalary / float64(deptCount[r.EmpNo])
+	}
+
+	result := make([]models.DepartmentPayroll, 0, len(totals))
+	for _, p := range totals {
+		p.TotalCost = math.Round(p.TotalCost*100) / 100
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].DeptNo != result[j].DeptNo {
+			return result[i].DeptNo < result[j].DeptNo
+		}
+		return result[i].Currency < result[j].Currency
+	})
+	return result, nil
+}
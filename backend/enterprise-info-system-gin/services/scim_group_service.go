@@ -0,0 +1,334 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 部门的在职成员，作为 SCIM 组的 members
+type scimGroupMember struct {
+	DeptNo       int
+	EmpNo        int
+	EmployeeName string
+}
+
+// 获取 query 查出的部门对应的 SCIM 组
+func loadSCIMGroups(baseURL string, query *gorm.DB) ([]models.SCIMGroup, error) {
+	var departments []models.Department
+	if err := query.Order("DeptNo").Find(&departments).Error; err != nil {
+		return nil, errors.New("获取部门信息失败")
+	}
+	if len(departments) == 0 {
+		return []models.SCIMGroup{}, nil
+	}
+
+	deptNos := make([]int, len(departments))
+	for i, d := range departments {
+		deptNos[i] = d.DeptNo
+	}
+	var members []scimGroupMember
+	if err := utils.DB.Table("Employee_Department ed").
+		Select("ed.DeptNo, ed.EmpNo, CONCAT(e.LastName, e.FirstName) as EmployeeName").
+		Joins("INNER JOIN Employees e ON ed.EmpNo = e.EmpNo").
+		Where("ed.DeptNo IN ? AND ed.EdStatus = 1", deptNos).
+		Order("ed.DeptNo, ed.EmpNo").
+		Scan(&members).Error; err != nil {
+		return nil, errors.New("获取部门成员失败")
+	}
+	membersByDept := make(map[int][]models.SCIMMultiValue)
+	for _, m := range members {
+		id := strconv.Itoa(m.EmpNo)
+		membersByDept[m.DeptNo] = append(membersByDept[m.DeptNo], models.SCIMMultiValue{
+			Value:   id,
+			Display: m.EmployeeName,
+			Type:    models.SCIMResourceUser,
+			Ref:     baseURL + "/Users/" + id,
+		})
+	}
+
+	externalIDs, err := loadSCIMExternalIDs(models.SCIMResourceGroup, deptNos)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]models.SCIMGroup, 0, len(departments))
+	for _, d := range departments {
+		id := strconv.Itoa(d.DeptNo)
+		groups = append(groups, models.SCIMGroup{
+			Schemas:     []string{models.SCIMSchemaGroup},
+			ID:          id,
+			ExternalID:  externalIDs[d.DeptNo],
+			DisplayName: d.DeptName,
+			Members:     membersByDept[d.DeptNo],
+			Meta: &models.SCIMMeta{
+				ResourceType: models.SCIMResourceGroup,
+				Location:     baseURL + "/Groups/" + id,
+			},
+		})
+	}
+	return groups, nil
+}
+
+// 获取 SCIM 组列表：没有过滤条件时在数据库中分页；
+// 否则先按 id、displayName 和 externalId 的 eq 比较在数据库中缩小范围，再精确过滤和分页
+func ListSCIMGroups(params *SCIMListParams, baseURL string) (*models.SCIMListResponse, error) {
+	query := utils.DB.Model(&models.Department{}).Session(&gorm.Session{})
+	if params.Filter == nil {
+		total, page, err := paginateSCIMQuery(query, params)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := loadSCIMGroups(baseURL, page)
+		if err != nil {
+			return nil, err
+		}
+		return listSCIMPage(scimGroupResources(groups), total, params)
+	}
+
+	groups, err := loadSCIMGroups(baseURL, narrowSCIMGroupQuery(query, utils.SCIMFilterEqualities(params.Filter)))
+	if err != nil {
+		return nil, err
+	}
+	return listSCIMResources(scimGroupResources(groups), params)
+}
+
+// 按过滤条件中的 eq 比较缩小部门查询的范围，结果仍需用过滤条件精确匹配
+func narrowSCIMGroupQuery(query *gorm.DB, equalities map[string]string) *gorm.DB {
+	if deptNo, ok := scimFilterID(equalities); ok {
+		query = query.Where("DeptNo = ?", deptNo)
+	}
+	if name, ok := equalities["displayname"]; ok {
+		query = query.Where("DeptName = ?", name)
+	}
+	if externalID, ok := equalities["externalid"]; ok {
+		query = query.Where("DeptNo IN (?)", scimExternalIDSubQuery(models.SCIMResourceGroup, externalID))
+	}
+	return query
+}
+
+func scimGroupResources(groups []models.SCIMGroup) []interface{} {
+	resources := make([]interface{}, len(groups))
+	for i := range groups {
+		resources[i] = groups[i]
+	}
+	return resources
+}
+
+// 获取单个 SCIM 组
+func GetSCIMGroup(id, baseURL string) (*models.SCIMGroup, error) {
+	deptNo, err := parseSCIMID(id, models.SCIMResourceGroup)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := loadSCIMGroups(baseURL, utils.DB.Where("DeptNo = ?", deptNo))
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, utils.NewSCIMError(http.StatusNotFound, "", "Group "+id+" 不存在")
+	}
+	return &groups[0], nil
+}
+
+// 校验组名称和成员，成员只能是员工
+func parseSCIMGroup(req *models.SCIMGroup) (string, []int, error) {
+	name := strings.TrimSpace(req.DisplayName)
+	if name == "" {
+		return "", nil, utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "displayName 不能为空")
+	}
+	if utf8.RuneCountInString(name) > 30 {
+		return "", nil, utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "displayName 不能超过30个字符")
+	}
+
+	seen := make(map[int]bool)
+	var empNos []int
+	for _, m := range req.Members {
+		empNo, err := strconv.Atoi(m.Value)
+		if err != nil || (m.Type != "" && m.Type != models.SCIMResourceUser) {
+			return "", nil, utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "成员只能是用户: "+m.Value)
+		}
+		if !seen[empNo] {
+			seen[empNo] = true
+			empNos = append(empNos, empNo)
+		}
+	}
+	if len(empNos) > 0 {
+		var count int64
+		if err := utils.DB.Model(&models.Employee{}).Where("EmpNo IN ?", empNos).Count(&count).Error; err != nil {
+			return "", nil, errors.New("获取员工信息失败")
+		}
+		if int(count) != len(empNos) {
+			return "", nil, utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "成员中有不存在的用户")
+		}
+	}
+	sort.Ints(empNos)
+	return name, empNos, nil
+}
+
+// 部门名称是否已被其他部门使用
+func checkSCIMGroupNameAvailable(tx *gorm.DB, name string, excludeDeptNo int) error {
+	var count int64
+	if err := tx.Model(&models.Department{}).
+		Where("DeptName = ? AND DeptNo != ?", name, excludeDeptNo).
+		Count(&count).Error; err != nil {
+		return errors.New("检查部门名称失败")
+	}
+	if count > 0 {
+		return utils.NewSCIMError(http.StatusConflict, "uniqueness", "displayName "+name+" 已存在")
+	}
+	return nil
+}
+
+// 将部门的在职成员调整为指定的员工：新成员从当天加入部门，移除的成员当天离开部门
+func setSCIMGroupMembers(tx *gorm.DB, deptNo int, empNos []int) error {
+	var relations []models.EmployeeDepartment
+	if err := tx.Where("DeptNo = ? AND EdStatus = 1", deptNo).Find(&relations).Error; err != nil {
+		return errors.New("获取部门成员失败")
+	}
+	wanted := make(map[int]bool, len(empNos))
+	for _, empNo := range empNos {
+		wanted[empNo] = true
+	}
+
+	today := dateOnly(time.Now())
+	current := make(map[int]bool, len(relations))
+	for _, ed := range relations {
+		current[ed.EmpNo] = true
+		if wanted[ed.EmpNo] {
+			continue
+		}
+		if err := updateEmployeeDepartment(tx, ed.EdID, &models.EmployeeDepartmentRequest{
+			EmpNo:       ed.EmpNo,
+			DeptNo:      ed.DeptNo,
+			EdEntryDate: dateOnly(ed.EdEntryDate),
+			EdLeaveDate: today,
+			EdStatus:    2,
+			PositionID:  ed.PositionID,
+		}); err != nil {
+			return scimInvalidValue(err)
+		}
+	}
+	for _, empNo := range empNos {
+		if current[empNo] {
+			continue
+		}
+		if err := addEmployeeDepartment(tx, &models.EmployeeDepartmentRequest{
+			EmpNo:       empNo,
+			DeptNo:      deptNo,
+			EdEntryDate: today,
+			EdStatus:    1,
+		}); err != nil {
+			return scimInvalidValue(err)
+		}
+	}
+	return nil
+}
+
+// 创建 SCIM 组：新建顶层部门并添加成员
+func CreateSCIMGroup(req *models.SCIMGroup, baseURL string) (*models.SCIMGroup, error) {
+	name, empNos, err := parseSCIMGroup(req)
+	if err != nil {
+		return nil, err
+	}
+	dept := models.Department{DeptName: name}
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkSCIMGroupNameAvailable(tx, name, 0); err != nil {
+			return err
+		}
+		if err := createDepartment(tx, &dept); err != nil {
+			return scimInvalidValue(err)
+		}
+		if err := saveSCIMExternalID(tx, models.SCIMResourceGroup, dept.DeptNo, strings.TrimSpace(req.ExternalID)); err != nil {
+			return errors.New("保存 externalId 失败")
+		}
+		return setSCIMGroupMembers(tx, dept.DeptNo, empNos)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetSCIMGroup(strconv.Itoa(dept.DeptNo), baseURL)
+}
+
+// 替换 SCIM 组（PUT）：修改部门名称并将成员调整为请求中的成员
+func ReplaceSCIMGroup(id string, req *models.SCIMGroup, baseURL string) (*models.SCIMGroup, error) {
+	deptNo, err := parseSCIMID(id, models.SCIMResourceGroup)
+	if err != nil {
+		return nil, err
+	}
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		var dept models.Department
+		if err := tx.First(&dept, deptNo).Error; err != nil {
+			return utils.NewSCIMError(http.StatusNotFound, "", "Group "+id+" 不存在")
+		}
+		name, empNos, err := parseSCIMGroup(req)
+		if err != nil {
+			return err
+		}
+
+		if name != dept.DeptName {
+			if err := checkSCIMGroupNameAvailable(tx, name, deptNo); err != nil {
+				return err
+			}
+			dept.DeptName = name
+			if err := updateDepartment(tx, &dept); err != nil {
+				return scimInvalidValue(err)
+			}
+		}
+		if err := saveSCIMExternalID(tx, models.SCIMResourceGroup, deptNo, strings.TrimSpace(req.ExternalID)); err != nil {
+			return errors.New("保存 externalId 失败")
+		}
+		return setSCIMGroupMembers(tx, deptNo, empNos)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetSCIMGroup(id, baseURL)
+}
+
+// 按 RFC 7644 3.5.2 修改 SCIM 组，常用于添加或移除成员
+func PatchSCIMGroup(id string, ops []models.SCIMPatchOperation, baseURL string) (*models.SCIMGroup, error) {
+	current, err := GetSCIMGroup(id, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := scimResourceMap(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ApplySCIMPatch(resource, ops); err != nil {
+		return nil, err
+	}
+	var patched models.SCIMGroup
+	if err := scimResourceFromMap(resource, &patched); err != nil {
+		return nil, err
+	}
+	return ReplaceSCIMGroup(id, &patched, baseURL)
+}
+
+// 删除 SCIM 组，部门存在子部门时拒绝删除
+func DeleteSCIMGroup(id string) error {
+	deptNo, err := parseSCIMID(id, models.SCIMResourceGroup)
+	if err != nil {
+		return err
+	}
+	var dept models.Department
+	if err := utils.DB.First(&dept, deptNo).Error; err != nil {
+		return utils.NewSCIMError(http.StatusNotFound, "", "Group "+id+" 不存在")
+	}
+	if err := DeleteDepartment(deptNo, DeleteChildrenRefuse); err != nil {
+		return scimInvalidValue(err)
+	}
+	if err := saveSCIMExternalID(utils.DB, models.SCIMResourceGroup, deptNo, ""); err != nil {
+		return errors.New("删除 externalId 失败")
+	}
+	return nil
+}
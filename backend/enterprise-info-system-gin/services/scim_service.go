@@ -0,0 +1,348 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SCIM 列表查询每页的默认数量和最大数量
+const (
+	scimDefaultCount = 100
+	scimMaxResults   = 200
+)
+
+// SCIM 列表查询参数（RFC 7644 3.4.2）
+type SCIMListParams struct {
+	Filter             utils.SCIMFilter
+	StartIndex         int
+	Count              int
+	Attributes         []string
+	ExcludedAttributes []string
+}
+
+// 解析 SCIM 列表查询参数：startIndex 从 1 开始，count 默认为 100、最大为 200
+func ParseSCIMListParams(filter, startIndex, count, attributes, excludedAttributes string) (*SCIMListParams, error) {
+	params := &SCIMListParams{StartIndex: 1, Count: scimDefaultCount}
+	if filter != "" {
+		f, err := utils.ParseSCIMFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		params.Filter = f
+	}
+	if startIndex != "" {
+		n, err := strconv.Atoi(startIndex)
+		if err != nil {
+			return nil, utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "无效的 startIndex")
+		}
+		if n > 1 {
+			params.StartIndex = n
+		}
+	}
+	if count != "" {
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "无效的 count")
+		}
+		params.Count = n
+	}
+	if params.Count < 0 {
+		params.Count = 0
+	}
+	if params.Count > scimMaxResults {
+		params.Count = scimMaxResults
+	}
+	params.Attributes = splitSCIMAttributes(attributes)
+	params.ExcludedAttributes = splitSCIMAttributes(excludedAttributes)
+	return params, nil
+}
+
+func splitSCIMAttributes(s string) []string {
+	var attrs []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// 将 SCIM 资源转换为 JSON 对象，用于过滤和 PATCH
+func scimResourceMap(resource interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, errors.New("生成 SCIM 资源失败")
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.New("生成 SCIM 资源失败")
+	}
+	return m, nil
+}
+
+// 将 JSON 对象转换回 SCIM 资源
+func scimResourceFromMap(m map[string]interface{}, resource interface{}) error {
+	data, err := json.Marshal(m)
+	if err == nil {
+		err = json.Unmarshal(data, resource)
+	}
+	if err != nil {
+		return utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "PATCH 后的属性值无效")
+	}
+	return nil
+}
+
+// 按 attributes 和 excludedAttributes 参数筛选单个资源返回的属性
+func ProjectSCIMResource(resource interface{}, attributes, excludedAttributes string) (map[string]interface{}, error) {
+	m, err := scimResourceMap(resource)
+	if err != nil {
+		return nil, err
+	}
+	return utils.ProjectSCIMResource(m, splitSCIMAttributes(attributes), splitSCIMAttributes(excludedAttributes)), nil
+}
+
+// 对资源进行过滤、分页和属性筛选，生成列表查询结果
+func listSCIMResources(resources []interface{}, params *SCIMListParams) (*models.SCIMListResponse, error) {
+	matched := make([]map[string]interface{}, 0, len(resources))
+	for _, r := range resources {
+		m, err := scimResourceMap(r)
+		if err != nil {
+			return nil, err
+		}
+		if params.Filter == nil || params.Filter.Match(m) {
+			matched = append(matched, m)
+		}
+	}
+
+	start := params.StartIndex - 1
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + params.Count
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return scimListResponse(matched[start:end], len(matched), params), nil
+}
+
+// 对已在数据库中分页的资源进行属性筛选，生成列表查询结果
+func listSCIMPage(page []interface{}, total int, params *SCIMListParams) (*models.SCIMListResponse, error) {
+	resources := make([]map[string]interface{}, 0, len(page))
+	for _, r := range page {
+		m, err := scimResourceMap(r)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, m)
+	}
+	return scimListResponse(resources, total, params), nil
+}
+
+func scimListResponse(resources []map[string]interface{}, total int, params *SCIMListParams) *models.SCIMListResponse {
+	list := &models.SCIMListResponse{
+		Schemas:      []string{models.SCIMSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   params.StartIndex,
+		Resources:    []map[string]interface{}{},
+	}
+	for _, m := range resources {
+		list.Resources = append(list.Resources, utils.ProjectSCIMResource(m, params.Attributes, params.ExcludedAttributes))
+	}
+	list.ItemsPerPage = len(list.Resources)
+	return list
+}
+
+// 统计查询结果的总数，并按 startIndex 和 count 返回当前页的查询，
+// query 需要是可以重复使用的会话（Session）
+func paginateSCIMQuery(query *gorm.DB, params *SCIMListParams) (int, *gorm.DB, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, nil, errors.New("获取资源数量失败")
+	}
+	return int(total), query.Offset(params.StartIndex - 1).Limit(params.Count), nil
+}
+
+// 过滤条件中 id 的 eq 比较对应的数据库编号，不是有效编号时返回 0
+func scimFilterID(equalities map[string]string) (int, bool) {
+	id, ok := equalities["id"]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil || n <= 0 {
+		return 0, true
+	}
+	return n, true
+}
+
+// 过滤条件中 externalId 的 eq 比较对应的资源编号子查询
+func scimExternalIDSubQuery(resourceType, externalID string) *gorm.DB {
+	return utils.DB.Model(&models.SCIMExternalID{}).Select("ResourceID").
+		Where("ResourceType = ? AND ExternalID = ?", resourceType, externalID)
+}
+
+// 获取指定资源的 externalId
+func loadSCIMExternalIDs(resourceType string, resourceIDs []int) (map[int]string, error) {
+	var ids []models.SCIMExternalID
+	if err := utils.DB.Where("ResourceType = ? AND ResourceID IN ?", resourceType, resourceIDs).Find(&ids).Error; err != nil {
+		return nil, errors.New("获取 externalId 失败")
+	}
+	result := make(map[int]string, len(ids))
+	for _, id := range ids {
+		result[id.ResourceID] = id.ExternalID
+	}
+	return result, nil
+}
+
+// 保存资源的 externalId，为空时删除
+func saveSCIMExternalID(tx *gorm.DB, resourceType string, resourceID int, externalID string) error {
+	if externalID == "" {
+		return tx.Where("ResourceType = ? AND ResourceID = ?", resourceType, resourceID).
+			Delete(&models.SCIMExternalID{}).Error
+	}
+	return tx.Save(&models.SCIMExternalID{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		ExternalID:   externalID,
+	}).Error
+}
+
+// 解析 SCIM 资源的 id
+func parseSCIMID(id, resourceType string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil || n <= 0 {
+		return 0, utils.NewSCIMError(http.StatusNotFound, "", resourceType+" "+id+" 不存在")
+	}
+	return n, nil
+}
+
+// 将复用的业务校验错误转换为 SCIM 错误
+func scimInvalidValue(err error) error {
+	var scimErr *utils.SCIMError
+	if err == nil || errors.As(err, &scimErr) {
+		return err
+	}
+	return utils.NewSCIMError(http.StatusBadRequest, "invalidValue", err.Error())
+}
+
+func hashSCIMToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// 创建 SCIM 访问令牌，明文令牌只在创建时返回
+func CreateSCIMToken(req *models.SCIMTokenRequest) (*models.SCIMTokenCreated, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("令牌名称不能为空")
+	}
+	if len([]rune(name)) > 100 {
+		return nil, errors.New("令牌名称不能超过100个字符")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, errors.New("生成令牌失败")
+	}
+	token := "scim_" + hex.EncodeToString(raw)
+	created := &models.SCIMTokenCreated{
+		SCIMToken: models.SCIMToken{Name: name, TokenHash: hashSCIMToken(token)},
+		Token:     token,
+	}
+	if err := utils.DB.Create(&created.SCIMToken).Error; err != nil {
+		return nil, errors.New("保存令牌失败")
+	}
+	return created, nil
+}
+
+// 获取所有 SCIM 访问令牌
+func GetSCIMTokens() ([]models.SCIMToken, error) {
+	tokens := []models.SCIMToken{}
+	if err := utils.DB.Order("TokenID").Find(&tokens).Error; err != nil {
+		return nil, errors.New("获取令牌失败")
+	}
+	return tokens, nil
+}
+
+// 删除（吊销）SCIM 访问令牌
+func DeleteSCIMToken(id int) error {
+	result := utils.DB.Delete(&models.SCIMToken{}, id)
+	if result.Error != nil {
+		return errors.New("删除令牌失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("令牌不存在")
+	}
+	return nil
+}
+
+// 校验 SCIM 访问令牌，并记录最后使用时间
+func AuthenticateSCIMToken(token string) error {
+	var t models.SCIMToken
+	if err := utils.DB.Where("TokenHash = ?", hashSCIMToken(token)).First(&t).Error; err != nil {
+		return errors.New("无效的访问令牌")
+	}
+	utils.DB.Model(&t).UpdateColumn("LastUsedAt", time.Now())
+	return nil
+}
+
+// SCIM 服务能力说明（RFC 7643 5）
+func SCIMServiceProviderConfig(baseURL string) map[string]interface{} {
+	return map[string]interface{}{
+		"schemas":        []string{models.SCIMSchemaServiceProviderConfig},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": scimMaxResults},
+		"changePassword": map[string]bool{"supported": true},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "使用管理员创建的 SCIM 访问令牌",
+			"primary":     true,
+		}},
+		"meta": map[string]string{
+			"resourceType": "ServiceProviderConfig",
+			"location":     baseURL + "/ServiceProviderConfig",
+		},
+	}
+}
+
+// SCIM 支持的资源类型（RFC 7643 6）
+func SCIMResourceTypes(baseURL string) []map[string]interface{} {
+	return []map[string]interface{}{
+		{
+			"schemas":     []string{models.SCIMSchemaResourceType},
+			"id":          models.SCIMResourceUser,
+			"name":        models.SCIMResourceUser,
+			"endpoint":    "/Users",
+			"description": "员工",
+			"schema":      models.SCIMSchemaUser,
+			"schemaExtensions": []map[string]interface{}{
+				{"schema": models.SCIMSchemaEnterpriseUser, "required": false},
+			},
+			"meta": map[string]string{"resourceType": "ResourceType", "location": baseURL + "/ResourceTypes/User"},
+		},
+		{
+			"schemas":     []string{models.SCIMSchemaResourceType},
+			"id":          models.SCIMResourceGroup,
+			"name":        models.SCIMResourceGroup,
+			"endpoint":    "/Groups",
+			"description": "部门",
+			"schema":      models.SCIMSchemaGroup,
+			"meta":        map[string]string{"resourceType": "ResourceType", "location": baseURL + "/ResourceTypes/Group"},
+		},
+	}
+}
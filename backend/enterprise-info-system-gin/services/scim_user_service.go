@@ -0,0 +1,691 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 身份提供方停用用户时记录的离职原因
+const scimTerminationReason = "身份提供方停用账号"
+
+// 从 SCIM 用户中解析出的员工和用户账号字段
+type scimUserFields struct {
+	UserName     string
+	FirstName    string
+	LastName     string
+	Telephone    string
+	Address      string
+	PasswordHash string
+	ManagerEmpNo *int
+	Emails       []models.EmployeeEmail
+	Active       *bool
+	ExternalID   string
+}
+
+// 员工所在的部门，作为 SCIM 用户的 groups
+type scimUserGroup struct {
+	EmpNo    int
+	DeptNo   int
+	DeptName string
+}
+
+// 获取 query 查出的员工对应的 SCIM 用户。
+// 员工关联多个用户账号时使用最早创建的账号，未关联账号时以员工编号作为 userName
+func loadSCIMUsers(baseURL string, query *gorm.DB) ([]models.SCIMUser, error) {
+	var employees []models.Employee
+	if err := query.Order("EmpNo").Find(&employees).Error; err != nil {
+		return nil, errors.New("获取员工信息失败")
+	}
+	if len(employees) == 0 {
+		return []models.SCIMUser{}, nil
+	}
+
+	empNos := make([]int, len(employees))
+	var managerIDs []int
+	for i, e := range employees {
+		empNos[i] = e.EmpNo
+		if e.ManagerEmpNo != nil {
+			managerIDs = append(managerIDs, *e.ManagerEmpNo)
+		}
+	}
+
+	var users []models.User
+	if err := utils.DB.Where("EmpNo IN ?", empNos).Order("UserID").Find(&users).Error; err != nil {
+		return nil, errors.New("获取用户账号失败")
+	}
+	accounts := make(map[int]*models.User, len(users))
+	for i := range users {
+		if _, ok := accounts[*users[i].EmpNo]; !ok {
+			accounts[*users[i].EmpNo] = &users[i]
+		}
+	}
+
+	var emails []models.EmployeeEmail
+	if err := utils.DB.Where("EmpNo IN ?", empNos).Order("IsPrimary DESC, EmailID").Find(&emails).Error; err != nil {
+		return nil, errors.New("获取员工邮箱失败")
+	}
+	emailsByEmp := make(map[int][]models.EmployeeEmail)
+	for _, e := range emails {
+		emailsByEmp[e.EmpNo] = append(emailsByEmp[e.EmpNo], e)
+	}
+
+	var groups []scimUserGroup
+	if err := utils.DB.Table("Employee_Department ed").
+		Select("ed.EmpNo, ed.DeptNo, d.DeptName").
+		Joins("INNER JOIN Departments d ON ed.DeptNo = d.DeptNo").
+		Where("ed.EmpNo IN ? AND ed.EdStatus = 1", empNos).
+		Order("ed.EdEntryDate, ed.EdID").
+		Scan(&groups).Error; err != nil {
+		return nil, errors.New("获取员工部门失败")
+	}
+	groupsByEmp := make(map[int][]scimUserGroup)
+	for _, g := range groups {
+		groupsByEmp[g.EmpNo] = append(groupsByEmp[g.EmpNo], g)
+	}
+
+	managerNames := make(map[int]string)
+	if len(managerIDs) > 0 {
+		var managers []models.Employee
+		if err := utils.DB.Select("EmpNo", "FirstName", "LastName").
+			Where("EmpNo IN ?", managerIDs).
+			Find(&managers).Error; err != nil {
+			return nil, errors.New("获取直属上级失败")
+		}
+		for _, m := range managers {
+			managerNames[m.EmpNo] = m.LastName + m.FirstName
+		}
+	}
+
+	externalIDs, err := loadSCIMExternalIDs(models.SCIMResourceUser, empNos)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.SCIMUser, 0, len(employees))
+	for _, e := range employees {
+		result = append(result, buildSCIMUser(e, accounts[e.EmpNo], emailsByEmp[e.EmpNo], groupsByEmp[e.EmpNo],
+			managerNames, externalIDs[e.EmpNo], baseURL))
+	}
+	return result, nil
+}
+
+func buildSCIMUser(emp models.Employee, account *models.User, emails []models.EmployeeEmail, groups []scimUserGroup,
+	managerNames map[int]string, externalID, baseURL string) models.SCIMUser {
+	id := strconv.Itoa(emp.EmpNo)
+	active := emp.EmploymentStatus == models.EmploymentActive && (account == nil || !account.Disabled)
+	u := models.SCIMUser{
+		Schemas:     []string{models.SCIMSchemaUser, models.SCIMSchemaEnterpriseUser},
+		ID:          id,
+		ExternalID:  externalID,
+		UserName:    id,
+		DisplayName: emp.LastName + emp.FirstName,
+		Name: &models.SCIMName{
+			Formatted:  emp.LastName + emp.FirstName,
+			FamilyName: emp.LastName,
+			GivenName:  emp.FirstName,
+		},
+		Active:     &active,
+		Enterprise: &models.SCIMEnterpriseUser{EmployeeNumber: id},
+		Meta: &models.SCIMMeta{
+			ResourceType: models.SCIMResourceUser,
+			Location:     baseURL + "/Users/" + id,
+		},
+	}
+	if account != nil {
+		u.UserName = account.Username
+	}
+
+	for _, e := range emails {
+		emailType := "work"
+		if e.EmailType == models.EmailPersonal {
+			emailType = "home"
+		}
+		u.Emails = append(u.Emails, models.SCIMMultiValue{Value: e.Email, Type: emailType, Primary: e.IsPrimary})
+	}
+	if emp.Telephone != "" {
+		u.PhoneNumbers = []models.SCIMMultiValue{{Value: emp.Telephone, Type: "work", Primary: true}}
+	}
+	if emp.Address != "" {
+		u.Addresses = []models.SCIMAddress{{Type: "work", Formatted: emp.Address, StreetAddress: emp.Address, Primary: true}}
+	}
+
+	for _, g := range groups {
+		deptID := strconv.Itoa(g.DeptNo)
+		u.Groups = append(u.Groups, models.SCIMMultiValue{
+			Value:   deptID,
+			Display: g.DeptName,
+			Type:    "direct",
+			Ref:     baseURL + "/Groups/" + deptID,
+		})
+	}
+	if len(groups) > 0 {
+		u.Enterprise.Department = groups[0].DeptName
+	}
+	if emp.ManagerEmpNo != nil {
+		managerID := strconv.Itoa(*emp.ManagerEmpNo)
+		u.Enterprise.Manager = &models.SCIMManager{
+			Value:       managerID,
+			Ref:         baseURL + "/Users/" + managerID,
+			DisplayName: managerNames[*emp.ManagerEmpNo],
+		}
+	}
+	return u
+}
+
+// 获取 SCIM 用户列表：没有过滤条件时在数据库中分页；
+// 否则先按 id、userName 和 externalId 的 eq 比较在数据库中缩小范围，再精确过滤和分页
+func ListSCIMUsers(params *SCIMListParams, baseURL string) (*models.SCIMListResponse, error) {
+	query := utils.DB.Model(&models.Employee{}).Session(&gorm.Session{})
+	if params.Filter == nil {
+		total, page, err := paginateSCIMQuery(query, params)
+		if err != nil {
+			return nil, err
+		}
+		users, err := loadSCIMUsers(baseURL, page)
+		if err != nil {
+			return nil, err
+		}
+		return listSCIMPage(scimUserResources(users), total, params)
+	}
+
+	users, err := loadSCIMUsers(baseURL, narrowSCIMUserQuery(query, utils.SCIMFilterEqualities(params.Filter)))
+	if err != nil {
+		return nil, err
+	}
+	return listSCIMResources(scimUserResources(users), params)
+}
+
+// 按过滤条件中的 eq 比较缩小员工查询的范围，结果仍需用过滤条件精确匹配
+func narrowSCIMUserQuery(query *gorm.DB, equalities map[string]string) *gorm.DB {
+	if empNo, ok := scimFilterID(equalities); ok {
+		query = query.Where("EmpNo = ?", empNo)
+	}
+	if userName, ok := equalities["username"]; ok {
+		// 没有用户账号的员工以员工编号作为 userName
+		accounts := utils.DB.Model(&models.User{}).Select("EmpNo").Where("Username = ? AND EmpNo IS NOT NULL", userName)
+		if empNo, err := strconv.Atoi(userName); err == nil {
+			query = query.Where("EmpNo IN (?) OR EmpNo = ?", accounts, empNo)
+		} else {
+			query = query.Where("EmpNo IN (?)", accounts)
+		}
+	}
+	if externalID, ok := equalities["externalid"]; ok {
+		query = query.Where("EmpNo IN (?)", scimExternalIDSubQuery(models.SCIMResourceUser, externalID))
+	}
+	return query
+}
+
+func scimUserResources(users []models.SCIMUser) []interface{} {
+	resources := make([]interface{}, len(users))
+	for i := range users {
+		resources[i] = users[i]
+	}
+	return resources
+}
+
+// 获取单个 SCIM 用户
+func GetSCIMUser(id, baseURL string) (*models.SCIMUser, error) {
+	empNo, err := parseSCIMID(id, models.SCIMResourceUser)
+	if err != nil {
+		return nil, err
+	}
+	users, err := loadSCIMUsers(baseURL, utils.DB.Where("EmpNo = ?", empNo))
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, utils.NewSCIMError(http.StatusNotFound, "", "User "+id+" 不存在")
+	}
+	return &users[0], nil
+}
+
+// 校验 SCIM 用户的属性并转换为员工和用户账号的字段
+func parseSCIMUserFields(req *models.SCIMUser) (*scimUserFields, error) {
+	invalid := func(detail string) error {
+		return utils.NewSCIMError(http.StatusBadRequest, "invalidValue", detail)
+	}
+
+	f := &scimUserFields{
+		UserName:   strings.TrimSpace(req.UserName),
+		Active:     req.Active,
+		ExternalID: strings.TrimSpace(req.ExternalID),
+	}
+	if f.UserName == "" {
+		return nil, invalid("userName 不能为空")
+	}
+	if utf8.RuneCountInString(f.UserName) > 50 {
+		return nil, invalid("userName 不能超过50个字符")
+	}
+
+	// 没有分开的姓和名时，将显示名称的第一个字作为姓
+	if req.Name != nil && strings.TrimSpace(req.Name.FamilyName) != "" && strings.TrimSpace(req.Name.GivenName) != "" {
+		f.LastName, f.FirstName = strings.TrimSpace(req.Name.FamilyName), strings.TrimSpace(req.Name.GivenName)
+	} else {
+		display := strings.TrimSpace(req.DisplayName)
+		if req.Name != nil && strings.TrimSpace(req.Name.Formatted) != "" {
+			display = strings.TrimSpace(req.Name.Formatted)
+		}
+		if utf8.RuneCountInString(display) < 2 {
+			return nil, invalid("必须提供 name.familyName 和 name.givenName")
+		}
+		_, size := utf8.DecodeRuneInString(display)
+		f.LastName, f.FirstName = display[:size], strings.TrimSpace(display[size:])
+	}
+	if utf8.RuneCountInString(f.LastName) > 30 || utf8.RuneCountInString(f.FirstName) > 30 {
+		return nil, invalid("姓和名均不能超过30个字符")
+	}
+
+	if phone := preferredSCIMValue(req.PhoneNumbers); phone != "" {
+		f.Telephone = normalizeTelephone(phone)
+		if len(f.Telephone) > 20 {
+			return nil, invalid("电话号码不能超过20个字符")
+		}
+	}
+	if a := preferredSCIMAddress(req.Addresses); a != nil {
+		f.Address = strings.TrimSpace(a.Formatted)
+		if f.Address == "" {
+			f.Address = strings.TrimSpace(a.StreetAddress)
+		}
+	}
+	if utf8.RuneCountInString(f.Address) > 200 {
+		return nil, invalid("地址不能超过200个字符")
+	}
+
+	seen := make(map[string]bool)
+	primary := -1
+	for _, e := range req.Emails {
+		address := strings.ToLower(strings.TrimSpace(e.Value))
+		if parsed, err := mail.ParseAddress(address); err != nil || parsed.Address != address || len(address) > 100 {
+			return nil, invalid("无效的邮箱地址: " + e.Value)
+		}
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		emailType := models.EmailWork
+		if e.Type == "home" || e.Type == "other" {
+			emailType = models.EmailPersonal
+		}
+		if e.Primary && primary < 0 {
+			primary = len(f.Emails)
+		}
+		f.Emails = append(f.Emails, models.EmployeeEmail{Email: address, EmailType: emailType})
+	}
+	if len(f.Emails) > 0 {
+		if primary < 0 {
+			primary = 0
+		}
+		f.Emails[primary].IsPrimary = true
+	}
+
+	if req.Enterprise != nil && req.Enterprise.Manager != nil && req.Enterprise.Manager.Value != "" {
+		managerEmpNo, err := strconv.Atoi(req.Enterprise.Manager.Value)
+		if err != nil {
+			return nil, invalid("无效的直属上级: " + req.Enterprise.Manager.Value)
+		}
+		f.ManagerEmpNo = &managerEmpNo
+	}
+
+	if req.Password != "" {
+		f.PasswordHash = hashSCIMPassword(req.Password)
+	}
+	return f, nil
+}
+
+// 多值属性中的首选值：优先使用 primary，其次是 work 类型，最后是第一项
+func preferredSCIMValue(values []models.SCIMMultiValue) string {
+	best := -1
+	for i, v := range values {
+		switch {
+		case v.Primary:
+			return strings.TrimSpace(v.Value)
+		case best < 0, v.Type == "work" && values[best].Type != "work":
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return strings.TrimSpace(values[best].Value)
+}
+
+// 首选地址，规则与 preferredSCIMValue 相同
+func preferredSCIMAddress(addresses []models.SCIMAddress) *models.SCIMAddress {
+	best := -1
+	for i, a := range addresses {
+		switch {
+		case a.Primary:
+			return &addresses[i]
+		case best < 0, a.Type == "work" && addresses[best].Type != "work":
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return &addresses[best]
+}
+
+// 与前端登录时一致，密码以 SHA-256 的十六进制形式保存
+func hashSCIMPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// 身份提供方没有提供密码时使用随机密码，用户需由管理员重置密码或通过单点登录访问
+func randomSCIMPasswordHash() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", errors.New("生成随机密码失败")
+	}
+	return hashSCIMPassword(hex.EncodeToString(raw)), nil
+}
+
+// userName 是否已被其他用户账号使用
+func checkSCIMUserNameAvailable(tx *gorm.DB, userName string, excludeUserID int) error {
+	var count int64
+	if err := tx.Model(&models.User{}).
+		Where("Username = ? AND UserID != ?", userName, excludeUserID).
+		Count(&count).Error; err != nil {
+		return errors.New("检查用户名失败")
+	}
+	if count > 0 {
+		return utils.NewSCIMError(http.StatusConflict, "uniqueness", "userName "+userName+" 已存在")
+	}
+	return nil
+}
+
+// 用 SCIM 用户中的邮箱替换员工的邮箱，没有变化时不修改
+func syncSCIMEmails(tx *gorm.DB, empNo int, emails []models.EmployeeEmail) error {
+	var existing []models.EmployeeEmail
+	if err := tx.Where("EmpNo = ?", empNo).Order("EmailID").Find(&existing).Error; err != nil {
+		return errors.New("获取员工邮箱失败")
+	}
+	if len(existing) == len(emails) {
+		unchanged := true
+		current := make(map[string]models.EmployeeEmail, len(existing))
+		for _, e := range existing {
+			current[e.Email] = e
+		}
+		for _, e := range emails {
+			if c, ok := current[e.Email]; !ok || c.EmailType != e.EmailType || c.IsPrimary != e.IsPrimary {
+				unchanged = false
+				break
+			}
+		}
+		if unchanged {
+			return nil
+		}
+	}
+
+	if err := tx.Where("EmpNo = ?", empNo).Delete(&models.EmployeeEmail{}).Error; err != nil {
+		return errors.New("保存员工邮箱失败")
+	}
+	for _, e := range emails {
+		e.EmpNo = empNo
+		if err := tx.Create(&e).Error; err != nil {
+			return errors.New("保存员工邮箱失败")
+		}
+	}
+	return nil
+}
+
+// 创建 SCIM 用户：新建员工（入职日期为当天）和关联的用户账号
+func CreateSCIMUser(req *models.SCIMUser, baseURL string) (*models.SCIMUser, error) {
+	f, err := parseSCIMUserFields(req)
+	if err != nil {
+		return nil, err
+	}
+	if f.PasswordHash == "" {
+		if f.PasswordHash, err = randomSCIMPasswordHash(); err != nil {
+			return nil, err
+		}
+	}
+
+	emp := models.Employee{
+		FirstName:        f.FirstName,
+		LastName:         f.LastName,
+		Telephone:        f.Telephone,
+		Address:          f.Address,
+		HireDate:         truncateToDay(time.Now()),
+		ManagerEmpNo:     f.ManagerEmpNo,
+		EmploymentStatus: models.EmploymentActive,
+	}
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkSCIMUserNameAvailable(tx, f.UserName, 0); err != nil {
+			return err
+		}
+		if f.ManagerEmpNo != nil {
+			if err := validateEmployeeManager(tx, 0, *f.ManagerEmpNo); err != nil {
+				return scimInvalidValue(err)
+			}
+		}
+		if err := tx.Create(&emp).Error; err != nil {
+			return errors.New("创建员工失败")
+		}
+		if err := createOnboardingTasks(tx, &emp, nil); err != nil {
+			return errors.New("生成入职任务失败")
+		}
+
+		// active 为 false 时只停用账号，员工保持待入职的在职状态
+		account := models.User{
+			Username:     f.UserName,
+			PasswordHash: f.PasswordHash,
+			Role:         "User",
+			EmpNo:        &emp.EmpNo,
+			Disabled:     f.Active != nil && !*f.Active,
+		}
+		if err := tx.Create(&account).Error; err != nil {
+			return errors.New("创建用户账号失败")
+		}
+		if err := syncSCIMEmails(tx, emp.EmpNo, f.Emails); err != nil {
+			return err
+		}
+		if err := saveSCIMExternalID(tx, models.SCIMResourceUser, emp.EmpNo, f.ExternalID); err != nil {
+			return errors.New("保存 externalId 失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetSCIMUser(strconv.Itoa(emp.EmpNo), baseURL)
+}
+
+// 替换 SCIM 用户（PUT），groups、employeeNumber 和 department 为只读属性，
+// active 改为 false 时按离职处理，改为 true 时恢复在职并启用账号
+func ReplaceSCIMUser(id string, req *models.SCIMUser, baseURL string) (*models.SCIMUser, error) {
+	empNo, err := parseSCIMID(id, models.SCIMResourceUser)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parseSCIMUserFields(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var emp models.Employee
+	var account *models.User
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&emp, empNo).Error; err != nil {
+			return utils.NewSCIMError(http.StatusNotFound, "", "User "+id+" 不存在")
+		}
+		var users []models.User
+		if err := tx.Where("EmpNo = ?", empNo).Order("UserID").Limit(1).Find(&users).Error; err != nil {
+			return errors.New("获取用户账号失败")
+		}
+
+		excludeUserID := 0
+		if len(users) > 0 {
+			account = &users[0]
+			excludeUserID = account.UserID
+		}
+		if err := checkSCIMUserNameAvailable(tx, f.UserName, excludeUserID); err != nil {
+			return err
+		}
+		if f.ManagerEmpNo != nil {
+			if err := validateEmployeeManager(tx, empNo, *f.ManagerEmpNo); err != nil {
+				return scimInvalidValue(err)
+			}
+		}
+
+		if err := tx.Model(&emp).Updates(map[string]interface{}{
+			"FirstName":    f.FirstName,
+			"LastName":     f.LastName,
+			"Telephone":    f.Telephone,
+			"Address":      f.Address,
+			"ManagerEmpNo": f.ManagerEmpNo,
+		}).Error; err != nil {
+			return errors.New("更新员工失败")
+		}
+
+		if account == nil {
+			passwordHash := f.PasswordHash
+			if passwordHash == "" {
+				if passwordHash, err = randomSCIMPasswordHash(); err != nil {
+					return err
+				}
+			}
+			account = &models.User{
+				Username:     f.UserName,
+				PasswordHash: passwordHash,
+				Role:         "User",
+				EmpNo:        &emp.EmpNo,
+				Disabled:     emp.EmploymentStatus == models.EmploymentTerminated,
+			}
+			if err := tx.Create(account).Error; err != nil {
+				return errors.New("创建用户账号失败")
+			}
+		} else {
+			updates := map[string]interface{}{"Username": f.UserName}
+			if f.PasswordHash != "" {
+				updates["PasswordHash"] = f.PasswordHash
+			}
+			if err := tx.Model(account).Updates(updates).Error; err != nil {
+				return errors.New("更新用户账号失败")
+			}
+		}
+
+		if err := syncSCIMEmails(tx, empNo, f.Emails); err != nil {
+			return err
+		}
+		if err := saveSCIMExternalID(tx, models.SCIMResourceUser, empNo, f.ExternalID); err != nil {
+			return errors.New("保存 externalId 失败")
+		}
+		if f.Active != nil {
+			return setSCIMUserActive(tx, &emp, account, *f.Active)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetSCIMUser(id, baseURL)
+}
+
+// 修改用户的启用状态：停用在职员工时按当天离职处理，
+// 启用离职员工时恢复在职（部门关系需要重新分配）
+func setSCIMUserActive(tx *gorm.DB, emp *models.Employee, account *models.User, active bool) error {
+	terminated := emp.EmploymentStatus == models.EmploymentTerminated
+	switch {
+	case !active && !terminated && !account.Disabled:
+		_, err := terminateEmployee(tx, emp.EmpNo, &models.EmployeeTerminationRequest{
+			TerminationDate: dateOnly(time.Now()),
+			Reason:          scimTerminationReason,
+		})
+		return scimInvalidValue(err)
+	case active && terminated:
+		if err := tx.Model(emp).Updates(map[string]interface{}{
+			"EmploymentStatus":  models.EmploymentActive,
+			"TerminationDate":   nil,
+			"TerminationReason": "",
+		}).Error; err != nil {
+			return errors.New("恢复员工在职状态失败")
+		}
+		if err := tx.Model(&models.User{}).Where("EmpNo = ?", emp.EmpNo).
+			Update("Disabled", false).Error; err != nil {
+			return errors.New("启用用户账号失败")
+		}
+	case active && account.Disabled:
+		if err := tx.Model(account).Update("Disabled", false).Error; err != nil {
+			return errors.New("启用用户账号失败")
+		}
+	}
+	return nil
+}
+
+// 按 RFC 7644 3.5.2 修改 SCIM 用户
+func PatchSCIMUser(id string, ops []models.SCIMPatchOperation, baseURL string) (*models.SCIMUser, error) {
+	current, err := GetSCIMUser(id, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := scimResourceMap(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ApplySCIMPatch(resource, ops); err != nil {
+		return nil, err
+	}
+
+	// 部分身份提供方以字符串 "True"/"False" 表示 active
+	for k, v := range resource {
+		if s, ok := v.(string); ok && strings.EqualFold(k, "active") {
+			active, err := strconv.ParseBool(strings.ToLower(s))
+			if err != nil {
+				return nil, utils.NewSCIMError(http.StatusBadRequest, "invalidValue", "无效的 active: "+s)
+			}
+			resource[k] = active
+		}
+	}
+
+	var patched models.SCIMUser
+	if err := scimResourceFromMap(resource, &patched); err != nil {
+		return nil, err
+	}
+	return ReplaceSCIMUser(id, &patched, baseURL)
+}
+
+// 删除 SCIM 用户：删除员工及其所有记录和关联的用户账号
+func DeleteSCIMUser(id string) error {
+	empNo, err := parseSCIMID(id, models.SCIMResourceUser)
+	if err != nil {
+		return err
+	}
+	var attachments []models.EmployeeAttachment
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		var emp models.Employee
+		if err := tx.Select("EmpNo").First(&emp, empNo).Error; err != nil {
+			return utils.NewSCIMError(http.StatusNotFound, "", "User "+id+" 不存在")
+		}
+		var err error
+		if attachments, err = deleteEmployeeRecords(tx, empNo); err != nil {
+			return err
+		}
+		if err := tx.Where("EmpNo = ?", empNo).Delete(&models.User{}).Error; err != nil {
+			return errors.New("删除用户账号失败")
+		}
+		if err := saveSCIMExternalID(tx, models.SCIMResourceUser, empNo, ""); err != nil {
+			return errors.New("删除 externalId 失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	deleteEmployeeAttachmentObjects(attachments)
+	return nil
+}
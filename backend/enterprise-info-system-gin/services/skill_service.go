@@ -0,0 +1,212 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 获取技能目录，category 不为空时只返回该分类的技能
+func GetSkills(category string) ([]models.Skill, error) {
+	query := utils.DB.Order("Category, SkillName")
+	if category != "" {
+		query = query.Where("Category = ?", category)
+	}
+
+	skills := []models.Skill{}
+	if err := query.Find(&skills).Error; err != nil {
+		return nil, errors.New("获取技能目录失败")
+	}
+	return skills, nil
+}
+
+// 创建技能
+func CreateSkill(req *models.SkillRequest) (*models.Skill, error) {
+	skill := &models.Skill{}
+	if err := applySkillRequest(skill, req); err != nil {
+		return nil, err
+	}
+	if err := utils.DB.Create(skill).Error; err != nil {
+		return nil, errors.New("创建技能失败")
+	}
+	return skill, nil
+}
+
+// 更新技能
+func UpdateSkill(id int, req *models.SkillRequest) (*models.Skill, error) {
+	var skill models.Skill
+	if err := utils.DB.First(&skill, id).Error; err != nil {
+		return nil, errors.New("技能不存在")
+	}
+	if err := applySkillRequest(&skill, req); err != nil {
+		return nil, err
+	}
+	if err := utils.DB.Save(&skill).Error; err != nil {
+		return nil, errors.New("更新技能失败")
+	}
+	return &skill, nil
+}
+
+// 删除技能，同时删除员工的该项技能
+func DeleteSkill(id int) error {
+	return utils.DB.Transaction(func(tx *gorm.DB) error {
+		var skill models.Skill
+		if err := tx.First(&skill, id).Error; err != nil {
+			return errors.New("技能不存在")
+		}
+		if err := tx.Where("SkillID = ?", id).Delete(&models.EmployeeSkill{}).Error; err != nil {
+			return errors.New("删除员工技能失败")
+		}
+		if err := tx.Delete(&skill).Error; err != nil {
+			return errors.New("删除技能失败")
+		}
+		return nil
+	})
+}
+
+// 校验技能请求并写入技能
+func applySkillRequest(skill *models.Skill, req *models.SkillRequest) error {
+	name := strings.TrimSpace(req.SkillName)
+	if name == "" {
+		return errors.New("技能名称不能为空")
+	}
+	if utf8.RuneCountInString(name) > 50 {
+		return errors.New("技能名称不能超过50个字符")
+	}
+	category := strings.TrimSpace(req.Category)
+	if utf8.RuneCountInString(category) > 50 {
+		return errors.New("技能分类不能超过50个字符")
+	}
+	if utf8.RuneCountInString(req.Description) > 200 {
+		return errors.New("技能说明不能超过200个字符")
+	}
+
+	var duplicate int64
+	if err := utils.DB.Model(&models.Skill{}).Where("SkillName = ? AND SkillID != ?", name, skill.SkillID).
+		Count(&duplicate).Error; err != nil {
+		return errors.New("检查技能名称失败")
+	}
+	if duplicate > 0 {
+		return errors.New("技能名称已存在")
+	}
+
+	skill.SkillName = name
+	skill.Category = category
+	skill.Description = req.Description
+	return nil
+}
+
+// 获取员工的技能，按熟练度从高到低排序
+func GetEmployeeSkills(empNo int) ([]models.EmployeeSkillDetail, error) {
+	var emp models.Employee
+	if err := utils.DB.First(&emp, empNo).Error; err != nil {
+		return nil, errors.New("员工不存在")
+	}
+
+	skills := []models.EmployeeSkillDetail{}
+	if err := utils.DB.Table("Employee_Skills es").
+		Select("es.*, s.SkillName, COALESCE(s.Category, '') as Category").
+		Joins("INNER JOIN Skills s ON es.SkillID = s.SkillID").
+		Where("es.EmpNo = ?", empNo).
+		Order("es.Level DESC, s.SkillName").
+		Scan(&skills).Error; err != nil {
+		return nil, errors.New("获取员工技能失败")
+	}
+	return skills, nil
+}
+
+// 设置员工的技能熟练度，已有该技能时更新熟练度
+func SetEmployeeSkill(empNo int, req *models.EmployeeSkillRequest, user *models.User) (*models.EmployeeSkill, error) {
+	if req.Level < models.SkillLevelMin || req.Level > models.SkillLevelMax {
+		return nil, errors.New("熟练度必须在" + strconv.Itoa(models.SkillLevelMin) + "到" + strconv.Itoa(models.SkillLevelMax) + "之间")
+	}
+
+	skill := &models.EmployeeSkill{EmpNo: empNo, SkillID: req.SkillID, Level: req.Level}
+	err := utils.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkProfileAccess(tx, user, empNo, "skills", true); err != nil {
+			return err
+		}
+		var s models.Skill
+		if err := tx.First(&s, req.SkillID).Error; err != nil {
+			return errors.New("技能不存在")
+		}
+		if err := tx.Save(skill).Error; err != nil {
+			return errors.New("保存员工技能失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skill, nil
+}
+
+// 删除员工的技能
+func RemoveEmployeeSkill(empNo, skillID int, user *models.User) error {
+	if err := checkProfileAccess(utils.DB, user, empNo, "skills", true); err != nil {
+		return err
+	}
+	result := utils.DB.Where("EmpNo = ? AND SkillID = ?", empNo, skillID).Delete(&models.EmployeeSkill{})
+	if result.Error != nil {
+		return errors.New("删除员工技能失败")
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("该员工没有这项技能")
+	}
+	return nil
+}
+
+// 解析搜索参数中的技能条件，格式为 [{"skillId": 1, "minLevel": 3}, ...]，
+// 无法识别的条件会被忽略
+func parseSkillCriteria(value interface{}) []models.SkillCriterion {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	criteria := make([]models.SkillCriterion, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		skillID, ok := m["skillId"].(float64)
+		if !ok || skillID <= 0 {
+			continue
+		}
+		minLevel, _ := m["minLevel"].(float64)
+		if minLevel < models.SkillLevelMin {
+			minLevel = models.SkillLevelMin
+		}
+		criteria = append(criteria, models.SkillCriterion{SkillID: int(skillID), MinLevel: int(minLevel)})
+	}
+	return criteria
+}
+
+// 按技能条件筛选员工：matchAny 为 false 时须满足全部条件，否则满足任一条件即可
+func applySkillCriteria(query *gorm.DB, criteria []models.SkillCriterion, matchAny bool) *gorm.DB {
+	if len(criteria) == 0 {
+		return query
+	}
+
+	const exists = "EXISTS (SELECT 1 FROM Employee_Skills es WHERE es.EmpNo = Employees.EmpNo AND es.SkillID = ? AND es.Level >= ?)"
+	if !matchAny {
+		for _, c := range criteria {
+			query = query.Where(exists, c.SkillID, c.MinLevel)
+		}
+		return query
+	}
+
+	conditions := make([]string, 0, len(criteria))
+	args := make([]interface{}, 0, len(criteria)*2)
+	for _, c := range criteria {
+		conditions = append(conditions, exists)
+		args = append(args, c.SkillID, c.MinLevel)
+	}
+	return query.Where(strings.Join(conditions, " OR "), args...)
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"enterprise-info-system-gin/models"
+	"enterprise-info-system-gin/utils"
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 员工调岗：在同一事务中结束原部门关系、建立新部门关系并记录调岗原因，
+// 原有的部门关系保留为历史记录，部门人数由触发器更新
+func TransferEmployee(empNo int, req *models.EmployeeTransferRequest) (*models.EmployeeTransfer, error) {
+	effectiveDate, err := time.Parse("2006-01-02", req.EffectiveDate)
+	if err != nil {
+		return nil, errors.New("无效的生效日期格式")
+	}
+	// 部门关系在调岗时立即变更，不支持预约未来生效的调岗
+	if dateOnly(effectiveDate) > dateOnly(time.Now()) {
+		return nil, errors.New("生效日期不能晚于今天")
+	}
+	if utf8.RuneCountInString(req.Reason) > 200 {
+		return nil, errors.New("调岗原因不能超过200个字符")
+	}
+
+	var transfer *models.EmployeeTransfer
+	err = utils.DB.Transaction(func(tx *gorm.DB) error {
+		var emp models.Employee
+		if err := tx.First(&emp, empNo).Error; err != nil {
+			return errors.New("员工不存在")
+		}
+
+		var toDept models.Department
+		if err := tx.First(&toDept, req.ToDeptNo).Error; err != nil {
+			return errors.New("目标部门不存在")
+		}
+
+		// 确定调出的部门关系
+		var current []models.EmployeeDepartment
+		query := tx.Where("EmpNo = ? AND EdStatus = 1", empNo)
+		if req.FromDeptNo != 0 {
+			query = query.Where("DeptNo = ?", req.FromDeptNo)
+		}
+		if err := query.Find(&current).Error; err != nil {
+			return errors.New("获取员工部门关系失败")
+		}
+		if len(current) == 0 {
+			return errors.New("员工不在原部门中")
+		}
+		if len(current) > 1 {
+			return errors.New("员工同时在多个部门任职，请指定调出部门")
+		}
+		from := current[0]
+
+		if from.DeptNo == req.ToDeptNo {
+			return errors.New("调入部门不能与调出部门相同")
+		}
+		if effectiveDate.Before(from.EdEntryDate) {
+			return errors.New("生效日期不能早于员工加入原部门的日期")
+		}
+
+		var existing int64
+		if err := tx.Model(&models.EmployeeDepartment{}).
+			Where("EmpNo = ? AND DeptNo = ? AND EdStatus = 1", empNo, req.ToDeptNo).
+			Count(&existing).Error; err != nil {
+			return errors.New("获取员工部门关系失败")
+		}
+		if existing > 0 {
+			return errors.New("该员工已在目标部门中")
+		}
+
+		// 新的部门关系同样需要满足生命周期约束
+		to := models.EmployeeDepartment{
+			EmpNo:       empNo,
+			DeptNo:      req.ToDeptNo,
+			EdEntryDate: effectiveDate,
+			EdStatus:    1,
+			PositionID:  req.ToPositionID,
+		}
+		verr := &ValidationError{}
+		if err := validateEmployeeDepartment(tx, &to, &emp, verr); err != nil {
+			return err
+		}
+		if verr.HasErrors() {
+			return verr
+		}
+
+		// 结束原部门关系
+		if err := tx.Model(&from).Updates(map[string]interface{}{
+			"EdStatus":    2,
+			"EdLeaveDate": effectiveDate,
+		}).Error; err != nil {
+			return errors.New("结束原部门关系失败")
+		}
+		if err := endManagerAssignments(tx, empNo, from.DeptNo, effectiveDate); err != nil {
+			return errors.New("结束部门经理任期失败")
+		}
+
+		// 建立新部门关系
+		if err := tx.Create(&to).Error; err != nil {
+			return errors.New("建立新部门关系失败")
+		}
+		if err := createOnboardingTasks(tx, &emp, &to.DeptNo); err != nil {
+			return errors.New("生成入职任务失败")
+		}
+
+		transfer = &models.EmployeeTransfer{
+			EmpNo:         empNo,
+			FromDeptNo:    from.DeptNo,
+			ToDeptNo:      to.DeptNo,
+			FromEdID:      from.EdID,
+			ToEdID:        to.EdID,
+			EffectiveDate: effectiveDate,
+			Reason:        req.Reason,
+		}
+		if err := tx.Create(transfer).Error; err != nil {
+			return errors.New("记录调岗信息失败")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// 获取员工的调岗记录：员工本人、上级或管理员可以查看
+func GetEmployeeTransfers(empNo int, user *models.User) ([]models.EmployeeTransferDetail, error) {
+	if err := checkProfileAccess(utils.DB, user, empNo, "transfers", false); err != nil {
+		return nil, err
+	}
+	var transfers []models.EmployeeTransferDetail
+	err := utils.DB.Raw(`
+		SELECT t.*, fd.DeptName as FromDeptName, td.DeptName as ToDeptName
+		FROM Employee_Transfers t
+		LEFT JOIN Departments fd ON t.FromDeptNo = fd.DeptNo
+		LEFT JOIN Departments td ON t.ToDeptNo = td.DeptNo
+		WHERE t.EmpNo = ?
+		ORDER BY t.EffectiveDate DESC, t.TransferID DESC
+	`, empNo).Scan(&transfers).Error
+	if err != nil {
+		return nil, errors.New("获取调岗记录失败")
+	}
+	return transfers, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import "strings"
+
+// 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// 字段校验错误集合，控制器会将其转换为带 fields 的 400 响应
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+	return strings.Join(messages, "；")
+}
+
+// 添加一个字段错误
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// 是否存在错误
+func (e *ValidationError) HasErrors() bool {
+	return len(e.Fields) > 0
+}
+
+// 没有错误时返回 nil，便于直接作为 error 返回
+func (e *ValidationError) Err() error {
+	if !e.HasErrors() {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// 加密字段的前缀，便于以后更换算法或密钥
+const encryptedPrefix = "v1:"
+
+// 字段加密使用的 AES-GCM，在连接数据库时初始化
+var fieldCipher cipher.AEAD
+
+// 字段加密密钥从 FIELD_ENCRYPTION_KEY 环境变量读取，经 SHA-256 生成 AES-256 密钥。
+// 未设置时拒绝启动，否则加密的证件号和银行卡号可以用公开的默认密钥解密
+func initFieldCipher() {
+	secret := os.Getenv("FIELD_ENCRYPTION_KEY")
+	if secret == "" {
+		log.Fatal("未设置 FIELD_ENCRYPTION_KEY 环境变量，无法加密员工敏感信息")
+	}
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		log.Fatal("初始化字段加密失败:", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		log.Fatal("初始化字段加密失败:", err)
+	}
+	fieldCipher = aead
+}
+
+var errFieldCipherNotReady = errors.New("字段加密未初始化")
+
+// 使用 AES-GCM 加密字符串，结果为 v1:base64(随机数+密文)
+func EncryptString(plain string) (string, error) {
+	if fieldCipher == nil {
+		return "", errFieldCipherNotReady
+	}
+	nonce := make([]byte, fieldCipher.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := fieldCipher.Seal(nonce, nonce, []byte(plain), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// 解密 EncryptString 的结果
+func DecryptString(encrypted string) (string, error) {
+	if fieldCipher == nil {
+		return "", errFieldCipherNotReady
+	}
+	data, ok := strings.CutPrefix(encrypted, encryptedPrefix)
+	if !ok {
+		return "", errors.New("无法识别的加密数据")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < fieldCipher.NonceSize() {
+		return "", errors.New("无法识别的加密数据")
+	}
+	nonce, ciphertext := sealed[:fieldCipher.NonceSize()], sealed[fieldCipher.NonceSize():]
+	plain, err := fieldCipher.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("解密失败")
+	}
+	return string(plain), nil
+}
+
+// 隐藏号码中间部分，只保留前后各 keep 位
+func MaskString(s string, keep int) string {
+	runes := []rune(s)
+	if len(runes) <= keep*2 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:keep]) + strings.Repeat("*", len(runes)-keep*2) + string(runes[len(runes)-keep:])
+}
@@ -13,6 +13,9 @@ import (
 var DB *gorm.DB
 
 func InitDB() {
+    // 加密字段读写依赖数据库，密钥缺失时在启动阶段失败
+    initFieldCipher()
+
     username := "root"        // 数据库用户名
     password := "123456"     // 数据库密码
     host := "127.0.0.1"      // 数据库主机地址
@@ -25,6 +28,7 @@ func InitDB() {
     DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
         Logger: logger.Default.LogMode(logger.Info),
         DisableForeignKeyConstraintWhenMigrating: true, // 禁用 GORM 的外键约束
+        TranslateError: true, // 将唯一键冲突等数据库错误转换为 gorm.ErrDuplicatedKey 等通用错误
     })
     if err != nil {
         log.Fatal("数据库连接失败:", err)
@@ -38,6 +42,34 @@ func InitDB() {
         &models.Department{},
         &models.Employee{},
         &models.EmployeeDepartment{},
+        &models.Job{},
+        &models.DepartmentManager{},
+        &models.EmployeeTransfer{},
+        &models.OnboardingTemplate{},
+        &models.OnboardingTask{},
+        &models.Position{},
+        &models.Salary{},
+        &models.LeaveRequest{},
+        &models.AttendanceRecord{},
+        &models.AttendanceSettings{},
+        &models.EmployeeAttachment{},
+        &models.EmployeeContract{},
+        &models.Notification{},
+        &models.NotificationRead{},
+        &models.EmergencyContact{},
+        &models.EmployeeEmail{},
+        &models.IdentityDocument{},
+        &models.EducationRecord{},
+        &models.BankAccount{},
+        &models.Skill{},
+        &models.EmployeeSkill{},
+        &models.ReviewCycle{},
+        &models.PerformanceReview{},
+        &models.HeadcountSnapshot{},
+        &models.CalendarPreference{},
+        &models.CalendarFeedKey{},
+        &models.SCIMToken{},
+        &models.SCIMExternalID{},
     )
     if err != nil {
         log.Fatal("数据库迁移失败:", err)
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 日历中的全天事件
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Date        time.Time // 事件日期（重复事件为首次发生的日期）
+	Yearly      bool      // 是否每年重复
+}
+
+// 生成 iCalendar（RFC 5545）格式的日历，name 为订阅后显示的日历名称。
+// 每年重复的 2 月 29 日事件在平年按 2 月最后一天发生
+func WriteICalendar(w io.Writer, name string, events []ICalEvent) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeFoldedLine(bw, "BEGIN:VCALENDAR")
+	writeFoldedLine(bw, "VERSION:2.0")
+	writeFoldedLine(bw, "PRODID:-//enterprise-info-system//calendar//ZH")
+	writeFoldedLine(bw, "CALSCALE:GREGORIAN")
+	writeFoldedLine(bw, "METHOD:PUBLISH")
+	writeFoldedLine(bw, "X-WR-CALNAME:"+escapeICalText(name))
+	for _, e := range events {
+		writeFoldedLine(bw, "BEGIN:VEVENT")
+		writeFoldedLine(bw, "UID:"+e.UID)
+		writeFoldedLine(bw, "DTSTAMP:"+stamp)
+		writeFoldedLine(bw, "DTSTART;VALUE=DATE:"+e.Date.Format("20060102"))
+		writeFoldedLine(bw, "DTEND;VALUE=DATE:"+e.Date.AddDate(0, 0, 1).Format("20060102"))
+		if e.Yearly {
+			if e.Date.Month() == time.February && e.Date.Day() == 29 {
+				writeFoldedLine(bw, "RRULE:FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1")
+			} else {
+				writeFoldedLine(bw, "RRULE:FREQ=YEARLY")
+			}
+		}
+		writeFoldedLine(bw, "SUMMARY:"+escapeICalText(e.Summary))
+		if e.Description != "" {
+			writeFoldedLine(bw, "DESCRIPTION:"+escapeICalText(e.Description))
+		}
+		writeFoldedLine(bw, "TRANSP:TRANSPARENT")
+		writeFoldedLine(bw, "END:VEVENT")
+	}
+	writeFoldedLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// 转义文本值中的反斜杠、分号、逗号和换行
+func escapeICalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// 写入一行，超过 75 个字节时折行，不拆开多字节字符（iCalendar 和 vCard 的折行规则相同）
+func writeFoldedLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // 续行开头的空格占一个字节
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// LDIF 中的一个条目
+type LDIFEntry struct {
+	DN         string
+	Attributes []LDIFAttribute
+}
+
+// 条目的属性，同名属性可以出现多次
+type LDIFAttribute struct {
+	Name  string
+	Value string
+}
+
+// 按 LDIF（RFC 2849）格式写入条目，包含非 ASCII 字符等不安全的值使用 base64 编码
+func WriteLDIF(w io.Writer, entries []LDIFEntry) error {
+	bw := bufio.NewWriter(w)
+	writeLDIFLine(bw, "version: 1")
+	for _, e := range entries {
+		bw.WriteString("\n")
+		writeLDIFValue(bw, "dn", e.DN)
+		for _, a := range e.Attributes {
+			if a.Value != "" {
+				writeLDIFValue(bw, a.Name, a.Value)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// 转义 DN 中的属性值，例如 cn=张三\,李四
+func EscapeLDAPDNValue(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case strings.ContainsRune(`,+"\<>;=`, r):
+			sb.WriteByte('\\')
+		case r == '#' && i == 0, r == ' ' && (i == 0 || i == len(s)-1):
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+func writeLDIFValue(w *bufio.Writer, name, value string) {
+	if isSafeLDIFString(value) {
+		writeLDIFLine(w, name+": "+value)
+		return
+	}
+	writeLDIFLine(w, name+":: "+base64.StdEncoding.EncodeToString([]byte(value)))
+}
+
+// 可以直接写出的值：只含可打印 ASCII，不以空格、冒号或 "<" 开头，不以空格结尾
+func isSafeLDIFString(s string) bool {
+	if s == "" {
+		return true
+	}
+	if s[0] == ' ' || s[0] == ':' || s[0] == '<' || s[len(s)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// 写入一行，超过 76 个字节时折行，续行以一个空格开头
+func writeLDIFLine(w *bufio.Writer, line string) {
+	limit := 76
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\n ")
+		line = line[cut:]
+		limit = 75
+	}
+	w.WriteString(line)
+	w.WriteString("\n")
+}
@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A4 横向页面尺寸与排版参数（单位：pt）
+const (
+	pdfPageWidth   = 842.0
+	pdfPageHeight  = 595.0
+	pdfMargin      = 36.0
+	pdfTitleSize   = 14.0
+	pdfFontSize    = 9.0
+	pdfRowHeight   = 16.0
+	pdfCellPadding = 3.0
+)
+
+// 预先分配的对象编号，页面对象从 pdfFirstPageObj 开始依次编号
+const (
+	pdfCatalogObj   = 1
+	pdfPagesObj     = 2
+	pdfFontObj      = 3
+	pdfCIDFontObj   = 4
+	pdfFontDescObj  = 5
+	pdfFirstPageObj = 6
+)
+
+// 流式写出表格型 PDF：每写满一页就立即输出该页，最后补写页面树和交叉引用表。
+// 中文使用阅读器内置的 Adobe 宋体（STSong-Light），因此无需嵌入字体文件。
+type PDFTableWriter struct {
+	w       *countingWriter
+	title   string
+	headers []string
+	widths  []float64
+
+	offsets []int64
+	pages   []int
+	content bytes.Buffer
+	cursorY float64
+	pageNum int
+	err     error
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// 创建 PDF 表格写入器，widths 为各列的相对宽度
+func NewPDFTableWriter(w io.Writer, title string, headers []string, widths []float64) *PDFTableWriter {
+	// 按相对宽度分配可用宽度
+	total := 0.0
+	for i := range headers {
+		if i < len(widths) && widths[i] > 0 {
+			total += widths[i]
+		} else {
+			total++
+		}
+	}
+	available := pdfPageWidth - 2*pdfMargin
+	scaled := make([]float64, len(headers))
+	for i := range headers {
+		weight := 1.0
+		if i < len(widths) && widths[i] > 0 {
+			weight = widths[i]
+		}
+		scaled[i] = available * weight / total
+	}
+
+	p := &PDFTableWriter{
+		w:       &countingWriter{w: w},
+		title:   title,
+		headers: headers,
+		widths:  scaled,
+		offsets: make([]int64, pdfFirstPageObj),
+	}
+	p.write("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	p.writeObject(pdfCatalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObj))
+	p.writeObject(pdfFontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [%d 0 R] >>",
+		pdfCIDFontObj))
+	p.writeObject(pdfCIDFontObj, fmt.Sprintf(
+		"<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> "+
+			"/FontDescriptor %d 0 R /DW 1000 /W [1 95 500] >>", pdfFontDescObj))
+	p.writeObject(pdfFontDescObj,
+		"<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] "+
+			"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+	p.startPage()
+	return p
+}
+
+// 写入一行数据，当前页写满时自动换页并重复表头
+func (p *PDFTableWriter) WriteRow(values []string) error {
+	if p.err != nil {
+		return p.err
+	}
+	if p.cursorY-pdfRowHeight < pdfMargin {
+		p.finishPage()
+		p.startPage()
+	}
+	p.drawRow(values, false)
+	return p.err
+}
+
+// 输出最后一页、页面树和交叉引用表
+func (p *PDFTableWriter) Close() error {
+	if p.err != nil {
+		return p.err
+	}
+	p.finishPage()
+
+	kids := make([]string, len(p.pages))
+	for i, obj := range p.pages {
+		kids[i] = strconv.Itoa(obj) + " 0 R"
+	}
+	p.writeObject(pdfPagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(p.pages)))
+
+	infoObj := len(p.offsets)
+	p.offsets = append(p.offsets, 0)
+	p.writeObject(infoObj, fmt.Sprintf("<< /Title %s /Producer (enterprise-info-system) /CreationDate (D:%s) >>",
+		pdfUTF16String(p.title), time.Now().Format("20060102150405")))
+
+	xref := p.w.n
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("xref\n0 %d\n", len(p.offsets)))
+	sb.WriteString("0000000000 65535 f \n")
+	for _, off := range p.offsets[1:] {
+		sb.WriteString(fmt.Sprintf("%010d 00000 n \n", off))
+	}
+	sb.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(p.offsets), pdfCatalogObj, infoObj, xref))
+	p.write(sb.String())
+	return p.err
+}
+
+func (p *PDFTableWriter) startPage() {
+	p.pageNum++
+	p.content.Reset()
+	p.cursorY = pdfPageHeight - pdfMargin
+
+	if p.pageNum == 1 && p.title != "" {
+		p.drawText(pdfMargin, p.cursorY-pdfTitleSize, pdfTitleSize, p.title)
+		p.cursorY -= pdfTitleSize + 10
+	}
+	p.drawRow(p.headers, true)
+}
+
+// 将当前页的内容流和页面对象写出
+func (p *PDFTableWriter) finishPage() {
+	footer := "第 " + strconv.Itoa(p.pageNum) + " 页"
+	p.drawText(pdfPageWidth/2-textWidth(footer, pdfFontSize)/2, pdfMargin/2, pdfFontSize, footer)
+
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write(p.content.Bytes())
+	zw.Close()
+
+	contentObj := len(p.offsets)
+	pageObj := contentObj + 1
+	p.offsets = append(p.offsets, 0, 0)
+
+	p.writeStream(contentObj, compressed.Bytes())
+	p.writeObject(pageObj, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+		pdfPagesObj, pdfPageWidth, pdfPageHeight, pdfFontObj, contentObj))
+	p.pages = append(p.pages, pageObj)
+}
+
+func (p *PDFTableWriter) drawRow(values []string, header bool) {
+	top := p.cursorY
+	bottom := top - pdfRowHeight
+	tableWidth := pdfPageWidth - 2*pdfMargin
+
+	if header {
+		fmt.Fprintf(&p.content, "0.9 g %.2f %.2f %.2f %.2f re f 0 g\n", pdfMargin, bottom, tableWidth, pdfRowHeight)
+	}
+
+	x := pdfMargin
+	for i, width := range p.widths {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		value = truncateText(strings.Join(strings.Fields(value), " "), width-2*pdfCellPadding, pdfFontSize)
+		p.drawText(x+pdfCellPadding, bottom+(pdfRowHeight-pdfFontSize)/2+1, pdfFontSize, value)
+		x += width
+	}
+
+	fmt.Fprintf(&p.content, "0.5 w 0.6 G %.2f %.2f m %.2f %.2f l S 0 G\n",
+		pdfMargin, bottom, pdfMargin+tableWidth, bottom)
+	p.cursorY = bottom
+}
+
+func (p *PDFTableWriter) drawText(x, y, size float64, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(&p.content, "BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", size, x, y, pdfUCS2Hex(text))
+}
+
+func (p *PDFTableWriter) writeObject(num int, body string) {
+	p.offsets[num] = p.w.n
+	p.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", num, body))
+}
+
+func (p *PDFTableWriter) writeStream(num int, data []byte) {
+	p.offsets[num] = p.w.n
+	p.write(fmt.Sprintf("%d 0 obj\n<< /Length %d /Filter /FlateDecode >>\nstream\n", num, len(data)))
+	if p.err == nil {
+		_, p.err = p.w.Write(data)
+	}
+	p.write("\nendstream\nendobj\n")
+}
+
+func (p *PDFTableWriter) write(s string) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = io.WriteString(p.w, s)
+}
+
+// 估算文本宽度：ASCII 字符为半角，其余按全角计算
+func textWidth(s string, size float64) float64 {
+	w := 0.0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			w += size / 2
+		} else {
+			w += size
+		}
+	}
+	return w
+}
+
+// 超出列宽的文本以省略号截断
+func truncateText(s string, maxWidth, size float64) string {
+	if textWidth(s, size) <= maxWidth {
+		return s
+	}
+	limit := maxWidth - size
+	w := 0.0
+	for i, r := range s {
+		cw := size
+		if r < utf8.RuneSelf {
+			cw = size / 2
+		}
+		if w+cw > limit {
+			return s[:i] + "…"
+		}
+		w += cw
+	}
+	return s
+}
+
+// UniGB-UCS2-H 编码要求以 UCS-2 大端序输出，超出基本平面的字符以 "?" 代替
+func pdfUCS2Hex(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF {
+			r = '?'
+		}
+		fmt.Fprintf(&sb, "%04X", r)
+	}
+	return sb.String()
+}
+
+// 文档信息字典中的字符串使用带 BOM 的 UTF-16BE
+func pdfUTF16String(s string) string {
+	return "<FEFF" + pdfUCS2Hex(s) + ">"
+}
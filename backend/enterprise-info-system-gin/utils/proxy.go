@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// 受信任的反向代理，只有来自这些地址的请求才采用 X-Forwarded-* 请求头
+var trustedProxies []*net.IPNet
+
+// 从 TRUSTED_PROXIES 环境变量读取受信任的反向代理（以逗号分隔的 IP 或 CIDR），
+// 未设置时不信任任何代理。返回的列表可用于 gin.Engine.SetTrustedProxies
+func InitTrustedProxies() []string {
+	var proxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		cidr := p
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Fatal("TRUSTED_PROXIES 中有无效的地址: ", p)
+		}
+		trustedProxies = append(trustedProxies, network)
+		proxies = append(proxies, p)
+	}
+	return proxies
+}
+
+// 请求是否直接来自受信任的反向代理
+func fromTrustedProxy(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// 请求使用的协议，只有来自受信任代理的请求才以 X-Forwarded-Proto 为准
+func RequestScheme(r *http.Request) string {
+	if fromTrustedProxy(r) {
+		if proto := strings.ToLower(r.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
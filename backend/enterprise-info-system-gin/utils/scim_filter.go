@@ -0,0 +1,498 @@
+package utils
+
+import (
+	"enterprise-info-system-gin/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SCIM 接口返回的错误，Status 为 HTTP 状态码，ScimType 为 RFC 7644 定义的错误类型
+type SCIMError struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *SCIMError) Error() string {
+	return e.Detail
+}
+
+// 创建 SCIM 错误
+func NewSCIMError(status int, scimType, detail string) *SCIMError {
+	return &SCIMError{Status: status, ScimType: scimType, Detail: detail}
+}
+
+// 解析后的 SCIM 过滤条件（RFC 7644 3.4.2.2），对资源的 JSON 表示进行匹配
+type SCIMFilter interface {
+	Match(resource map[string]interface{}) bool
+}
+
+// 比较运算
+type scimCompare struct {
+	keys  []string
+	op    string
+	value interface{}
+}
+
+// and / or
+type scimLogical struct {
+	op          string
+	left, right SCIMFilter
+}
+
+type scimNot struct {
+	filter SCIMFilter
+}
+
+// 多值属性的筛选，例如 emails[type eq "work"]
+type scimValuePath struct {
+	keys   []string
+	filter SCIMFilter
+}
+
+// 解析 SCIM 过滤条件，例如 userName eq "zhangsan" and active eq true
+func ParseSCIMFilter(s string) (SCIMFilter, error) {
+	tokens, err := tokenizeSCIMFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &scimFilterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != scimTokEOF {
+		return nil, invalidSCIMFilter("过滤条件中有多余的内容: " + p.peek().text)
+	}
+	return f, nil
+}
+
+func invalidSCIMFilter(detail string) *SCIMError {
+	return NewSCIMError(http.StatusBadRequest, "invalidFilter", detail)
+}
+
+const (
+	scimTokEOF = iota
+	scimTokWord
+	scimTokString
+	scimTokLParen
+	scimTokRParen
+	scimTokLBracket
+	scimTokRBracket
+)
+
+type scimToken struct {
+	kind int
+	text string
+}
+
+func tokenizeSCIMFilter(s string) ([]scimToken, error) {
+	var tokens []scimToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, scimToken{scimTokLParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, scimToken{scimTokRParen, ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, scimToken{scimTokLBracket, "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, scimToken{scimTokRBracket, "]"})
+			i++
+		case c == '"':
+			// 字符串使用 JSON 的转义规则
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, invalidSCIMFilter("字符串缺少结束的引号")
+			}
+			text, err := strconv.Unquote(s[i : j+1])
+			if err != nil {
+				return nil, invalidSCIMFilter("无效的字符串: " + s[i:j+1])
+			}
+			tokens = append(tokens, scimToken{scimTokString, text})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\r\n()[]\"", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, scimToken{scimTokWord, s[i:j]})
+			i = j
+		}
+	}
+	return append(tokens, scimToken{kind: scimTokEOF}), nil
+}
+
+// 括号和多值属性筛选的最大嵌套层数，避免恶意的过滤条件耗尽栈空间
+const scimMaxFilterDepth = 32
+
+type scimFilterParser struct {
+	tokens []scimToken
+	pos    int
+	depth  int
+}
+
+func (p *scimFilterParser) peek() scimToken {
+	return p.tokens[p.pos]
+}
+
+func (p *scimFilterParser) next() scimToken {
+	t := p.tokens[p.pos]
+	if t.kind != scimTokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// 当前是否为指定的关键字（不区分大小写）
+func (p *scimFilterParser) isKeyword(word string) bool {
+	t := p.peek()
+	return t.kind == scimTokWord && strings.EqualFold(t.text, word)
+}
+
+func (p *scimFilterParser) expect(kind int, text string) error {
+	if p.next().kind != kind {
+		return invalidSCIMFilter("过滤条件缺少 \"" + text + "\"")
+	}
+	return nil
+}
+
+// 进入一层嵌套，超过最大层数时返回 invalidFilter
+func (p *scimFilterParser) enter() error {
+	p.depth++
+	if p.depth > scimMaxFilterDepth {
+		return invalidSCIMFilter("过滤条件嵌套层数不能超过 " + strconv.Itoa(scimMaxFilterDepth))
+	}
+	return nil
+}
+
+func (p *scimFilterParser) parseOr() (SCIMFilter, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &scimLogical{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *scimFilterParser) parseAnd() (SCIMFilter, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &scimLogical{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *scimFilterParser) parseFactor() (SCIMFilter, error) {
+	negate := false
+	if p.isKeyword("not") {
+		p.next()
+		negate = true
+		if p.peek().kind != scimTokLParen {
+			return nil, invalidSCIMFilter("not 之后必须是括号")
+		}
+	}
+
+	var f SCIMFilter
+	var err error
+	if p.peek().kind == scimTokLParen {
+		p.next()
+		if f, err = p.parseOr(); err != nil {
+			return nil, err
+		}
+		if err := p.expect(scimTokRParen, ")"); err != nil {
+			return nil, err
+		}
+	} else if f, err = p.parseAttrExp(); err != nil {
+		return nil, err
+	}
+
+	if negate {
+		return &scimNot{filter: f}, nil
+	}
+	return f, nil
+}
+
+func (p *scimFilterParser) parseAttrExp() (SCIMFilter, error) {
+	t := p.next()
+	if t.kind != scimTokWord {
+		return nil, invalidSCIMFilter("过滤条件缺少属性名")
+	}
+	keys := ParseSCIMAttrPath(t.text)
+
+	if p.peek().kind == scimTokLBracket {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(scimTokRBracket, "]"); err != nil {
+			return nil, err
+		}
+		return &scimValuePath{keys: keys, filter: inner}, nil
+	}
+
+	opTok := p.next()
+	op := strings.ToLower(opTok.text)
+	if opTok.kind != scimTokWord {
+		return nil, invalidSCIMFilter("属性 " + t.text + " 之后缺少比较运算符")
+	}
+	switch op {
+	case "pr":
+		return &scimCompare{keys: keys, op: op}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, invalidSCIMFilter("不支持的比较运算符: " + opTok.text)
+	}
+
+	valTok := p.next()
+	c := &scimCompare{keys: keys, op: op}
+	switch {
+	case valTok.kind == scimTokString:
+		c.value = valTok.text
+	case valTok.kind != scimTokWord:
+		return nil, invalidSCIMFilter("运算符 " + opTok.text + " 之后缺少比较值")
+	case strings.EqualFold(valTok.text, "true"):
+		c.value = true
+	case strings.EqualFold(valTok.text, "false"):
+		c.value = false
+	case strings.EqualFold(valTok.text, "null"):
+		c.value = nil
+	default:
+		n, err := strconv.ParseFloat(valTok.text, 64)
+		if err != nil {
+			return nil, invalidSCIMFilter("无效的比较值: " + valTok.text)
+		}
+		c.value = n
+	}
+	return c, nil
+}
+
+// 取出过滤条件中以 and 连接的字符串 eq 比较，键为小写的属性路径（例如 username），
+// 调用方可以据此先在数据库中缩小候选范围，再用 Match 精确过滤；
+// or、not 等无法取出的条件会被忽略，因此结果只会扩大而不会遗漏匹配的资源
+func SCIMFilterEqualities(f SCIMFilter) map[string]string {
+	result := make(map[string]string)
+	collectSCIMEqualities(f, result)
+	return result
+}
+
+func collectSCIMEqualities(f SCIMFilter, result map[string]string) {
+	switch f := f.(type) {
+	case *scimLogical:
+		if f.op == "and" {
+			collectSCIMEqualities(f.left, result)
+			collectSCIMEqualities(f.right, result)
+		}
+	case *scimCompare:
+		value, ok := f.value.(string)
+		key := strings.ToLower(strings.Join(f.keys, "."))
+		if _, exists := result[key]; ok && f.op == "eq" && !exists {
+			result[key] = value
+		}
+	}
+}
+
+// 已知的 schema，属性名可以带上这些前缀，例如
+// urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber
+var scimKnownSchemas = []string{models.SCIMSchemaUser, models.SCIMSchemaGroup, models.SCIMSchemaEnterpriseUser}
+
+// 将属性路径拆分为各级属性名：核心 schema 的前缀会被去掉，扩展 schema 作为第一级属性
+func ParseSCIMAttrPath(path string) []string {
+	prefix, name := "", path
+	for _, schema := range scimKnownSchemas {
+		if strings.EqualFold(path, schema) {
+			return []string{schema}
+		}
+		if len(path) > len(schema) && strings.EqualFold(path[:len(schema)+1], schema+":") {
+			prefix, name = schema, path[len(schema)+1:]
+			break
+		}
+	}
+	if prefix == models.SCIMSchemaUser || prefix == models.SCIMSchemaGroup {
+		prefix = ""
+	}
+
+	keys := strings.Split(name, ".")
+	if prefix != "" {
+		keys = append([]string{prefix}, keys...)
+	}
+	return keys
+}
+
+// 按属性名查找（属性名不区分大小写），返回实际的键
+func findSCIMKey(m map[string]interface{}, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// 取出路径上的所有值，经过多值属性时展开其中的每一项
+func scimValues(v interface{}, keys []string) []interface{} {
+	if arr, ok := v.([]interface{}); ok {
+		var values []interface{}
+		for _, item := range arr {
+			values = append(values, scimValues(item, keys)...)
+		}
+		return values
+	}
+	if len(keys) == 0 {
+		if v == nil {
+			return nil
+		}
+		return []interface{}{v}
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	key, ok := findSCIMKey(m, keys[0])
+	if !ok {
+		return nil
+	}
+	return scimValues(m[key], keys[1:])
+}
+
+func (f *scimLogical) Match(resource map[string]interface{}) bool {
+	if f.op == "and" {
+		return f.left.Match(resource) && f.right.Match(resource)
+	}
+	return f.left.Match(resource) || f.right.Match(resource)
+}
+
+func (f *scimNot) Match(resource map[string]interface{}) bool {
+	return !f.filter.Match(resource)
+}
+
+func (f *scimValuePath) Match(resource map[string]interface{}) bool {
+	for _, v := range scimValues(resource, f.keys) {
+		if item, ok := v.(map[string]interface{}); ok && f.filter.Match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *scimCompare) Match(resource map[string]interface{}) bool {
+	values := scimValues(resource, f.keys)
+	switch {
+	case f.op == "pr":
+		for _, v := range values {
+			if s, ok := v.(string); !ok || s != "" {
+				return true
+			}
+		}
+		return false
+	case f.op == "ne":
+		return !(&scimCompare{keys: f.keys, op: "eq", value: f.value}).Match(resource)
+	case f.value == nil:
+		return f.op == "eq" && len(values) == 0
+	}
+
+	// id 和 externalId 区分大小写，其余字符串不区分
+	last := f.keys[len(f.keys)-1]
+	caseExact := strings.EqualFold(last, "id") || strings.EqualFold(last, "externalId")
+	for _, v := range values {
+		// 复杂多值属性没有指定子属性时比较其 value
+		if item, ok := v.(map[string]interface{}); ok {
+			key, found := findSCIMKey(item, "value")
+			if !found {
+				continue
+			}
+			v = item[key]
+		}
+		if compareSCIMValue(v, f.op, f.value, caseExact) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareSCIMValue(actual interface{}, op string, expected interface{}, caseExact bool) bool {
+	switch want := expected.(type) {
+	case string:
+		got, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		if !caseExact {
+			got, want = strings.ToLower(got), strings.ToLower(want)
+		}
+		switch op {
+		case "eq":
+			return got == want
+		case "co":
+			return strings.Contains(got, want)
+		case "sw":
+			return strings.HasPrefix(got, want)
+		case "ew":
+			return strings.HasSuffix(got, want)
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case float64:
+		got, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return got == want
+		case "gt":
+			return got > want
+		case "ge":
+			return got >= want
+		case "lt":
+			return got < want
+		case "le":
+			return got <= want
+		}
+	case bool:
+		got, ok := actual.(bool)
+		return ok && op == "eq" && got == want
+	}
+	return false
+}
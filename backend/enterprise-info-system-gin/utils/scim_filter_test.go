@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"enterprise-info-system-gin/models"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// RFC 7643 8.2 中的示例用户（节选）
+func scimTestUser() map[string]interface{} {
+	return map[string]interface{}{
+		"schemas":  []interface{}{models.SCIMSchemaUser, models.SCIMSchemaEnterpriseUser},
+		"id":       "2819c223-7f76-453a-919d-413861904646",
+		"userName": "bjensen",
+		"name": map[string]interface{}{
+			"familyName": "O'Malley",
+			"givenName":  "Barbara",
+		},
+		"title":    "Tour Guide",
+		"userType": "Employee",
+		"active":   true,
+		"emails": []interface{}{
+			map[string]interface{}{"type": "work", "value": "bjensen@example.com", "primary": true},
+			map[string]interface{}{"type": "home", "value": "babs@jensen.org"},
+		},
+		"meta": map[string]interface{}{
+			"lastModified": "2011-05-13T04:42:34Z",
+		},
+		models.SCIMSchemaEnterpriseUser: map[string]interface{}{
+			"employeeNumber": "701984",
+		},
+	}
+}
+
+func TestParseSCIMFilterMatch(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   bool
+	}{
+		// RFC 7644 3.4.2.2 中的示例
+		{`userName eq "bjensen"`, true},
+		{`name.familyName co "O'Malley"`, true},
+		{`userName sw "J"`, false},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName sw "BJ"`, true},
+		{`title pr`, true},
+		{`meta.lastModified gt "2011-05-13T04:42:34Z"`, false},
+		{`meta.lastModified ge "2011-05-13T04:42:34Z"`, true},
+		{`meta.lastModified lt "2011-05-13T04:42:34Z"`, false},
+		{`meta.lastModified le "2011-05-13T04:42:34Z"`, true},
+		{`title pr and userType eq "Employee"`, true},
+		{`title pr or userType eq "Intern"`, true},
+		{`schemas eq "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`, true},
+		{`userType eq "Employee" and (emails co "example.com" or emails.value co "example.org")`, true},
+		{`userType ne "Employee" and not (emails co "example.com" or emails.value co "example.org")`, false},
+		{`userType eq "Employee" and (emails.type eq "work")`, true},
+		{`userType eq "Employee" and emails[type eq "work" and value co "@example.com"]`, true},
+		{`emails[type eq "work" and value co "@example.com"] or ims[type eq "xmpp" and value co "@foo.com"]`, true},
+
+		// 属性名、运算符和字符串比较不区分大小写，id 区分大小写
+		{`USERNAME EQ "BJENSEN"`, true},
+		{`id eq "2819C223-7F76-453A-919D-413861904646"`, false},
+		{`id eq "2819c223-7f76-453a-919d-413861904646"`, true},
+		{`userName ew "sen"`, true},
+
+		// 布尔值、null 和扩展 schema 的属性
+		{`active eq true`, true},
+		{`active eq false`, false},
+		{`nickName eq null`, true},
+		{`ims pr`, false},
+		{`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber eq "701984"`, true},
+
+		// 多值属性的筛选需要同一项同时满足条件
+		{`emails[type eq "home" and value co "@example.com"]`, false},
+		{`not (emails[type eq "home"])`, false},
+		{`emails[not (type eq "work")]`, true},
+	}
+
+	user := scimTestUser()
+	for _, tt := range tests {
+		f, err := ParseSCIMFilter(tt.filter)
+		if err != nil {
+			t.Errorf("ParseSCIMFilter(%q) error: %v", tt.filter, err)
+			continue
+		}
+		if got := f.Match(user); got != tt.want {
+			t.Errorf("ParseSCIMFilter(%q).Match = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestParseSCIMFilterInvalid(t *testing.T) {
+	tests := []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName foo "bjensen"`,
+		`userName eq bjensen`,
+		`userName eq "bjensen`,
+		`userName eq "bad \q escape"`,
+		`(userName eq "bjensen"`,
+		`userName eq "bjensen")`,
+		`userName eq "bjensen" and`,
+		`userName eq "bjensen" userType eq "Employee"`,
+		`not userName eq "bjensen"`,
+		`emails[type eq "work"`,
+		`emails[type eq "work"]]`,
+		`emails[]`,
+		`eq "bjensen"`,
+		`()`,
+	}
+
+	for _, filter := range tests {
+		_, err := ParseSCIMFilter(filter)
+		var scimErr *SCIMError
+		if !errors.As(err, &scimErr) {
+			t.Errorf("ParseSCIMFilter(%q) error = %v, want *SCIMError", filter, err)
+			continue
+		}
+		if scimErr.Status != http.StatusBadRequest || scimErr.ScimType != "invalidFilter" {
+			t.Errorf("ParseSCIMFilter(%q) error = %d %s, want 400 invalidFilter", filter, scimErr.Status, scimErr.ScimType)
+		}
+	}
+}
+
+func TestParseSCIMFilterDepth(t *testing.T) {
+	nested := func(open, close string, n int) string {
+		return strings.Repeat(open, n) + `userName eq "bjensen"` + strings.Repeat(close, n)
+	}
+
+	tests := []struct {
+		name    string
+		filter  string
+		wantErr bool
+	}{
+		{"括号未超过上限", nested("(", ")", scimMaxFilterDepth-1), false},
+		{"括号超过上限", nested("(", ")", scimMaxFilterDepth), true},
+		{"not 超过上限", nested("not (", ")", scimMaxFilterDepth), true},
+		{"多值属性筛选超过上限", strings.Repeat("emails[", scimMaxFilterDepth) + `type pr` + strings.Repeat("]", scimMaxFilterDepth), true},
+		{"大量括号", nested("(", ")", 100000), true},
+		{"大量未闭合的括号", strings.Repeat("(", 100000), true},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseSCIMFilter(tt.filter)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSCIMFilterEqualities(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   map[string]string
+	}{
+		{`userName eq "bjensen"`, map[string]string{"username": "bjensen"}},
+		{`externalId eq "e1" and userName eq "bjensen"`, map[string]string{"externalid": "e1", "username": "bjensen"}},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`, map[string]string{"username": "bjensen"}},
+		{`userName eq "a" and userName eq "b"`, map[string]string{"username": "a"}},
+		{`userName eq "bjensen" and (title eq "x" or title eq "y")`, map[string]string{"username": "bjensen"}},
+		{`userName eq "a" or userName eq "b"`, map[string]string{}},
+		{`not (userName eq "bjensen")`, map[string]string{}},
+		{`userName ne "bjensen"`, map[string]string{}},
+		{`active eq true`, map[string]string{}},
+		{`emails[value eq "bjensen@example.com"]`, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseSCIMFilter(tt.filter)
+		if err != nil {
+			t.Fatalf("ParseSCIMFilter(%q) error: %v", tt.filter, err)
+		}
+		if got := SCIMFilterEqualities(f); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SCIMFilterEqualities(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestParseSCIMAttrPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"userName", []string{"userName"}},
+		{"name.givenName", []string{"name", "givenName"}},
+		{"urn:ietf:params:scim:schemas:core:2.0:User:name.givenName", []string{"name", "givenName"}},
+		{"URN:IETF:PARAMS:SCIM:SCHEMAS:CORE:2.0:USER:userName", []string{"userName"}},
+		{"urn:ietf:params:scim:schemas:core:2.0:Group:displayName", []string{"displayName"}},
+		{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber",
+			[]string{models.SCIMSchemaEnterpriseUser, "employeeNumber"}},
+		{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value",
+			[]string{models.SCIMSchemaEnterpriseUser, "manager", "value"}},
+		{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User", []string{models.SCIMSchemaEnterpriseUser}},
+		{"name..givenName", []string{"name", "", "givenName"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseSCIMAttrPath(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseSCIMAttrPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
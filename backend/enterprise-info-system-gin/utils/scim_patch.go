@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"enterprise-info-system-gin/models"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PATCH 操作的目标路径，例如 emails[type eq "work"].value
+type scimPatchPath struct {
+	keys   []string
+	filter SCIMFilter
+	sub    string
+}
+
+// 只读属性，PATCH 时忽略
+var scimReadOnlyAttributes = []string{"id", "meta", "schemas"}
+
+func invalidSCIMPath(path string) *SCIMError {
+	return NewSCIMError(http.StatusBadRequest, "invalidPath", "无效的属性路径: "+path)
+}
+
+func parseSCIMPatchPath(path string) (*scimPatchPath, error) {
+	p := &scimPatchPath{}
+	head := path
+	if i := strings.Index(path, "["); i >= 0 {
+		j := strings.LastIndex(path, "]")
+		if j < i {
+			return nil, invalidSCIMPath(path)
+		}
+		filter, err := ParseSCIMFilter(path[i+1 : j])
+		if err != nil {
+			return nil, err
+		}
+		p.filter = filter
+		head = path[:i]
+		if rest := path[j+1:]; rest != "" {
+			if !strings.HasPrefix(rest, ".") || len(rest) == 1 || strings.Contains(rest[1:], ".") {
+				return nil, invalidSCIMPath(path)
+			}
+			p.sub = rest[1:]
+		}
+	}
+
+	p.keys = ParseSCIMAttrPath(head)
+	for _, k := range p.keys {
+		if k == "" {
+			return nil, invalidSCIMPath(path)
+		}
+	}
+	return p, nil
+}
+
+// 按 RFC 7644 3.5.2 对资源的 JSON 表示执行 PATCH 操作
+func ApplySCIMPatch(resource map[string]interface{}, ops []models.SCIMPatchOperation) error {
+	if len(ops) == 0 {
+		return NewSCIMError(http.StatusBadRequest, "invalidValue", "缺少 PATCH 操作")
+	}
+	for _, op := range ops {
+		name := strings.ToLower(op.Op)
+		if name != "add" && name != "replace" && name != "remove" {
+			return NewSCIMError(http.StatusBadRequest, "invalidSyntax", "不支持的 PATCH 操作: "+op.Op)
+		}
+
+		if op.Path != "" {
+			if err := applySCIMPatchOp(resource, name, op.Path, op.Value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// 没有 path 时 value 中的每个属性分别处理，属性名可以是带 schema 前缀或子属性的路径
+		if name == "remove" {
+			return NewSCIMError(http.StatusBadRequest, "noTarget", "remove 操作必须指定 path")
+		}
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return NewSCIMError(http.StatusBadRequest, "invalidValue", "没有 path 时 value 必须是对象")
+		}
+		for key, value := range values {
+			if isSCIMReadOnly(key) {
+				continue
+			}
+			if err := applySCIMPatchOp(resource, name, key, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isSCIMReadOnly(attr string) bool {
+	for _, a := range scimReadOnlyAttributes {
+		if strings.EqualFold(attr, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func applySCIMPatchOp(resource map[string]interface{}, op, path string, value interface{}) error {
+	p, err := parseSCIMPatchPath(path)
+	if err != nil {
+		return err
+	}
+	if len(p.keys) == 1 && isSCIMReadOnly(p.keys[0]) {
+		return NewSCIMError(http.StatusBadRequest, "mutability", "属性 "+p.keys[0]+" 不能修改")
+	}
+
+	parent := resource
+	for _, k := range p.keys[:len(p.keys)-1] {
+		key, ok := findSCIMKey(parent, k)
+		child, isMap := parent[key].(map[string]interface{})
+		if !ok || !isMap {
+			if op == "remove" {
+				return nil
+			}
+			if ok && parent[key] != nil {
+				return invalidSCIMPath(path)
+			}
+			child = map[string]interface{}{}
+			key = k
+			parent[key] = child
+		}
+		parent = child
+	}
+
+	last := p.keys[len(p.keys)-1]
+	key, ok := findSCIMKey(parent, last)
+	if !ok {
+		key = last
+	}
+	if p.filter == nil {
+		applySCIMAttributeOp(parent, key, op, value)
+		return nil
+	}
+
+	arr, _ := parent[key].([]interface{})
+	matched := false
+	kept := make([]interface{}, 0, len(arr))
+	for _, item := range arr {
+		m, isMap := item.(map[string]interface{})
+		if !isMap || !p.filter.Match(m) {
+			kept = append(kept, item)
+			continue
+		}
+		matched = true
+		switch {
+		case p.sub != "":
+			applySCIMAttributeOp(m, p.sub, op, value)
+		case op == "remove":
+			continue
+		case op == "replace":
+			item = value
+		default:
+			mergeSCIMObject(m, value)
+		}
+		kept = append(kept, item)
+	}
+
+	if !matched {
+		switch {
+		case op == "remove":
+			return nil
+		case op == "add" && p.sub != "":
+			// 例如 addresses[type eq "work"].formatted，没有匹配项时按过滤条件新增一项
+			attr, v, simple := simpleSCIMEqFilter(p.filter)
+			if !simple {
+				return NewSCIMError(http.StatusBadRequest, "noTarget", "没有与路径匹配的值: "+path)
+			}
+			kept = append(kept, map[string]interface{}{attr: v, p.sub: value})
+		default:
+			return NewSCIMError(http.StatusBadRequest, "noTarget", "没有与路径匹配的值: "+path)
+		}
+	}
+
+	if len(kept) == 0 {
+		delete(parent, key)
+	} else {
+		parent[key] = kept
+	}
+	return nil
+}
+
+// 对单个属性执行操作：add 向多值属性追加，复杂属性按子属性合并，其余情况直接替换
+func applySCIMAttributeOp(parent map[string]interface{}, name, op string, value interface{}) {
+	key, ok := findSCIMKey(parent, name)
+	if !ok {
+		key = name
+	}
+	existing := parent[key]
+
+	switch op {
+	case "remove":
+		// 带 value 时只删除多值属性中的这些值，例如删除组成员
+		if arr, isArr := existing.([]interface{}); isArr && value != nil {
+			parent[key] = removeSCIMValues(arr, value)
+			if len(parent[key].([]interface{})) == 0 {
+				delete(parent, key)
+			}
+			return
+		}
+		delete(parent, key)
+		return
+	case "add":
+		if arr, isArr := existing.([]interface{}); isArr {
+			parent[key] = appendSCIMValues(arr, value)
+			return
+		}
+	}
+
+	if m, isMap := existing.(map[string]interface{}); isMap {
+		if _, valueIsMap := value.(map[string]interface{}); valueIsMap {
+			mergeSCIMObject(m, value)
+			return
+		}
+	}
+	parent[key] = value
+}
+
+func mergeSCIMObject(target map[string]interface{}, value interface{}) {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	for k, v := range values {
+		key, found := findSCIMKey(target, k)
+		if !found {
+			key = k
+		}
+		target[key] = v
+	}
+}
+
+// 多值属性中每项的 value，用于去重和按值删除
+func scimItemValue(item interface{}) (string, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return fmt.Sprint(item), true
+	}
+	key, ok := findSCIMKey(m, "value")
+	if !ok || m[key] == nil {
+		return "", false
+	}
+	return fmt.Sprint(m[key]), true
+}
+
+// 向多值属性追加值，已存在相同 value 的项不会重复添加
+func appendSCIMValues(arr []interface{}, value interface{}) []interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+	for _, item := range items {
+		duplicate := false
+		if v, ok := scimItemValue(item); ok {
+			for _, existing := range arr {
+				if ev, ok := scimItemValue(existing); ok && ev == v {
+					duplicate = true
+					break
+				}
+			}
+		}
+		if !duplicate {
+			arr = append(arr, item)
+		}
+	}
+	return arr
+}
+
+func removeSCIMValues(arr []interface{}, value interface{}) []interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+	remove := make(map[string]bool, len(items))
+	for _, item := range items {
+		if v, ok := scimItemValue(item); ok {
+			remove[v] = true
+		}
+	}
+	kept := make([]interface{}, 0, len(arr))
+	for _, item := range arr {
+		if v, ok := scimItemValue(item); ok && remove[v] {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+// 过滤条件是否为单个属性的相等比较，例如 type eq "work"
+func simpleSCIMEqFilter(f SCIMFilter) (string, interface{}, bool) {
+	c, ok := f.(*scimCompare)
+	if !ok || c.op != "eq" || len(c.keys) != 1 || c.value == nil {
+		return "", nil, false
+	}
+	return c.keys[0], c.value, true
+}
+
+// 按 attributes 和 excludedAttributes 参数筛选返回的顶层属性，schemas、id 和 meta 总是返回
+func ProjectSCIMResource(resource map[string]interface{}, attributes, excluded []string) map[string]interface{} {
+	if len(attributes) == 0 && len(excluded) == 0 {
+		return resource
+	}
+	wanted := func(list []string, key string) bool {
+		for _, a := range list {
+			if keys := ParseSCIMAttrPath(strings.TrimSpace(a)); strings.EqualFold(keys[0], key) {
+				return true
+			}
+		}
+		return false
+	}
+
+	projected := make(map[string]interface{}, len(resource))
+	for k, v := range resource {
+		switch {
+		case isSCIMReadOnly(k):
+		case len(attributes) > 0 && !wanted(attributes, k):
+			continue
+		case wanted(excluded, k):
+			continue
+		}
+		projected[k] = v
+	}
+	return projected
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"encoding/json"
+	"enterprise-info-system-gin/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func scimTestJSON(t *testing.T, s string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		t.Fatalf("invalid test JSON %s: %v", s, err)
+	}
+}
+
+func TestApplySCIMPatch(t *testing.T) {
+	const group = `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+		"id": "g1",
+		"displayName": "Tour Guides",
+		"members": [
+			{"value": "2819c223", "display": "Babs Jensen"},
+			{"value": "902c246b", "display": "Mandy Pepperidge"}
+		]
+	}`
+	const user = `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"id": "u1",
+		"userName": "bjensen",
+		"name": {"givenName": "Barbara", "familyName": "Jensen"},
+		"emails": [{"type": "work", "value": "bjensen@example.com", "primary": true}],
+		"addresses": [{"type": "work", "streetAddress": "100 Universal City Plaza", "locality": "Hollywood"}]
+	}`
+
+	tests := []struct {
+		name     string
+		resource string
+		ops      string
+		want     string
+	}{
+		// RFC 7644 3.5.2.1 至 3.5.2.3 中的示例
+		{
+			"add 向组追加成员",
+			group,
+			`[{"op": "add", "path": "members", "value": [{"display": "James Smith", "value": "08e1d05d"}]}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "g1", "displayName": "Tour Guides",
+				"members": [{"value": "2819c223", "display": "Babs Jensen"}, {"value": "902c246b", "display": "Mandy Pepperidge"},
+					{"value": "08e1d05d", "display": "James Smith"}]}`,
+		},
+		{
+			"add 已有的成员不重复添加",
+			group,
+			`[{"op": "add", "path": "members", "value": [{"value": "2819c223"}]}]`,
+			group,
+		},
+		{
+			"add 没有 path 时按属性合并",
+			user,
+			`[{"op": "add", "value": {"emails": [{"value": "babs@jensen.org", "type": "home"}], "nickName": "Babs"}}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "u1", "userName": "bjensen",
+				"name": {"givenName": "Barbara", "familyName": "Jensen"}, "nickName": "Babs",
+				"emails": [{"type": "work", "value": "bjensen@example.com", "primary": true}, {"value": "babs@jensen.org", "type": "home"}],
+				"addresses": [{"type": "work", "streetAddress": "100 Universal City Plaza", "locality": "Hollywood"}]}`,
+		},
+		{
+			"remove 按过滤条件删除成员",
+			group,
+			`[{"op": "remove", "path": "members[value eq \"2819c223\"]"}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "g1", "displayName": "Tour Guides",
+				"members": [{"value": "902c246b", "display": "Mandy Pepperidge"}]}`,
+		},
+		{
+			"remove 删除所有成员",
+			group,
+			`[{"op": "remove", "path": "members"}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "g1", "displayName": "Tour Guides"}`,
+		},
+		{
+			"remove 带 value 时只删除这些成员",
+			group,
+			`[{"op": "remove", "path": "members", "value": [{"value": "902c246b"}]}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "g1", "displayName": "Tour Guides",
+				"members": [{"value": "2819c223", "display": "Babs Jensen"}]}`,
+		},
+		{
+			"remove 删除并替换成员",
+			group,
+			`[{"op": "remove", "path": "members[value eq \"2819c223\"]"},
+				{"op": "add", "path": "members", "value": [{"value": "08e1d05d", "display": "James Smith"}]}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "g1", "displayName": "Tour Guides",
+				"members": [{"value": "902c246b", "display": "Mandy Pepperidge"}, {"value": "08e1d05d", "display": "James Smith"}]}`,
+		},
+		{
+			"remove 没有匹配的值时不做修改",
+			group,
+			`[{"op": "remove", "path": "members[value eq \"missing\"]"}]`,
+			group,
+		},
+		{
+			"replace 替换所有成员",
+			group,
+			`[{"op": "replace", "path": "members", "value": [{"value": "08e1d05d", "display": "James Smith"}]}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "g1", "displayName": "Tour Guides",
+				"members": [{"value": "08e1d05d", "display": "James Smith"}]}`,
+		},
+		{
+			"replace 替换匹配的多值属性项",
+			user,
+			`[{"op": "replace", "path": "addresses[type eq \"work\"]",
+				"value": {"type": "work", "streetAddress": "911 Universal City Plaza", "locality": "Hollywood"}}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "u1", "userName": "bjensen",
+				"name": {"givenName": "Barbara", "familyName": "Jensen"},
+				"emails": [{"type": "work", "value": "bjensen@example.com", "primary": true}],
+				"addresses": [{"type": "work", "streetAddress": "911 Universal City Plaza", "locality": "Hollywood"}]}`,
+		},
+		{
+			"replace 替换匹配项的子属性",
+			user,
+			`[{"op": "replace", "path": "addresses[type eq \"work\"].streetAddress", "value": "1010 Broadway Ave"}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "u1", "userName": "bjensen",
+				"name": {"givenName": "Barbara", "familyName": "Jensen"},
+				"emails": [{"type": "work", "value": "bjensen@example.com", "primary": true}],
+				"addresses": [{"type": "work", "streetAddress": "1010 Broadway Ave", "locality": "Hollywood"}]}`,
+		},
+		{
+			"replace 没有 path 时按属性替换",
+			user,
+			`[{"op": "replace", "value": {"emails": [{"value": "bjensen@example.org", "type": "work"}], "name": {"givenName": "Babs"}, "id": "ignored"}}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "u1", "userName": "bjensen",
+				"name": {"givenName": "Babs", "familyName": "Jensen"},
+				"emails": [{"value": "bjensen@example.org", "type": "work"}],
+				"addresses": [{"type": "work", "streetAddress": "100 Universal City Plaza", "locality": "Hollywood"}]}`,
+		},
+
+		// 带 schema 前缀的路径、大小写不同的属性名以及没有匹配项时的处理
+		{
+			"replace 扩展 schema 的属性",
+			user,
+			`[{"op": "replace", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber", "value": "701984"}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "u1", "userName": "bjensen",
+				"name": {"givenName": "Barbara", "familyName": "Jensen"},
+				"emails": [{"type": "work", "value": "bjensen@example.com", "primary": true}],
+				"addresses": [{"type": "work", "streetAddress": "100 Universal City Plaza", "locality": "Hollywood"}],
+				"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"employeeNumber": "701984"}}`,
+		},
+		{
+			"replace 核心 schema 前缀和大小写不同的属性名",
+			user,
+			`[{"op": "Replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:NAME.GIVENNAME", "value": "Babs"}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "u1", "userName": "bjensen",
+				"name": {"givenName": "Babs", "familyName": "Jensen"},
+				"emails": [{"type": "work", "value": "bjensen@example.com", "primary": true}],
+				"addresses": [{"type": "work", "streetAddress": "100 Universal City Plaza", "locality": "Hollywood"}]}`,
+		},
+		{
+			"add 没有匹配项时按过滤条件新增一项",
+			user,
+			`[{"op": "add", "path": "emails[type eq \"home\"].value", "value": "babs@jensen.org"}]`,
+			`{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "id": "u1", "userName": "bjensen",
+				"name": {"givenName": "Barbara", "familyName": "Jensen"},
+				"emails": [{"type": "work", "value": "bjensen@example.com", "primary": true}, {"type": "home", "value": "babs@jensen.org"}],
+				"addresses": [{"type": "work", "streetAddress": "100 Universal City Plaza", "locality": "Hollywood"}]}`,
+		},
+		{
+			"remove 不存在的上级属性时不做修改",
+			user,
+			`[{"op": "remove", "path": "phoneNumbers.value"}]`,
+			user,
+		},
+	}
+
+	for _, tt := range tests {
+		var resource, want map[string]interface{}
+		var ops []models.SCIMPatchOperation
+		scimTestJSON(t, tt.resource, &resource)
+		scimTestJSON(t, tt.ops, &ops)
+		scimTestJSON(t, tt.want, &want)
+
+		if err := ApplySCIMPatch(resource, ops); err != nil {
+			t.Errorf("%s: ApplySCIMPatch error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(resource, want) {
+			got, _ := json.Marshal(resource)
+			t.Errorf("%s: ApplySCIMPatch = %s", tt.name, got)
+		}
+	}
+}
+
+func TestApplySCIMPatchErrors(t *testing.T) {
+	const user = `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"id": "u1",
+		"userName": "bjensen",
+		"emails": [{"type": "work", "value": "bjensen@example.com"}]
+	}`
+
+	tests := []struct {
+		name     string
+		ops      string
+		scimType string
+	}{
+		{"没有操作", `[]`, "invalidValue"},
+		{"不支持的操作", `[{"op": "copy", "path": "userName", "value": "x"}]`, "invalidSyntax"},
+		{"remove 没有 path", `[{"op": "remove"}]`, "noTarget"},
+		{"没有 path 时 value 不是对象", `[{"op": "replace", "value": "bjensen"}]`, "invalidValue"},
+		{"修改只读属性", `[{"op": "replace", "path": "id", "value": "u2"}]`, "mutability"},
+		{"修改只读属性（大小写不同）", `[{"op": "replace", "path": "META", "value": {}}]`, "mutability"},
+		{"缺少右方括号", `[{"op": "replace", "path": "emails[type eq", "value": "x"}]`, "invalidPath"},
+		{"无效的过滤条件", `[{"op": "replace", "path": "emails[type foo \"work\"]", "value": "x"}]`, "invalidFilter"},
+		{"过滤条件嵌套过深", `[{"op": "remove", "path": "emails[((((((((((((((((((((((((((((((((type pr))))))))))))))))))))))))))))))))]"}]`, "invalidFilter"},
+		{"方括号顺序错误", `[{"op": "replace", "path": "emails]type eq \"work\"[", "value": "x"}]`, "invalidPath"},
+		{"过滤条件之后不是子属性", `[{"op": "replace", "path": "emails[type eq \"work\"]value", "value": "x"}]`, "invalidPath"},
+		{"过滤条件之后有多级子属性", `[{"op": "replace", "path": "emails[type eq \"work\"].a.b", "value": "x"}]`, "invalidPath"},
+		{"过滤条件之后只有点号", `[{"op": "replace", "path": "emails[type eq \"work\"].", "value": "x"}]`, "invalidPath"},
+		{"属性名为空", `[{"op": "replace", "path": "name..givenName", "value": "x"}]`, "invalidPath"},
+		{"上级属性不是对象", `[{"op": "add", "path": "userName.first", "value": "x"}]`, "invalidPath"},
+		{"replace 没有匹配的值", `[{"op": "replace", "path": "emails[type eq \"home\"]", "value": {"value": "x"}}]`, "noTarget"},
+		{"add 的过滤条件无法用于新增", `[{"op": "add", "path": "emails[type eq \"home\" or primary eq true].value", "value": "x"}]`, "noTarget"},
+	}
+
+	for _, tt := range tests {
+		var resource map[string]interface{}
+		var ops []models.SCIMPatchOperation
+		scimTestJSON(t, user, &resource)
+		scimTestJSON(t, tt.ops, &ops)
+
+		err := ApplySCIMPatch(resource, ops)
+		var scimErr *SCIMError
+		if !errors.As(err, &scimErr) {
+			t.Errorf("%s: error = %v, want *SCIMError", tt.name, err)
+			continue
+		}
+		if scimErr.ScimType != tt.scimType {
+			t.Errorf("%s: scimType = %q, want %q", tt.name, scimErr.ScimType, tt.scimType)
+		}
+	}
+}
+
+func TestProjectSCIMResource(t *testing.T) {
+	resource := map[string]interface{}{
+		"schemas":  []interface{}{models.SCIMSchemaUser},
+		"id":       "u1",
+		"meta":     map[string]interface{}{"resourceType": "User"},
+		"userName": "bjensen",
+		"name":     map[string]interface{}{"givenName": "Barbara"},
+		"emails":   []interface{}{},
+	}
+
+	tests := []struct {
+		name       string
+		attributes []string
+		excluded   []string
+		want       []string
+	}{
+		{"不筛选", nil, nil, []string{"schemas", "id", "meta", "userName", "name", "emails"}},
+		{"只返回指定属性", []string{"userName", "NAME.givenName"}, nil, []string{"schemas", "id", "meta", "userName", "name"}},
+		{"带 schema 前缀的属性", []string{"urn:ietf:params:scim:schemas:core:2.0:User:emails"}, nil, []string{"schemas", "id", "meta", "emails"}},
+		{"排除指定属性", nil, []string{"emails", " name "}, []string{"schemas", "id", "meta", "userName"}},
+		{"不能排除 id", nil, []string{"id", "meta"}, []string{"schemas", "id", "meta", "userName", "name", "emails"}},
+	}
+
+	for _, tt := range tests {
+		got := ProjectSCIMResource(resource, tt.attributes, tt.excluded)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d attributes, want %v", tt.name, len(got), tt.want)
+			continue
+		}
+		for _, key := range tt.want {
+			if _, ok := got[key]; !ok {
+				t.Errorf("%s: missing attribute %s", tt.name, key)
+			}
+		}
+	}
+}
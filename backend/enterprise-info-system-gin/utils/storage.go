@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// 对象存储接口，按 S3 的对象语义设计（以键名读写整个对象），
+// 可以替换为 S3 兼容的实现，默认使用本地文件系统
+type ObjectStorage interface {
+	PutObject(key string, r io.Reader, contentType string) error
+	GetObject(key string) (io.ReadCloser, error)
+	DeleteObject(key string) error
+}
+
+// 对象不存在
+var ErrObjectNotFound = errors.New("文件不存在")
+
+// 以本地目录模拟对象存储，键名中的 / 对应子目录
+type LocalObjectStorage struct {
+	Root string
+}
+
+func NewLocalObjectStorage(root string) *LocalObjectStorage {
+	return &LocalObjectStorage{Root: root}
+}
+
+// 将键名转换为本地路径，拒绝跳出根目录的键名
+func (s *LocalObjectStorage) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if key == "" || strings.Contains(key, "..") || clean == "/" {
+		return "", errors.New("无效的文件键名")
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(clean)), nil
+}
+
+// 写入对象，先写入临时文件再重命名，避免读到写了一半的文件
+func (s *LocalObjectStorage) PutObject(key string, r io.Reader, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+func (s *LocalObjectStorage) GetObject(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+func (s *LocalObjectStorage) DeleteObject(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
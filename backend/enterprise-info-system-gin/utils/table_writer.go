@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+)
+
+// 支持的导出格式
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+	ExportPDF  = "pdf"
+)
+
+// 导出表格的列定义，Width 为 PDF 排版时的相对列宽
+type TableColumn struct {
+	Header string
+	Width  float64
+}
+
+// 逐行写出表格数据的导出器
+type TableWriter interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// 判断导出格式是否受支持
+func IsValidExportFormat(format string) bool {
+	return format == ExportCSV || format == ExportXLSX || format == ExportPDF
+}
+
+// 导出格式对应的 Content-Type
+func ExportContentType(format string) string {
+	switch format {
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case ExportPDF:
+		return "application/pdf"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// 按格式创建导出器并写入表头
+func NewTableWriter(format string, w io.Writer, title string, columns []TableColumn) (TableWriter, error) {
+	headers := make([]string, len(columns))
+	widths := make([]float64, len(columns))
+	for i, col := range columns {
+		headers[i] = col.Header
+		widths[i] = col.Width
+	}
+
+	switch format {
+	case ExportCSV:
+		// 写入 UTF-8 BOM，保证 Excel 直接打开时中文不乱码
+		if _, err := io.WriteString(w, "\xef\xbb\xbf"); err != nil {
+			return nil, err
+		}
+		cw := &csvTableWriter{w: csv.NewWriter(w)}
+		if err := cw.WriteRow(headers); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	case ExportXLSX:
+		xw, err := NewXLSXWriter(w, title)
+		if err != nil {
+			return nil, err
+		}
+		if err := xw.WriteHeader(headers); err != nil {
+			return nil, err
+		}
+		return xw, nil
+	case ExportPDF:
+		return NewPDFTableWriter(w, title, headers, widths), nil
+	}
+	return nil, errors.New("不支持的导出格式")
+}
+
+type csvTableWriter struct {
+	w    *csv.Writer
+	rows int
+}
+
+func (c *csvTableWriter) WriteRow(values []string) error {
+	if err := c.w.Write(values); err != nil {
+		return err
+	}
+	// 定期刷新，让数据尽快发送给客户端
+	c.rows++
+	if c.rows%500 == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvTableWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+)
+
+// 生成缩略图时允许的最大像素数，避免声明了超大尺寸的图片在解码时占用大量内存
+const maxThumbnailSourcePixels = 40 * 1000 * 1000
+
+// 图片像素数超过限制
+var ErrImageTooLarge = errors.New("图片尺寸过大")
+
+// 生成缩略图：按比例缩小到长边不超过 maxSize 像素，输出 JPEG。
+// 使用区域平均采样，图片本身小于 maxSize 时只重新编码
+func MakeThumbnail(r io.Reader, maxSize int) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先读取图片头中的尺寸，确认不超过限制后再解码
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width <= 0 || config.Height <= 0 ||
+		int64(config.Width)*int64(config.Height) > maxThumbnailSourcePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	tw, th := w, h
+	if w > maxSize || h > maxSize {
+		if w >= h {
+			tw, th = maxSize, h*maxSize/w
+		} else {
+			tw, th = w*maxSize/h, maxSize
+		}
+		if tw < 1 {
+			tw = 1
+		}
+		if th < 1 {
+			th = 1
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0 := bounds.Min.Y + y*h/th
+		y1 := bounds.Min.Y + (y+1)*h/th
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < tw; x++ {
+			x0 := bounds.Min.X + x*w/tw
+			x1 := bounds.Min.X + (x+1)*w/tw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			// 透明部分按白色背景合成
+			alpha := a / n
+			white := uint64(0xffff) - alpha
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8((r/n + white) >> 8)
+			dst.Pix[i+1] = uint8((g/n + white) >> 8)
+			dst.Pix[i+2] = uint8((b/n + white) >> 8)
+			dst.Pix[i+3] = 0xff
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
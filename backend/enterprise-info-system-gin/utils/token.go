@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// 访问令牌有效期
+const TokenTTL = 12 * time.Hour
+
+// 令牌中携带的用户信息
+type TokenClaims struct {
+	UserID    int    `json:"uid"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// 令牌签名密钥，在服务启动时初始化
+var tokenSecret []byte
+
+var errTokenSecretNotReady = errors.New("令牌密钥未初始化")
+
+// 签名密钥从 AUTH_TOKEN_SECRET 环境变量读取。未设置时拒绝启动，
+// 否则服务重启后登录令牌和日历订阅链接都会失效
+func InitTokenSecret() {
+	secret := os.Getenv("AUTH_TOKEN_SECRET")
+	if secret == "" {
+		log.Fatal("未设置 AUTH_TOKEN_SECRET 环境变量，无法签发访问令牌")
+	}
+	tokenSecret = []byte(secret)
+}
+
+// 签发访问令牌，格式为 base64(载荷).base64(HMAC-SHA256 签名)
+func IssueToken(userID int, role string) (string, error) {
+	if tokenSecret == nil {
+		return "", errTokenSecretNotReady
+	}
+	payload, err := json.Marshal(TokenClaims{
+		UserID:    userID,
+		Role:      role,
+		ExpiresAt: time.Now().Add(TokenTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signToken(encoded), nil
+}
+
+// 校验访问令牌的签名和有效期
+func ParseToken(token string) (*TokenClaims, error) {
+	if tokenSecret == nil {
+		return nil, errTokenSecretNotReady
+	}
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signToken(encoded))) {
+		return nil, errors.New("无效的令牌")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("无效的令牌")
+	}
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, errors.New("无效的令牌")
+	}
+	if time.Now().Unix() > claims.ExpiresAt {
+		return nil, errors.New("令牌已过期")
+	}
+	return &claims, nil
+}
+
+func signToken(encoded string) string {
+	mac := hmac.New(sha256.New, tokenSecret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// 为固定的值生成签名，用于无法携带访问令牌的场景，例如日历订阅链接
+func SignValue(value string) string {
+	return signToken("value:" + value)
+}
+
+// 校验 SignValue 生成的签名
+func VerifySignedValue(value, signature string) bool {
+	return tokenSecret != nil && hmac.Equal([]byte(signature), []byte(SignValue(value)))
+}
@@ -0,0 +1,298 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime/quotedprintable"
+	"sort"
+	"strings"
+)
+
+// vCard 中的一个属性，例如 TEL;TYPE=CELL:13800138000
+type VCardProperty struct {
+	Group  string
+	Name   string
+	Params map[string][]string
+	Value  string
+}
+
+// 判断属性是否带有指定的 TYPE 参数（不区分大小写）
+func (p VCardProperty) HasType(t string) bool {
+	for _, v := range p.Params["TYPE"] {
+		for _, item := range strings.Split(v, ",") {
+			if strings.EqualFold(item, t) {
+				return true
+			}
+		}
+	}
+	// vCard 2.1 允许省略 TYPE=，直接写 TEL;CELL:...
+	_, ok := p.Params[strings.ToUpper(t)]
+	return ok
+}
+
+// 以 ";" 分隔的结构化值，例如 N、ADR、ORG
+func (p VCardProperty) Components() []string {
+	parts := splitVCardValue(p.Value, ';')
+	for i := range parts {
+		parts[i] = unescapeVCardText(parts[i])
+	}
+	return parts
+}
+
+// 单个联系人
+type VCard struct {
+	Properties []VCardProperty
+}
+
+// 返回第一个指定名称的属性
+func (c VCard) Get(name string) (VCardProperty, bool) {
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return VCardProperty{}, false
+}
+
+// 返回所有指定名称的属性
+func (c VCard) GetAll(name string) []VCardProperty {
+	var props []VCardProperty
+	for _, p := range c.Properties {
+		if p.Name == name {
+			props = append(props, p)
+		}
+	}
+	return props
+}
+
+// 返回属性的文本值（已反转义），不存在时返回空字符串
+func (c VCard) Text(name string) string {
+	p, ok := c.Get(name)
+	if !ok {
+		return ""
+	}
+	return unescapeVCardText(p.Value)
+}
+
+// 解析 vCard 文件，支持 2.1/3.0/4.0 中常见的写法：
+// 折行、属性分组、quoted-printable 编码以及文本转义
+func ParseVCards(r io.Reader) ([]VCard, error) {
+	lines, err := unfoldVCardLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []VCard
+	var current *VCard
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseVCardLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VCARD"):
+			current = &VCard{}
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VCARD"):
+			if current != nil {
+				cards = append(cards, *current)
+				current = nil
+			}
+		case current != nil:
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+
+	if current != nil {
+		return nil, errors.New("vCard 文件不完整：缺少 END:VCARD")
+	}
+	return cards, nil
+}
+
+// 处理折行：以空格或制表符开头的行是上一行的延续；
+// quoted-printable 以 "=" 结尾表示软换行
+func unfoldVCardLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	var lines []string
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+
+		if len(lines) > 0 {
+			last := lines[len(lines)-1]
+			if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+				lines[len(lines)-1] = last + line[1:]
+				continue
+			}
+			if strings.HasSuffix(last, "=") && isQuotedPrintableLine(last) {
+				lines[len(lines)-1] = last[:len(last)-1] + line
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("读取 vCard 文件失败")
+	}
+	return lines, nil
+}
+
+func isQuotedPrintableLine(line string) bool {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return false
+	}
+	return strings.Contains(strings.ToUpper(line[:idx]), "QUOTED-PRINTABLE")
+}
+
+func parseVCardLine(line string) (VCardProperty, error) {
+	// 属性名与参数部分可能包含带引号的 ":"，需要跳过引号查找分隔符
+	sep := -1
+	inQuote := false
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			sep = i
+			break
+		}
+	}
+	if sep < 0 {
+		return VCardProperty{}, errors.New("无效的 vCard 行: " + line)
+	}
+
+	head, value := line[:sep], line[sep+1:]
+	parts := splitVCardParams(head)
+
+	prop := VCardProperty{Params: map[string][]string{}}
+	name := strings.ToUpper(parts[0])
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		prop.Group = name[:dot]
+		name = name[dot+1:]
+	}
+	prop.Name = name
+
+	for _, param := range parts[1:] {
+		key, val := param, ""
+		if eq := strings.Index(param, "="); eq >= 0 {
+			key, val = param[:eq], strings.Trim(param[eq+1:], `"`)
+		}
+		key = strings.ToUpper(key)
+		prop.Params[key] = append(prop.Params[key], val)
+	}
+
+	if enc := prop.Params["ENCODING"]; len(enc) > 0 && strings.EqualFold(enc[0], "QUOTED-PRINTABLE") {
+		decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(value)))
+		if err == nil {
+			value = string(decoded)
+		}
+	}
+	prop.Value = value
+
+	return prop, nil
+}
+
+// 拆分属性名和参数，忽略双引号中的 ";"，例如 LABEL="a;b"
+func splitVCardParams(head string) []string {
+	var parts []string
+	start := 0
+	inQuote := false
+	for i := 0; i < len(head); i++ {
+		switch head[i] {
+		case '"':
+			inQuote = !inQuote
+		case ';':
+			if !inQuote {
+				parts = append(parts, head[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, head[start:])
+}
+
+// 按分隔符拆分，忽略被反斜杠转义的分隔符
+func splitVCardValue(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unescapeVCardText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			sb.WriteByte('\n')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
+// 转义文本值中的反斜杠、逗号、分号和换行，用于生成 vCard
+func EscapeVCardText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// 按 vCard 3.0 格式写入联系人，属性值需要已经转义（见 EscapeVCardText）
+func WriteVCards(w io.Writer, cards []VCard) error {
+	bw := bufio.NewWriter(w)
+	for _, card := range cards {
+		writeFoldedLine(bw, "BEGIN:VCARD")
+		writeFoldedLine(bw, "VERSION:3.0")
+		for _, p := range card.Properties {
+			var sb strings.Builder
+			if p.Group != "" {
+				sb.WriteString(p.Group + ".")
+			}
+			sb.WriteString(p.Name)
+			for _, key := range sortedParamKeys(p.Params) {
+				sb.WriteString(";" + key + "=" + strings.Join(p.Params[key], ","))
+			}
+			sb.WriteString(":" + p.Value)
+			writeFoldedLine(bw, sb.String())
+		}
+		writeFoldedLine(bw, "END:VCARD")
+	}
+	return bw.Flush()
+}
+
+// 参数按名称排序，保证输出稳定
+func sortedParamKeys(params map[string][]string) []string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestParseVCards(t *testing.T) {
+	const input = "\ufeffBEGIN:VCARD\r\n" +
+		"VERSION:3.0\r\n" +
+		"N:O\\;Malley;Barbara;;;\r\n" +
+		"FN:Barbara O'Malley\r\n" +
+		"item1.EMAIL;TYPE=INTERNET,WORK:bjensen@\r\n" +
+		" example.com\r\n" +
+		"TEL;TYPE=CELL:138\r\n" +
+		"\t00138000\r\n" +
+		"ADR;TYPE=WORK:;;100 Universal City Plaza\\, Suite 1;Hollywood;CA;91608;USA\r\n" +
+		"NOTE:第一行\\n第二行\\, 还有\\\\反斜杠\r\n" +
+		"X-LABEL;LABEL=\"a:b;c\":value\r\n" +
+		"END:VCARD\r\n" +
+		"\r\n" +
+		"BEGIN:VCARD\n" +
+		"VERSION:2.1\n" +
+		"FN;CHARSET=UTF-8;ENCODING=QUOTED-PRINTABLE:=E5=BC=A0=\n" +
+		"=E4=B8=89\n" +
+		"TEL;CELL;PREF:13900139000\n" +
+		"END:VCARD\n"
+
+	cards, err := ParseVCards(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseVCards error: %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("ParseVCards returned %d cards, want 2", len(cards))
+	}
+
+	first, second := cards[0], cards[1]
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"N 结构化值", mustVCardProperty(t, first, "N").Components(), []string{"O;Malley", "Barbara", "", "", ""}},
+		{"FN", first.Text("FN"), "Barbara O'Malley"},
+		{"折行的 EMAIL", first.Text("EMAIL"), "bjensen@example.com"},
+		{"属性分组", mustVCardProperty(t, first, "EMAIL").Group, "ITEM1"},
+		{"逗号分隔的 TYPE", mustVCardProperty(t, first, "EMAIL").HasType("work"), true},
+		{"以制表符开头的折行", first.Text("TEL"), "13800138000"},
+		{"TEL 类型", mustVCardProperty(t, first, "TEL").HasType("CELL"), true},
+		{"ADR 中转义的逗号", mustVCardProperty(t, first, "ADR").Components(),
+			[]string{"", "", "100 Universal City Plaza, Suite 1", "Hollywood", "CA", "91608", "USA"}},
+		{"文本转义", first.Text("NOTE"), "第一行\n第二行, 还有\\反斜杠"},
+		{"带引号的参数值", mustVCardProperty(t, first, "X-LABEL").Params["LABEL"], []string{"a:b;c"}},
+		{"带引号的参数之后的值", first.Text("X-LABEL"), "value"},
+		{"不存在的属性", first.Text("ORG"), ""},
+		{"quoted-printable 软换行", second.Text("FN"), "张三"},
+		{"vCard 2.1 省略 TYPE=", mustVCardProperty(t, second, "TEL").HasType("pref"), true},
+		{"没有的类型", mustVCardProperty(t, second, "TEL").HasType("HOME"), false},
+		{"GetAll", len(first.GetAll("VERSION")), 1},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func mustVCardProperty(t *testing.T, card VCard, name string) VCardProperty {
+	t.Helper()
+	p, ok := card.Get(name)
+	if !ok {
+		t.Fatalf("missing property %s", name)
+	}
+	return p
+}
+
+func TestParseVCardsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"缺少 END:VCARD", "BEGIN:VCARD\r\nFN:张三\r\n"},
+		{"缺少冒号", "BEGIN:VCARD\r\nFN 张三\r\nEND:VCARD\r\n"},
+		{"引号没有闭合", "BEGIN:VCARD\r\nX-LABEL;LABEL=\"a:b\r\nEND:VCARD\r\n"},
+		{"超长的行", "BEGIN:VCARD\r\nNOTE:" + strings.Repeat("x", 5*1024*1024) + "\r\nEND:VCARD\r\n"},
+	}
+	for _, tt := range tests {
+		if _, err := ParseVCards(strings.NewReader(tt.input)); err == nil {
+			t.Errorf("%s: ParseVCards should fail", tt.name)
+		}
+	}
+
+	// 没有联系人或 BEGIN 之外的内容会被忽略
+	cards, err := ParseVCards(strings.NewReader("FN:忽略\r\n\r\n"))
+	if err != nil || len(cards) != 0 {
+		t.Errorf("ParseVCards = %d cards, %v, want 0 cards", len(cards), err)
+	}
+}
+
+func TestVCardRoundTrip(t *testing.T) {
+	texts := []string{
+		"张三",
+		"O'Malley; Barbara, Jr.",
+		`C:\path\to\file`,
+		"第一行\r\n第二行\n第三行",
+		strings.Repeat("很长的地址", 40),
+		strings.Repeat("a", 74) + "中文" + strings.Repeat("b", 80),
+	}
+	want := []string{
+		"张三",
+		"O'Malley; Barbara, Jr.",
+		`C:\path\to\file`,
+		"第一行\n第二行\n第三行",
+		strings.Repeat("很长的地址", 40),
+		strings.Repeat("a", 74) + "中文" + strings.Repeat("b", 80),
+	}
+
+	var cards []VCard
+	for _, text := range texts {
+		cards = append(cards, VCard{Properties: []VCardProperty{
+			{Name: "FN", Params: map[string][]string{}, Value: EscapeVCardText(text)},
+			{Group: "ITEM1", Name: "EMAIL", Params: map[string][]string{"TYPE": {"WORK"}}, Value: "user@example.com"},
+			{Name: "N", Params: map[string][]string{}, Value: EscapeVCardText(text) + ";" + EscapeVCardText("名")},
+		}})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteVCards(&buf, cards); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每行不超过 75 个字节，且不会从多字节字符中间折行
+	output := buf.String()
+	if !strings.HasSuffix(output, "\r\n") {
+		t.Error("WriteVCards output should end with CRLF")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 bytes: %q", line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line splits a multi-byte character: %q", line)
+		}
+	}
+
+	parsed, err := ParseVCards(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("ParseVCards error: %v", err)
+	}
+	if len(parsed) != len(cards) {
+		t.Fatalf("ParseVCards returned %d cards, want %d", len(parsed), len(cards))
+	}
+	for i, card := range parsed {
+		if card.Text("VERSION") != "3.0" {
+			t.Errorf("card %d: VERSION = %q, want 3.0", i, card.Text("VERSION"))
+		}
+		if got := card.Text("FN"); got != want[i] {
+			t.Errorf("card %d: FN = %q, want %q", i, got, want[i])
+		}
+		if got := mustVCardProperty(t, card, "N").Components(); !reflect.DeepEqual(got, []string{want[i], "名"}) {
+			t.Errorf("card %d: N = %q, want %q", i, got, []string{want[i], "名"})
+		}
+		if got := mustVCardProperty(t, card, "EMAIL"); !reflect.DeepEqual(got, cards[i].Properties[1]) {
+			t.Errorf("card %d: EMAIL = %+v, want %+v", i, got, cards[i].Properties[1])
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsx 工作簿中用到的 XML 结构（仅保留读取单元格所需的字段）
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	sb.WriteString(t.Text)
+	for _, r := range t.Runs {
+		sb.WriteString(r.Text)
+	}
+	return sb.String()
+}
+
+type xlsxSheetData struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Excel 工作表的最大行数和列数（XFD 列）
+const (
+	xlsxMaxRows    = 1048576
+	xlsxMaxColumns = 16384
+)
+
+// 读取 xlsx 文件第一个工作表的所有行
+// 空行会以空切片的形式保留，以便调用方得到与表格一致的行号
+func ReadXLSXRows(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, errors.New("无效的 xlsx 文件")
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeZipXML(f, &shared); err != nil {
+			return nil, errors.New("解析 xlsx 共享字符串失败")
+		}
+	}
+
+	sheetFile, ok := files[sheetPath]
+	if !ok {
+		return nil, errors.New("xlsx 文件中缺少工作表")
+	}
+	var sheet xlsxSheetData
+	if err := decodeZipXML(sheetFile, &sheet); err != nil {
+		return nil, errors.New("解析 xlsx 工作表失败")
+	}
+
+	var rows [][]string
+	for i, row := range sheet.Rows {
+		rowIndex := row.Index
+		if rowIndex == 0 {
+			rowIndex = len(rows) + 1
+		}
+		if rowIndex < 0 || rowIndex > xlsxMaxRows {
+			return nil, errors.New("xlsx 行号超出范围（第 " + strconv.Itoa(i+1) + " 行）")
+		}
+		// 补齐被省略的空行
+		for len(rows) < rowIndex-1 {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for j, cell := range row.Cells {
+			col := j
+			if cell.Ref != "" {
+				c, err := xlsxColumnIndex(cell.Ref)
+				if err != nil {
+					return nil, errors.New("xlsx 单元格引用无效（第 " + strconv.Itoa(i+1) + " 行）")
+				}
+				col = c
+			}
+			if col >= xlsxMaxColumns {
+				return nil, errors.New("xlsx 列数超出范围（第 " + strconv.Itoa(i+1) + " 行）")
+			}
+			for len(values) < col {
+				values = append(values, "")
+			}
+
+			var value string
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, errors.New("xlsx 共享字符串索引无效（第 " + strconv.Itoa(i+1) + " 行）")
+				}
+				value = shared.Items[idx].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			case "b":
+				if cell.Value == "1" {
+					value = "TRUE"
+				} else {
+					value = "FALSE"
+				}
+			case "", "n":
+				value = formatXLSXNumber(cell.Value)
+			default:
+				value = cell.Value
+			}
+			if col < len(values) {
+				values[col] = value
+			} else {
+				values = append(values, value)
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	return rows, nil
+}
+
+// 根据 workbook.xml 及其关系文件找到第一个工作表的路径
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("无效的 xlsx 文件：缺少 workbook.xml")
+	}
+	var wb xlsxWorkbook
+	if err := decodeZipXML(wbFile, &wb); err != nil || len(wb.Sheets) == 0 {
+		return fallback, nil
+	}
+
+	relFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodeZipXML(relFile, &rels); err != nil {
+		return fallback, nil
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != wb.Sheets[0].RID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			return strings.TrimPrefix(target, "/"), nil
+		}
+		return path.Join("xl", target), nil
+	}
+	return fallback, nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(io.LimitReader(rc, 256<<20)).Decode(v)
+}
+
+// 将 "AB12" 之类的单元格引用转换为从 0 开始的列号
+func xlsxColumnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		// 列名最多三个字母（XFD），避免超长列名溢出
+		if n > 3 {
+			return 0, errors.New("无效的单元格引用")
+		}
+		if r >= 'A' && r <= 'Z' {
+			col = col*26 + int(r-'A'+1)
+			n++
+		} else if r >= 'a' && r <= 'z' {
+			col = col*26 + int(r-'a'+1)
+			n++
+		} else {
+			break
+		}
+	}
+	if n == 0 || n > 3 || col > xlsxMaxColumns {
+		return 0, errors.New("无效的单元格引用")
+	}
+	return col - 1, nil
+}
+
+// Excel 会把电话号码等长数字存成科学计数法，这里还原为整数形式
+func formatXLSXNumber(v string) string {
+	if v == "" || !strings.ContainsAny(v, "eE.") {
+		return v
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return v
+	}
+	if f == float64(int64(f)) {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// 将列号（从 0 开始）转换为列名，例如 0 -> A，27 -> AB
+func xlsxColumnName(col int) string {
+	name := ""
+	for col >= 0 {
+		name = string(rune('A'+col%26)) + name
+		col = col/26 - 1
+	}
+	return name
+}
+
+// 流式写出单工作表的 xlsx 文件，行数据直接写入 zip 流而不在内存中缓存
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	rows  int
+}
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// 样式 0 为普通单元格，样式 1 为加粗的表头
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="等线"/></font><font><b/><sz val="11"/><name val="等线"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// 创建 xlsx 写入器，sheetName 为工作表名称
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	zw := zip.NewWriter(w)
+
+	var name bytes.Buffer
+	xml.EscapeText(&name, []byte(sheetName))
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, p.body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return nil, err
+	}
+
+	return &XLSXWriter{zw: zw, sheet: sheet}, nil
+}
+
+// 写入表头行（加粗）
+func (x *XLSXWriter) WriteHeader(values []string) error {
+	return x.writeRow(values, 1)
+}
+
+// 写入一行数据，所有单元格以文本形式保存，避免电话号码等被 Excel 转为数字
+func (x *XLSXWriter) WriteRow(values []string) error {
+	return x.writeRow(values, 0)
+}
+
+func (x *XLSXWriter) writeRow(values []string, style int) error {
+	x.rows++
+	rowNum := strconv.Itoa(x.rows)
+
+	var buf bytes.Buffer
+	buf.WriteString(`<row r="` + rowNum + `">`)
+	for i, v := range values {
+		buf.WriteString(`<c r="` + xlsxColumnName(i) + rowNum + `" t="inlineStr"`)
+		if style != 0 {
+			buf.WriteString(` s="` + strconv.Itoa(style) + `"`)
+		}
+		buf.WriteString(`><is><t xml:space="preserve">`)
+		xml.EscapeText(&buf, []byte(v))
+		buf.WriteString(`</t></is></c>`)
+	}
+	buf.WriteString(`</row>`)
+
+	_, err := x.sheet.Write(buf.Bytes())
+	return err
+}
+
+// 结束工作表并写出 zip 目录，必须调用
+func (x *XLSXWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
@@ -0,0 +1,245 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 生成只包含一个工作表的 xlsx 文件，shared 为空时不包含共享字符串
+func buildTestXLSX(t *testing.T, sheetData, shared string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml":          `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheets/></workbook>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	if shared != "" {
+		parts["xl/sharedStrings.xml"] = `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` + shared + `</sst>`
+	}
+	for name, body := range parts {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	wide := make([]string, 30)
+	for i := range wide {
+		wide[i] = "列" + strconv.Itoa(i+1)
+	}
+	rows := [][]string{
+		{"姓名", "电话", "备注"},
+		{"张三", "13800138000", `<a href="x">&amp;</a>`},
+		{" 前后有空格 ", "", "第一行\n第二行"},
+		{"", "", "只有最后一列"},
+		wide,
+	}
+
+	var buf bytes.Buffer
+	w, err := NewXLSXWriter(&buf, `客户 <导出> & "备份"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader(rows[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows[1:] {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadXLSXRows(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ReadXLSXRows error: %v", err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("ReadXLSXRows = %q, want %q", got, rows)
+	}
+}
+
+func TestReadXLSXRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		sheet  string
+		shared string
+		want   [][]string
+	}{
+		{
+			"省略的空行和空单元格",
+			`<row r="1"><c r="A1" t="inlineStr"><is><t>a</t></is></c><c r="C1" t="inlineStr"><is><t>c</t></is></c></row>` +
+				`<row r="3"><c r="B3" t="inlineStr"><is><t>b</t></is></c></row>`,
+			"",
+			[][]string{{"a", "", "c"}, nil, {"", "b"}},
+		},
+		{
+			"没有行号和单元格引用",
+			`<row><c t="inlineStr"><is><t>a</t></is></c><c t="inlineStr"><is><t>b</t></is></c></row><row><c><v>1</v></c></row>`,
+			"",
+			[][]string{{"a", "b"}, {"1"}},
+		},
+		{
+			"共享字符串和富文本",
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>`,
+			`<si><t>普通</t></si><si><r><t>富</t></r><r><t>文本</t></r></si>`,
+			[][]string{{"普通", "富文本"}},
+		},
+		{
+			"数字、布尔值和公式结果",
+			`<row r="1"><c r="A1"><v>1.3800138E10</v></c><c r="B1" t="n"><v>3.5</v></c>` +
+				`<c r="C1" t="b"><v>1</v></c><c r="D1" t="b"><v>0</v></c><c r="E1" t="str"><v>公式</v></c></row>`,
+			"",
+			[][]string{{"13800138000", "3.5", "TRUE", "FALSE", "公式"}},
+		},
+		{
+			"小写的列名和最后一列",
+			`<row r="1"><c r="xfd1" t="inlineStr"><is><t>z</t></is></c></row>`,
+			"",
+			[][]string{append(make([]string, xlsxMaxColumns-1), "z")},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ReadXLSXRows(buildTestXLSX(t, tt.sheet, tt.shared))
+		if err != nil {
+			t.Errorf("%s: ReadXLSXRows error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ReadXLSXRows = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadXLSXRowsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		sheet  string
+		shared string
+	}{
+		{"行号超出范围", `<row r="1048577"><c r="A1048577"><v>1</v></c></row>`, ""},
+		{"行号为负数", `<row r="-1"><c r="A1"><v>1</v></c></row>`, ""},
+		{"列超出范围", `<row r="1"><c r="XFE1"><v>1</v></c></row>`, ""},
+		{"超长的列名", `<row r="1"><c r="` + strings.Repeat("Z", 40) + `1"><v>1</v></c></row>`, ""},
+		{"缺少列名", `<row r="1"><c r="11"><v>1</v></c></row>`, ""},
+		{"共享字符串索引越界", `<row r="1"><c r="A1" t="s"><v>1</v></c></row>`, `<si><t>a</t></si>`},
+		{"共享字符串索引为负数", `<row r="1"><c r="A1" t="s"><v>-1</v></c></row>`, `<si><t>a</t></si>`},
+		{"共享字符串索引不是数字", `<row r="1"><c r="A1" t="s"><v>x</v></c></row>`, `<si><t>a</t></si>`},
+		{"工作表不是有效的 XML", `<row r="1"><c r="A1"><v>1</v></row>`, ""},
+	}
+
+	for _, tt := range tests {
+		if _, err := ReadXLSXRows(buildTestXLSX(t, tt.sheet, tt.shared)); err == nil {
+			t.Errorf("%s: ReadXLSXRows should fail", tt.name)
+		}
+	}
+
+	if _, err := ReadXLSXRows([]byte("not a zip file")); err == nil {
+		t.Error("ReadXLSXRows should fail for non-zip data")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zw.Close()
+	if _, err := ReadXLSXRows(buf.Bytes()); err == nil {
+		t.Error("ReadXLSXRows should fail without workbook.xml")
+	}
+}
+
+func TestXLSXColumnIndex(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    int
+		wantErr bool
+	}{
+		{"A1", 0, false},
+		{"Z9", 25, false},
+		{"AA1", 26, false},
+		{"az12", 51, false},
+		{"ZZ1", 701, false},
+		{"AAA1", 702, false},
+		{"XFD1048576", xlsxMaxColumns - 1, false},
+		{"XFE1", 0, true},
+		{"ZZZ1", 0, true},
+		{"AAAA1", 0, true},
+		{"1", 0, true},
+		{"", 0, true},
+		{"$A$1", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := xlsxColumnIndex(tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("xlsxColumnIndex(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("xlsxColumnIndex(%q) = %d, want %d", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	tests := []struct {
+		col  int
+		want string
+	}{
+		{0, "A"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{701, "ZZ"},
+		{702, "AAA"},
+		{xlsxMaxColumns - 1, "XFD"},
+	}
+	for _, tt := range tests {
+		if got := xlsxColumnName(tt.col); got != tt.want {
+			t.Errorf("xlsxColumnName(%d) = %q, want %q", tt.col, got, tt.want)
+		}
+	}
+
+	// 列名与列号互相转换的结果一致
+	for col := 0; col < xlsxMaxColumns; col++ {
+		name := xlsxColumnName(col)
+		if got, err := xlsxColumnIndex(name + "1"); err != nil || got != col {
+			t.Fatalf("xlsxColumnIndex(%q) = %d, %v, want %d", name+"1", got, err, col)
+		}
+	}
+}
+
+func TestFormatXLSXNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"42", "42"},
+		{"1.3800138E10", "13800138000"},
+		{"1.5e2", "150"},
+		{"3.25", "3.25"},
+		{"100.0", "100"},
+		{"abc.def", "abc.def"},
+	}
+	for _, tt := range tests {
+		if got := formatXLSXNumber(tt.value); got != tt.want {
+			t.Errorf("formatXLSXNumber(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -13,7 +13,6 @@ export interface LoginRequest {
 export interface RegisterRequest {
   username: string
   password_hash: string
-  role?: string
 }
 
 export interface User {
@@ -43,12 +42,11 @@ export const authApi = {
     return response.data
   },
 
-  register: async (data: { username: string; password: string; role?: string }) => {
+  register: async (data: { username: string; password: string }) => {
     const hashedPassword = hashPassword(data.password)
     const response = await api.post<ApiResponse<User>>('/register', {
       username: data.username,
-      password_hash: hashedPassword,
-      role: data.role
+      password_hash: hashedPassword
     })
     return response.data
   }
@@ -16,7 +16,7 @@ export const useAuthStore = defineStore('auth', () => {
     }
   }
 
-  const register = async (data: { username: string; password: string; role?: string }) => {
+  const register = async (data: { username: string; password: string }) => {
     const response = await authApi.register(data)
     if (response.user) {
       user.value = response.user